@@ -5,11 +5,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"net/url"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
+
+	"github.com/ymtdzzz/otel-tui/tuiexporter"
 )
 
 //go:embed config.yml.tpl
@@ -40,6 +45,15 @@ type Config struct {
 	DebugLogFilePath       string
 	DisableInternalMetrics bool
 	AuthToken              string // #nosec G117
+	MaxServiceSpanCount    int
+	MaxMetricCount         int
+	MaxLogCount            int
+	MaxMemory              string
+	MaxMemoryBytes         int64
+	TTL                    time.Duration
+	SessionFile            string
+	TraceEvictionPolicy    string
+	TraceEvictionFilter    string
 }
 
 func NewConfig(
@@ -53,6 +67,14 @@ func NewConfig(
 	debugLogFilePath string,
 	disableInternalMetrics bool,
 	authToken string,
+	maxServiceSpanCount int,
+	maxMetricCount int,
+	maxLogCount int,
+	maxMemory string,
+	ttl time.Duration,
+	sessionFile string,
+	traceEvictionPolicy string,
+	traceEvictionFilter string,
 ) (*Config, error) {
 	cfg := &Config{
 		OTLPHost:               otlpHost,
@@ -65,12 +87,28 @@ func NewConfig(
 		DebugLogFilePath:       debugLogFilePath,
 		DisableInternalMetrics: disableInternalMetrics,
 		AuthToken:              authToken,
+		MaxServiceSpanCount:    maxServiceSpanCount,
+		MaxMetricCount:         maxMetricCount,
+		MaxLogCount:            maxLogCount,
+		MaxMemory:              maxMemory,
+		TTL:                    ttl,
+		SessionFile:            sessionFile,
+		TraceEvictionPolicy:    traceEvictionPolicy,
+		TraceEvictionFilter:    traceEvictionFilter,
 	}
 
 	if err := cfg.validate(); err != nil {
 		return nil, err
 	}
 
+	if cfg.MaxMemory != "" {
+		size, err := parseByteSize(cfg.MaxMemory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse max memory: %w", err)
+		}
+		cfg.MaxMemoryBytes = size
+	}
+
 	if err := cfg.buildPromScrapeConfigs(); err != nil {
 		return nil, fmt.Errorf("failed to build Prometheus scrape configs: %w", err)
 	}
@@ -132,6 +170,14 @@ func (c *Config) RenderYml() (string, error) {
 	if err != nil {
 		return "", err
 	}
+	// the JSON round trip turns the int64 into a float rendered in the exponent form
+	if c.MaxMemoryBytes > 0 {
+		params["MaxMemoryBytes"] = strconv.FormatInt(c.MaxMemoryBytes, 10)
+	}
+	// the duration is rendered in the readable form rather than the nanoseconds
+	if c.TTL > 0 {
+		params["TTL"] = c.TTL.String()
+	}
 
 	var buf strings.Builder
 	if err := tpl.Execute(&buf, params); err != nil {
@@ -163,5 +209,61 @@ func (c *Config) validate() error {
 		return errors.New("the initial data JSON file does not exist")
 	}
 
+	if c.MaxServiceSpanCount < 0 || c.MaxMetricCount < 0 || c.MaxLogCount < 0 {
+		return errors.New("the max count of spans, metrics and logs must not be negative")
+	}
+
+	if c.TTL < 0 {
+		return errors.New("the ttl must not be negative")
+	}
+
+	traceEviction := tuiexporter.TraceEviction{
+		Policy: c.TraceEvictionPolicy,
+		Filter: c.TraceEvictionFilter,
+	}
+	if err := traceEviction.Validate(); err != nil {
+		return fmt.Errorf("the trace eviction is invalid: %w", err)
+	}
+
 	return nil
 }
+
+var byteSizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	// longer suffixes first so that "MiB" is not matched as "B"
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"KB", 1000},
+	{"MB", 1000 * 1000},
+	{"GB", 1000 * 1000 * 1000},
+	{"B", 1},
+}
+
+// parseByteSize parses a human readable byte size such as "512MiB" or "1GB"
+func parseByteSize(input string) (int64, error) {
+	s := strings.TrimSpace(input)
+	unit := int64(1)
+	for _, u := range byteSizeUnits {
+		if strings.HasSuffix(s, u.suffix) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, u.suffix))
+			unit = u.size
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	if n <= 0 {
+		return 0, fmt.Errorf("byte size must be positive: %d", n)
+	}
+	if n > math.MaxInt64/unit {
+		return 0, fmt.Errorf("byte size is too large: %s", input)
+	}
+
+	return n * unit, nil
+}
@@ -75,6 +75,31 @@ exporters:
   tui:
     from_json_file: {{ if .FromJSONFile }}true{{else}}false{{end}}
     debug_log_file_path: '{{ .DebugLogFilePath }}'
+{{- if .MaxServiceSpanCount}}
+    max_service_span_count: {{ .MaxServiceSpanCount }}
+{{- end}}
+{{- if .MaxMetricCount}}
+    max_metric_count: {{ .MaxMetricCount }}
+{{- end}}
+{{- if .MaxLogCount}}
+    max_log_count: {{ .MaxLogCount }}
+{{- end}}
+{{- if .MaxMemoryBytes}}
+    max_memory_bytes: {{ .MaxMemoryBytes }}
+{{- end}}
+{{- if .TTL}}
+    ttl: {{ .TTL }}
+{{- end}}
+{{- if .SessionFile}}
+    session_file: '{{ .SessionFile }}'
+{{- end}}
+{{- if .TraceEvictionPolicy}}
+    trace_eviction:
+      policy: {{ .TraceEvictionPolicy }}
+{{- if .TraceEvictionFilter}}
+      filter: {{ printf "%q" .TraceEvictionFilter }}
+{{- end}}
+{{- end}}
 service:
 {{- if .AuthToken}}
   extensions: [bearertokenauth]
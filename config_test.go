@@ -2,7 +2,10 @@ package main
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -341,6 +344,74 @@ service:
 	assert.Equal(t, want, got)
 }
 
+func TestConfigRenderYmlWithRetention(t *testing.T) {
+	cfg := &Config{
+		OTLPHost:            "0.0.0.0",
+		OTLPHTTPPort:        4318,
+		OTLPGRPCPort:        4317,
+		MaxServiceSpanCount: 5000,
+		MaxMetricCount:      6000,
+		MaxLogCount:         7000,
+		MaxMemoryBytes:      536870912,
+		TTL:                 90 * time.Minute,
+		SessionFile:         "/tmp/otel-tui-session.json",
+		TraceEvictionPolicy: "drop-by-filter",
+		TraceEvictionFilter: `name="GET /healthz"`,
+	}
+	want := `yaml:
+receivers:
+  otlp:
+    protocols:
+      http:
+        endpoint: 0.0.0.0:4318
+        cors:
+          allowed_origins:
+            - http://localhost:*
+            - https://localhost:*
+      grpc:
+        endpoint: 0.0.0.0:4317
+processors:
+exporters:
+  tui:
+    from_json_file: false
+    debug_log_file_path: ''
+    max_service_span_count: 5000
+    max_metric_count: 6000
+    max_log_count: 7000
+    max_memory_bytes: 536870912
+    ttl: 1h30m0s
+    session_file: '/tmp/otel-tui-session.json'
+    trace_eviction:
+      policy: drop-by-filter
+      filter: "name=\"GET /healthz\""
+service:
+  pipelines:
+    traces:
+      receivers:
+        - otlp
+      processors:
+      exporters:
+        - tui
+    logs:
+      receivers:
+        - otlp
+      processors:
+      exporters:
+        - tui
+    metrics:
+      receivers:
+        - otlp
+      processors:
+      exporters:
+        - tui
+`
+	err := cfg.buildPromScrapeConfigs()
+	assert.Nil(t, err)
+	got, err := cfg.RenderYml()
+	assert.Nil(t, err)
+	assert.Equal(t, want, got)
+}
+
 func TestConfigRenderYmlWithDatadog(t *testing.T) {
 	cfg := &Config{
 		OTLPHost:      "0.0.0.0",
@@ -445,6 +516,44 @@ func TestValidate(t *testing.T) {
 			},
 			want: errors.New("the initial data JSON file does not exist"),
 		},
+		{
+			name: "NG_Negative_Max_Count",
+			cfg: &Config{
+				MaxLogCount: -1,
+			},
+			want: errors.New("the max count of spans, metrics and logs must not be negative"),
+		},
+		{
+			name: "NG_Negative_TTL",
+			cfg: &Config{
+				TTL: -time.Second,
+			},
+			want: errors.New("the ttl must not be negative"),
+		},
+		{
+			name: "OK_Trace_Eviction",
+			cfg: &Config{
+				TraceEvictionPolicy: "drop-by-filter",
+				TraceEvictionFilter: `name="GET /healthz"`,
+			},
+			want: nil,
+		},
+		{
+			name: "NG_Unknown_Trace_Eviction_Policy",
+			cfg: &Config{
+				TraceEvictionPolicy: "lru",
+			},
+			want: fmt.Errorf("the trace eviction is invalid: %w",
+				errors.New(`unknown trace eviction policy "lru", must be one of fifo, keep-errors, fair-share or drop-by-filter`)),
+		},
+		{
+			name: "NG_Trace_Eviction_Without_Filter",
+			cfg: &Config{
+				TraceEvictionPolicy: "drop-by-filter",
+			},
+			want: fmt.Errorf("the trace eviction is invalid: %w",
+				errors.New("filter is required for the drop-by-filter eviction policy")),
+		},
 	}
 
 	for _, tt := range tests {
@@ -453,3 +562,37 @@ func TestValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{input: "1024", want: 1024},
+		{input: "100B", want: 100},
+		{input: "512MiB", want: 512 * 1024 * 1024},
+		{input: "2 GiB", want: 2 * 1024 * 1024 * 1024},
+		{input: "10KiB", want: 10 * 1024},
+		{input: "1GB", want: 1000 * 1000 * 1000},
+		{input: "5MB", want: 5 * 1000 * 1000},
+		{input: "3KB", want: 3000},
+		{input: "abc", wantErr: true},
+		{input: "0MiB", wantErr: true},
+		{input: "-1GB", wantErr: true},
+		{input: "99999999999GB", wantErr: true},
+		{input: "9223372036854775807B", want: math.MaxInt64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := parseByteSize(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
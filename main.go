@@ -4,8 +4,10 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/ymtdzzz/otel-tui/tuiexporter"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/confmap/provider/envprovider"
@@ -57,6 +59,14 @@ type collectorCommand struct {
 	fromJSONFile           string
 	debugLog               bool
 	disableInternalMetrics bool
+	maxServiceSpanCount    int
+	maxMetricCount         int
+	maxLogCount            int
+	maxMemory              string
+	ttl                    time.Duration
+	sessionFile            string
+	traceEvictionPolicy    string
+	traceEvictionFilter    string
 }
 
 func (c *collectorCommand) preRunE(cmd *cobra.Command, args []string) error {
@@ -76,6 +86,14 @@ func (c *collectorCommand) preRunE(cmd *cobra.Command, args []string) error {
 		logPath,
 		c.disableInternalMetrics,
 		os.Getenv("AUTH_TOKEN"),
+		c.maxServiceSpanCount,
+		c.maxMetricCount,
+		c.maxLogCount,
+		c.maxMemory,
+		c.ttl,
+		c.sessionFile,
+		c.traceEvictionPolicy,
+		c.traceEvictionFilter,
 	)
 
 	if err != nil {
@@ -117,10 +135,13 @@ func newCommand(params otelcol.CollectorSettings) *collectorCommand {
 Environment Variables:
   AUTH_TOKEN    Bearer token for OTLP receiver authentication (applies to both HTTP and gRPC)`,
 		},
-		params:   params,
-		httpPort: 4318,
-		grpcPort: 4317,
-		host:     "0.0.0.0",
+		params:              params,
+		httpPort:            4318,
+		grpcPort:            4317,
+		host:                "0.0.0.0",
+		maxServiceSpanCount: tuiexporter.DefaultMaxServiceSpanCount,
+		maxMetricCount:      tuiexporter.DefaultMaxMetricCount,
+		maxLogCount:         tuiexporter.DefaultMaxLogCount,
 	}
 
 	rootCmd.PreRunE = rootCmd.preRunE
@@ -135,6 +156,14 @@ Environment Variables:
 	rootCmd.Flags().StringArrayVar(&rootCmd.promTargets, "prom-target", rootCmd.promTargets, `Enable the prometheus receiver and specify the target endpoints for the receiver (--prom-target "localhost:9000" --prom-target "http://other-host:9000/custom/prometheus")`)
 	rootCmd.Flags().BoolVar(&rootCmd.debugLog, "debug-log", rootCmd.debugLog, "Enable debug log output to file (/tmp/otel-tui.log)")
 	rootCmd.Flags().BoolVar(&rootCmd.disableInternalMetrics, "disable-internal-metrics", rootCmd.disableInternalMetrics, "Disable the collector's internal metrics telemetry reporting")
+	rootCmd.Flags().IntVar(&rootCmd.maxServiceSpanCount, "max-service-spans", rootCmd.maxServiceSpanCount, "The maximum number of service spans (rows in the Traces table) to retain")
+	rootCmd.Flags().IntVar(&rootCmd.maxMetricCount, "max-metrics", rootCmd.maxMetricCount, "The maximum number of metric series to retain")
+	rootCmd.Flags().IntVar(&rootCmd.maxLogCount, "max-logs", rootCmd.maxLogCount, "The maximum number of logs to retain")
+	rootCmd.Flags().StringVar(&rootCmd.maxMemory, "max-memory", rootCmd.maxMemory, `The approximate memory budget for retained telemetry. The oldest data is evicted when exceeded (e.g. "512MiB", "1GB")`)
+	rootCmd.Flags().DurationVar(&rootCmd.ttl, "ttl", rootCmd.ttl, `The time to live of retained telemetry. Data received earlier than this is dropped (e.g. "30m", "2h")`)
+	rootCmd.Flags().StringVar(&rootCmd.sessionFile, "session-file", rootCmd.sessionFile, "The file path to persist the session. Telemetry is restored from the file on startup and saved periodically (OTLP protobuf for .pb, otherwise OTLP JSON)")
+	rootCmd.Flags().StringVar(&rootCmd.traceEvictionPolicy, "trace-eviction-policy", rootCmd.traceEvictionPolicy, "The policy to evict traces when the number of service spans exceeds the max count (fifo, keep-errors, fair-share or drop-by-filter)")
+	rootCmd.Flags().StringVar(&rootCmd.traceEvictionFilter, "trace-eviction-filter", rootCmd.traceEvictionFilter, `The trace filter query matching the traces evicted first by the drop-by-filter policy (e.g. 'name="GET /healthz"')`)
 	return rootCmd
 }
 
@@ -1,18 +1,60 @@
 package tuiexporter
 
-import "go.opentelemetry.io/collector/component"
+import (
+	"errors"
+	"time"
+
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
+	"go.opentelemetry.io/collector/component"
+)
+
+// Default max counts of the retained telemetry, used when the max counts in Config are not set
+const (
+	DefaultMaxServiceSpanCount = telemetry.MAX_SERVICE_SPAN_COUNT
+	DefaultMaxMetricCount      = telemetry.MAX_METRIC_COUNT
+	DefaultMaxLogCount         = telemetry.MAX_LOG_COUNT
+)
 
 // Config defines configuration for TUI exporter.
 type Config struct {
-	FromJSONFile     bool   `mapstructure:"from_json_file"`
-	DebugLogFilePath string `mapstructure:"debug_log_file_path"`
+	FromJSONFile        bool          `mapstructure:"from_json_file"`
+	DebugLogFilePath    string        `mapstructure:"debug_log_file_path"`
+	MaxServiceSpanCount int           `mapstructure:"max_service_span_count"`
+	MaxMetricCount      int           `mapstructure:"max_metric_count"`
+	MaxLogCount         int           `mapstructure:"max_log_count"`
+	MaxMemoryBytes      int64         `mapstructure:"max_memory_bytes"`
+	TTL                 time.Duration `mapstructure:"ttl"`
+	SessionFile         string        `mapstructure:"session_file"`
+	TraceEviction       TraceEviction `mapstructure:"trace_eviction"`
+}
+
+// TraceEviction defines the policy to evict the service spans (rows in the Traces table)
+// when the number of them exceeds max_service_span_count
+type TraceEviction struct {
+	// Policy is one of fifo (default), keep-errors, fair-share or drop-by-filter
+	Policy string `mapstructure:"policy"`
+	// Filter is the trace filter query of the service spans evicted first by drop-by-filter
+	Filter string `mapstructure:"filter"`
 }
 
 var _ component.Config = (*Config)(nil)
 
 // Validate checks if the exporter configuration is valid
-/* This is not used because the exporter does not have any configuration
 func (cfg *Config) Validate() error {
-	return nil
+	if cfg.MaxServiceSpanCount < 0 || cfg.MaxMetricCount < 0 || cfg.MaxLogCount < 0 {
+		return errors.New("max counts must not be negative")
+	}
+	if cfg.MaxMemoryBytes < 0 {
+		return errors.New("max_memory_bytes must not be negative")
+	}
+	if cfg.TTL < 0 {
+		return errors.New("ttl must not be negative")
+	}
+	return cfg.TraceEviction.Validate()
+}
+
+// Validate checks if the policy is known and the filter is valid for the policy
+func (te TraceEviction) Validate() error {
+	_, err := telemetry.NewTraceEvictionPolicy(te.Policy, te.Filter)
+	return err
 }
-*/
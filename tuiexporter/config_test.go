@@ -0,0 +1,71 @@
+package tuiexporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *Config
+		wantErr bool
+	}{
+		{
+			name: "OK_Default",
+			cfg:  &Config{},
+		},
+		{
+			name: "OK_WithLimits",
+			cfg: &Config{
+				MaxServiceSpanCount: 10,
+				MaxMetricCount:      20,
+				MaxLogCount:         30,
+				MaxMemoryBytes:      1024,
+				TTL:                 time.Hour,
+				TraceEviction: TraceEviction{
+					Policy: "drop-by-filter",
+					Filter: `name="GET /healthz"`,
+				},
+			},
+		},
+		{
+			name:    "NG_NegativeCount",
+			cfg:     &Config{MaxLogCount: -1},
+			wantErr: true,
+		},
+		{
+			name:    "NG_NegativeMemory",
+			cfg:     &Config{MaxMemoryBytes: -1},
+			wantErr: true,
+		},
+		{
+			name:    "NG_NegativeTTL",
+			cfg:     &Config{TTL: -time.Second},
+			wantErr: true,
+		},
+		{
+			name:    "NG_UnknownTraceEvictionPolicy",
+			cfg:     &Config{TraceEviction: TraceEviction{Policy: "lru"}},
+			wantErr: true,
+		},
+		{
+			name:    "NG_TraceEvictionWithoutFilter",
+			cfg:     &Config{TraceEviction: TraceEviction{Policy: "drop-by-filter"}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
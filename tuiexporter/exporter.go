@@ -14,8 +14,14 @@ import (
 	"go.opentelemetry.io/collector/pdata/ptrace"
 )
 
+// sessionSaveInterval is the interval to save the session to the session file
+const sessionSaveInterval = 10 * time.Second
+
 type tuiExporter struct {
-	app *tui.TUIApp
+	app              *tui.TUIApp
+	sessionFile      string
+	stopSweeper      func()
+	stopSessionSaver func()
 }
 
 func newTuiExporter(config *Config) (*tuiExporter, error) {
@@ -26,12 +32,34 @@ func newTuiExporter(config *Config) (*tuiExporter, error) {
 		initialInterval = 1 * time.Second
 	}
 
-	app, err := tui.NewTUIApp(telemetry.NewStore(clockwork.NewRealClock()), initialInterval, config.DebugLogFilePath)
+	evictionPolicy, err := telemetry.NewTraceEvictionPolicy(config.TraceEviction.Policy, config.TraceEviction.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	store := telemetry.NewStore(
+		clockwork.NewRealClock(),
+		telemetry.WithMaxServiceSpanCount(config.MaxServiceSpanCount),
+		telemetry.WithMaxMetricCount(config.MaxMetricCount),
+		telemetry.WithMaxLogCount(config.MaxLogCount),
+		telemetry.WithMaxMemoryBytes(config.MaxMemoryBytes),
+		telemetry.WithTTL(config.TTL),
+		telemetry.WithTraceEvictionPolicy(evictionPolicy),
+	)
+
+	if config.SessionFile != "" {
+		if err := store.LoadSession(config.SessionFile); err != nil {
+			return nil, err
+		}
+	}
+
+	app, err := tui.NewTUIApp(store, initialInterval, config.DebugLogFilePath)
 	if err != nil {
 		return nil, err
 	}
 	return &tuiExporter{
-		app: app,
+		app:         app,
+		sessionFile: config.SessionFile,
 	}, nil
 }
 
@@ -55,6 +83,10 @@ func (e *tuiExporter) pushLogs(_ context.Context, logs plog.Logs) error {
 
 // Start runs the TUI exporter
 func (e *tuiExporter) Start(_ context.Context, _ component.Host) error {
+	e.stopSweeper = e.app.Store().StartTTLSweeper()
+	if e.sessionFile != "" {
+		e.stopSessionSaver = e.app.Store().StartSessionSaver(e.sessionFile, sessionSaveInterval)
+	}
 	go func() {
 		err := e.app.Run()
 		if err != nil {
@@ -66,5 +98,11 @@ func (e *tuiExporter) Start(_ context.Context, _ component.Host) error {
 
 // Shutdown stops the TUI exporter
 func (e *tuiExporter) Shutdown(_ context.Context) error {
+	if e.stopSweeper != nil {
+		e.stopSweeper()
+	}
+	if e.stopSessionSaver != nil {
+		e.stopSessionSaver()
+	}
 	return e.app.Stop()
 }
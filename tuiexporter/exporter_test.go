@@ -2,6 +2,8 @@ package tuiexporter
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -79,3 +81,29 @@ func TestStartAndShutdown(t *testing.T) {
 	err = exporter.Shutdown(context.Background())
 	assert.NoError(t, err)
 }
+
+func TestSessionFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	exporter, err := newTuiExporter(&Config{SessionFile: path})
+	assert.NoError(t, err)
+
+	err = exporter.Start(context.Background(), nil)
+	assert.NoError(t, err)
+
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("test")
+	err = exporter.pushLogs(context.Background(), logs)
+	assert.NoError(t, err)
+
+	time.Sleep(10 * time.Millisecond)
+
+	// the session is saved on shutdown
+	err = exporter.Shutdown(context.Background())
+	assert.NoError(t, err)
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+
+	restored, err := newTuiExporter(&Config{SessionFile: path})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, restored.app.Store().SnapshotLogs().Len())
+}
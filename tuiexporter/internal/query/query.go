@@ -0,0 +1,280 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Op is a comparison operator of a term
+type Op string
+
+const (
+	OP_NONE      Op = ""
+	OP_EQ        Op = "="
+	OP_NE        Op = "!="
+	OP_GT        Op = ">"
+	OP_GE        Op = ">="
+	OP_LT        Op = "<"
+	OP_LE        Op = "<="
+	OP_MATCH     Op = "~"
+	OP_NOT_MATCH Op = "!~"
+)
+
+// IsOrdering returns true if the operator compares the order of values
+func (o Op) IsOrdering() bool {
+	return o == OP_GT || o == OP_GE || o == OP_LT || o == OP_LE
+}
+
+// IsRegex returns true if the operator matches a regular expression
+func (o Op) IsRegex() bool {
+	return o == OP_MATCH || o == OP_NOT_MATCH
+}
+
+// Error is an error in the query with the position where it occurred
+type Error struct {
+	// Pos is the byte offset in the query
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at column %d", e.Msg, e.Pos+1)
+}
+
+// Errorf returns an error pointing to the term
+func (t *Term) Errorf(format string, args ...any) error {
+	return &Error{Pos: t.Pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Term is a single condition of the query.
+// A term without a key is a free text which is matched by substring.
+type Term struct {
+	Key   string
+	Op    Op
+	Value string
+	// Pos is the byte offset of the term in the query
+	Pos   int
+	re    *regexp.Regexp
+	num   float64
+	isNum bool
+}
+
+// IsText returns true if the term is a free text
+func (t *Term) IsText() bool {
+	return t.Key == ""
+}
+
+// Number returns the value as a number if it is numeric
+func (t *Term) Number() (float64, bool) {
+	return t.num, t.isNum
+}
+
+// Compare returns whether the result of comparing the target with the value (-1, 0 or 1)
+// satisfies the operator
+func (t *Term) Compare(cmp int) bool {
+	switch t.Op {
+	case OP_EQ:
+		return cmp == 0
+	case OP_NE:
+		return cmp != 0
+	case OP_GT:
+		return cmp > 0
+	case OP_GE:
+		return cmp >= 0
+	case OP_LT:
+		return cmp < 0
+	case OP_LE:
+		return cmp <= 0
+	}
+	return false
+}
+
+// MatchString returns whether the string satisfies the term. Ordering operators
+// compare the string as a number and never match non-numeric strings.
+func (t *Term) MatchString(s string) bool {
+	switch t.Op {
+	case OP_NONE:
+		return strings.Contains(s, t.Value)
+	case OP_MATCH:
+		return t.re.MatchString(s)
+	case OP_NOT_MATCH:
+		return !t.re.MatchString(s)
+	case OP_EQ:
+		return s == t.Value
+	case OP_NE:
+		return s != t.Value
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || !t.isNum {
+		return false
+	}
+	return t.MatchNumber(n)
+}
+
+// MatchNumber returns whether the number satisfies the term
+func (t *Term) MatchNumber(n float64) bool {
+	if t.Op.IsRegex() {
+		return t.MatchString(strconv.FormatFloat(n, 'f', -1, 64))
+	}
+	switch {
+	case n < t.num:
+		return t.Compare(-1)
+	case n > t.num:
+		return t.Compare(1)
+	}
+	return t.Compare(0)
+}
+
+// Query is a list of terms which must all be satisfied
+type Query struct {
+	Terms []*Term
+}
+
+// IsEmpty returns true if the query has no terms
+func (q *Query) IsEmpty() bool {
+	return len(q.Terms) == 0
+}
+
+// Parse parses the query such as `service.name=checkout duration>250ms "free text"`.
+// Each term is `key op value` or a free text, and the terms are combined with AND.
+// The value can be quoted with double quotes to contain spaces and operators.
+func Parse(input string) (*Query, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &Query{Terms: []*Term{}}
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if tok.kind == tokenOp {
+			return nil, &Error{Pos: tok.pos, Msg: fmt.Sprintf("unexpected operator '%s'", tok.text)}
+		}
+
+		// free text
+		if i+1 >= len(tokens) || tokens[i+1].kind != tokenOp {
+			q.Terms = append(q.Terms, &Term{Value: tok.text, Pos: tok.pos})
+			continue
+		}
+
+		if tok.kind == tokenString {
+			return nil, &Error{Pos: tok.pos, Msg: "key must not be quoted"}
+		}
+		op := tokens[i+1]
+		if i+2 >= len(tokens) || tokens[i+2].kind == tokenOp {
+			return nil, &Error{Pos: op.pos, Msg: fmt.Sprintf("missing value after '%s'", op.text)}
+		}
+		value := tokens[i+2]
+
+		term := &Term{
+			Key:   tok.text,
+			Op:    Op(op.text),
+			Value: value.text,
+			Pos:   tok.pos,
+		}
+		if term.Op.IsRegex() {
+			re, err := regexp.Compile(term.Value)
+			if err != nil {
+				return nil, &Error{Pos: value.pos, Msg: fmt.Sprintf("invalid regular expression: %v", err)}
+			}
+			term.re = re
+		}
+		if n, err := strconv.ParseFloat(term.Value, 64); err == nil {
+			term.num, term.isNum = n, true
+		}
+		q.Terms = append(q.Terms, term)
+		i += 2
+	}
+
+	return q, nil
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func tokenize(input string) ([]token, error) {
+	tokens := []token{}
+	runes := []rune(input)
+	// byte offsets of each rune to report positions in the original string
+	offsets := make([]int, len(runes)+1)
+	off := 0
+	for i, r := range runes {
+		offsets[i] = off
+		off += len(string(r))
+	}
+	offsets[len(runes)] = off
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"':
+			start := i
+			var sb strings.Builder
+			i++
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					sb.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == '"' {
+					closed = true
+					i++
+					break
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if !closed {
+				return nil, &Error{Pos: offsets[start], Msg: "unterminated quoted string"}
+			}
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), pos: offsets[start]})
+		case isOpStart(runes, i):
+			start := i
+			op := string(r)
+			if i+1 < len(runes) && (runes[i+1] == '=' || (r == '!' && runes[i+1] == '~')) && r != '=' && r != '~' {
+				op += string(runes[i+1])
+			}
+			i += len([]rune(op))
+			tokens = append(tokens, token{kind: tokenOp, text: op, pos: offsets[start]})
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '"' && !isOpStart(runes, i) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, text: string(runes[start:i]), pos: offsets[start]})
+		}
+	}
+
+	return tokens, nil
+}
+
+// isOpStart returns true if an operator starts at the position.
+// '!' is an operator only when it is followed by '=' or '~'.
+func isOpStart(runes []rune, i int) bool {
+	switch runes[i] {
+	case '=', '<', '>', '~':
+		return true
+	case '!':
+		return i+1 < len(runes) && (runes[i+1] == '=' || runes[i+1] == '~')
+	}
+	return false
+}
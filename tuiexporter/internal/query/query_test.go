@@ -0,0 +1,192 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []*Term
+	}{
+		{
+			name:  "empty",
+			input: "  ",
+			want:  []*Term{},
+		},
+		{
+			name:  "free text",
+			input: "checkout GET",
+			want: []*Term{
+				{Value: "checkout", Pos: 0},
+				{Value: "GET", Pos: 9},
+			},
+		},
+		{
+			name:  "predicates",
+			input: `service.name=checkout http.response.status_code>=500 duration > 250ms status!=ok`,
+			want: []*Term{
+				{Key: "service.name", Op: OP_EQ, Value: "checkout", Pos: 0},
+				{Key: "http.response.status_code", Op: OP_GE, Value: "500", Pos: 22, num: 500, isNum: true},
+				{Key: "duration", Op: OP_GT, Value: "250ms", Pos: 53},
+				{Key: "status", Op: OP_NE, Value: "ok", Pos: 70},
+			},
+		},
+		{
+			name:  "quoted value",
+			input: `name="GET /users" "free text"`,
+			want: []*Term{
+				{Key: "name", Op: OP_EQ, Value: "GET /users", Pos: 0},
+				{Value: "free text", Pos: 18},
+			},
+		},
+		{
+			name:  "escaped quote",
+			input: `body="say \"hi\""`,
+			want: []*Term{
+				{Key: "body", Op: OP_EQ, Value: `say "hi"`, Pos: 0},
+			},
+		},
+		{
+			name:  "exclamation mark in free text",
+			input: "hello!",
+			want: []*Term{
+				{Value: "hello!", Pos: 0},
+			},
+		},
+		{
+			name:  "less than",
+			input: "a<1 b<=2",
+			want: []*Term{
+				{Key: "a", Op: OP_LT, Value: "1", Pos: 0, num: 1, isNum: true},
+				{Key: "b", Op: OP_LE, Value: "2", Pos: 4, num: 2, isNum: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.Terms)
+		})
+	}
+}
+
+func TestParseRegex(t *testing.T) {
+	got, err := Parse(`body~"timeout.*db" name!~^GET`)
+	require.NoError(t, err)
+	require.Equal(t, 2, len(got.Terms))
+
+	assert.Equal(t, OP_MATCH, got.Terms[0].Op)
+	assert.True(t, got.Terms[0].MatchString("connection timeout to db"))
+	assert.False(t, got.Terms[0].MatchString("db timeout"))
+
+	assert.Equal(t, OP_NOT_MATCH, got.Terms[1].Op)
+	assert.False(t, got.Terms[1].MatchString("GET /users"))
+	assert.True(t, got.Terms[1].MatchString("POST /users"))
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "unexpected operator",
+			input: "=foo",
+			want:  "unexpected operator '=' at column 1",
+		},
+		{
+			name:  "missing value",
+			input: "status= ",
+			want:  "missing value after '=' at column 7",
+		},
+		{
+			name:  "operator after operator",
+			input: "a>=<1",
+			want:  "missing value after '>=' at column 2",
+		},
+		{
+			name:  "unterminated string",
+			input: `name="foo`,
+			want:  "unterminated quoted string at column 6",
+		},
+		{
+			name:  "quoted key",
+			input: `"name"=foo`,
+			want:  "key must not be quoted at column 1",
+		},
+		{
+			name:  "invalid regex",
+			input: "body~(",
+			want:  "invalid regular expression: error parsing regexp: missing closing ): `(` at column 6",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			require.Error(t, err)
+			assert.Equal(t, tt.want, err.Error())
+
+			var qerr *Error
+			assert.ErrorAs(t, err, &qerr)
+		})
+	}
+}
+
+func TestTermMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		str   string
+		want  bool
+	}{
+		{name: "text contains", input: "eck", str: "checkout", want: true},
+		{name: "text not contains", input: "foo", str: "checkout", want: false},
+		{name: "eq", input: "k=checkout", str: "checkout", want: true},
+		{name: "eq not", input: "k=check", str: "checkout", want: false},
+		{name: "ne", input: "k!=check", str: "checkout", want: true},
+		{name: "gt number", input: "k>499", str: "500", want: true},
+		{name: "ge number", input: "k>=500", str: "500", want: true},
+		{name: "lt number", input: "k<500", str: "500", want: false},
+		{name: "le number", input: "k<=500.5", str: "500", want: true},
+		{name: "ordering non numeric", input: "k>1", str: "abc", want: false},
+		{name: "ordering non numeric value", input: "k>abc", str: "1", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := Parse(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, q.Terms[0].MatchString(tt.str))
+		})
+	}
+}
+
+func TestTermCompare(t *testing.T) {
+	q, err := Parse("a=1 b!=1 c>1 d>=1 e<1 f<=1 g~1")
+	require.NoError(t, err)
+
+	want := map[string][3]bool{
+		// -1, 0, 1
+		"a": {false, true, false},
+		"b": {true, false, true},
+		"c": {false, false, true},
+		"d": {false, true, true},
+		"e": {true, false, false},
+		"f": {true, true, false},
+		"g": {false, false, false},
+	}
+	for _, term := range q.Terms {
+		for i, cmp := range []int{-1, 0, 1} {
+			assert.Equal(t, want[term.Key][i], term.Compare(cmp), "%s %d", term.Key, cmp)
+		}
+	}
+}
@@ -22,9 +22,67 @@ type TraceServiceSpanDataMap map[string]map[string][]*SpanData
 // the spans have any error status
 type TraceServiceHasErrorMap map[string]map[string]bool
 
-// TraceServiceParentIDMap is a map of trace id and service name to a parent span id
+// TraceServiceSpanForestMap is a map of trace id and service name to a span forest
 // This is used to update service root spans in the trace list.
-type TraceServiceParentIDMap map[string]map[string]*SpanData
+type TraceServiceSpanForestMap map[string]map[string]*spanForest
+
+// spanForest is a forest of the spans of a service in a trace. The roots are the spans
+// whose parent is not in the same service, so the service root span is resolved
+// correctly whatever order the spans arrive in.
+type spanForest struct {
+	spanIDs map[string]struct{}
+	// roots is ordered by arrival
+	roots []*SpanData
+	root  *SpanData
+}
+
+func newSpanForestWith(data *SpanData) *spanForest {
+	f := &spanForest{
+		spanIDs: map[string]struct{}{},
+	}
+	f.add(data)
+	return f
+}
+
+// add adds a span to the forest and updates the service root span
+func (f *spanForest) add(data *SpanData) {
+	spanID := data.Span.SpanID().String()
+	f.spanIDs[spanID] = struct{}{}
+
+	// the roots whose parent is the new span are not roots anymore
+	roots := make([]*SpanData, 0, len(f.roots)+1)
+	for _, r := range f.roots {
+		if r.Span.ParentSpanID().String() != spanID {
+			roots = append(roots, r)
+		}
+	}
+	if _, ok := f.spanIDs[data.Span.ParentSpanID().String()]; !ok {
+		roots = append(roots, data)
+	}
+	f.roots = roots
+
+	f.root = f.serviceRoot(data)
+}
+
+// serviceRoot returns the root which started first. The earlier arrived one is taken
+// when the start times are the same, e.g. the service is called multiple times in
+// the trace and the spans have the same start time.
+func (f *spanForest) serviceRoot(data *SpanData) *SpanData {
+	var root *SpanData
+	for _, r := range f.roots {
+		if root == nil || r.Span.StartTimestamp() < root.Span.StartTimestamp() {
+			root = r
+		}
+	}
+	if root != nil {
+		return root
+	}
+	// The spans have a cycle, which should not happen
+	if f.root != nil {
+		return f.root
+	}
+	return data
+}
 
 // TraceCache is a cache of trace spans
 type TraceCache struct {
@@ -33,7 +91,8 @@ type TraceCache struct {
 	traceid2spans     TraceSpanDataMap
 	tracesvc2spans    TraceServiceSpanDataMap
 	tracesvc2haserror TraceServiceHasErrorMap
-	tracesvc2parent   TraceServiceParentIDMap
+	tracesvc2forest   TraceServiceSpanForestMap
+	pinned            map[string]struct{}
 }
 
 // NewTraceCache returns a new trace cache
@@ -43,12 +102,14 @@ func NewTraceCache() *TraceCache {
 		traceid2spans:     TraceSpanDataMap{},
 		tracesvc2spans:    TraceServiceSpanDataMap{},
 		tracesvc2haserror: TraceServiceHasErrorMap{},
-		tracesvc2parent:   TraceServiceParentIDMap{},
+		tracesvc2forest:   TraceServiceSpanForestMap{},
+		pinned:            map[string]struct{}{},
 	}
 }
 
-// UpdateCache updates the cache with a new span
-func (c *TraceCache) UpdateCache(sname string, data *SpanData) (newtracesvc bool, replaceSpanID string) {
+// UpdateCache updates the cache with a new span. replaced is the previous service root span
+// if the service root span is changed by the new span.
+func (c *TraceCache) UpdateCache(sname string, data *SpanData) (newtracesvc bool, replaced *SpanData) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.spanid2span[data.Span.SpanID().String()] = data
@@ -58,14 +119,11 @@ func (c *TraceCache) UpdateCache(sname string, data *SpanData) (newtracesvc bool
 		c.traceid2spans[traceID] = append(ts, data)
 		if _, ok := c.tracesvc2spans[traceID][sname]; ok {
 			c.tracesvc2spans[traceID][sname] = append(c.tracesvc2spans[traceID][sname], data)
-			if c.tracesvc2parent[traceID][sname].Span.ParentSpanID().String() == data.Span.SpanID().String() {
-				// This span is higher parent span
-				// NOTE: In this process, for performance reasons, only adjacent parent-child relationships
-				//   between spans are evaluated. For example, if the parent-child order of spans is 1, 2, 3, and
-				//   the arrival order is 3, 1, 2, span 2 will be recognized as the service root span. To recalculate
-				//   the specific parent-child relationship, use `R` key to trigger deep refreshing
-				replaceSpanID = c.tracesvc2parent[traceID][sname].Span.SpanID().String()
-				c.tracesvc2parent[traceID][sname] = data
+			forest := c.tracesvc2forest[traceID][sname]
+			current := forest.root
+			forest.add(data)
+			if forest.root != current {
+				replaced = current
 			}
 			if hasError {
 				c.tracesvc2haserror[traceID][sname] = hasError
@@ -73,21 +131,22 @@ func (c *TraceCache) UpdateCache(sname string, data *SpanData) (newtracesvc bool
 		} else {
 			c.tracesvc2spans[traceID][sname] = []*SpanData{data}
 			c.tracesvc2haserror[traceID][sname] = hasError
-			c.tracesvc2parent[traceID][sname] = data
+			c.tracesvc2forest[traceID][sname] = newSpanForestWith(data)
 			newtracesvc = true
 		}
 	} else {
 		c.traceid2spans[traceID] = []*SpanData{data}
 		c.tracesvc2spans[traceID] = map[string][]*SpanData{sname: {data}}
 		c.tracesvc2haserror[traceID] = map[string]bool{sname: hasError}
-		c.tracesvc2parent[traceID] = map[string]*SpanData{sname: data}
+		c.tracesvc2forest[traceID] = map[string]*spanForest{sname: newSpanForestWith(data)}
 		newtracesvc = true
 	}
 
-	return newtracesvc, replaceSpanID
+	return newtracesvc, replaced
 }
 
-// DeleteCache deletes a list of spans from the cache
+// DeleteCache deletes a list of spans from the cache. The pin of the trace is removed
+// when no spans are left in the trace.
 func (c *TraceCache) DeleteCache(serviceSpans []*SpanData) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -103,14 +162,15 @@ func (c *TraceCache) DeleteCache(serviceSpans []*SpanData) {
 		}
 		delete(c.tracesvc2spans[traceID], sname)
 		delete(c.tracesvc2haserror[traceID], sname)
-		delete(c.tracesvc2parent[traceID], sname)
+		delete(c.tracesvc2forest[traceID], sname)
 		if len(c.tracesvc2spans[traceID]) == 0 {
 			delete(c.tracesvc2spans, traceID)
 			delete(c.tracesvc2haserror, traceID)
-			delete(c.tracesvc2parent, traceID)
+			delete(c.tracesvc2forest, traceID)
 			// delete spans in traceid2spans only if there are no spans left in tracesvc2spans
 			// for better performance
 			delete(c.traceid2spans, traceID)
+			delete(c.pinned, traceID)
 		}
 	}
 }
@@ -153,6 +213,30 @@ func (c *TraceCache) HasErrorByTraceIDAndSvc(traceID, svc string) (bool, bool) {
 	return false, false
 }
 
+// HasErrorByTraceID returns the flag whether any spans in the trace have errors
+func (c *TraceCache) HasErrorByTraceID(traceID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, haserr := range c.tracesvc2haserror[traceID] {
+		if haserr {
+			return true
+		}
+	}
+	return false
+}
+
+// GetServiceRootSpan returns the service root span for a given trace id and service name
+func (c *TraceCache) GetServiceRootSpan(traceID, svc string) (*SpanData, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if forests, ok := c.tracesvc2forest[traceID]; ok {
+		if forest, ok := forests[svc]; ok {
+			return forest.root, ok
+		}
+	}
+	return nil, false
+}
+
 // GetSpanByID returns a span by its id
 func (c *TraceCache) GetSpanByID(spanID string) (*SpanData, bool) {
 	c.mu.RLock()
@@ -161,6 +245,35 @@ func (c *TraceCache) GetSpanByID(spanID string) (*SpanData, bool) {
 	return span, ok
 }
 
+// Pin marks the trace as pinned. The pins are kept when the cache is flushed.
+func (c *TraceCache) Pin(traceID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pinned[traceID] = struct{}{}
+}
+
+// Unpin removes the pin of the trace
+func (c *TraceCache) Unpin(traceID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pinned, traceID)
+}
+
+// IsPinned returns whether the trace is pinned
+func (c *TraceCache) IsPinned(traceID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.pinned[traceID]
+	return ok
+}
+
+// HasPins returns whether any traces are pinned
+func (c *TraceCache) HasPins() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.pinned) > 0
+}
+
 func (c *TraceCache) DrawSpanDependencies() (string, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -173,6 +286,8 @@ func (c *TraceCache) flush() {
 	c.spanid2span = SpanDataMap{}
 	c.traceid2spans = TraceSpanDataMap{}
 	c.tracesvc2spans = TraceServiceSpanDataMap{}
+	c.tracesvc2haserror = TraceServiceHasErrorMap{}
+	c.tracesvc2forest = TraceServiceSpanForestMap{}
 }
 
 func spanHasError(span *ptrace.Span) bool {
@@ -1,9 +1,13 @@
 package telemetry
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 )
 
 func TestGetSpansByTraceID(t *testing.T) {
@@ -118,6 +122,88 @@ func TestGetSpanByID(t *testing.T) {
 	}
 }
 
+func TestGetServiceRootSpan(t *testing.T) {
+	// span 1 (started at 0s)
+	//  └- span 2 (started at 1s)
+	//    └- span 3 (started at 2s)
+	// span 4 (started at 3s, called by another service)
+	// span 5 (started at 5s, called by another service)
+	start := time.Date(2024, 3, 30, 12, 30, 0, 0, time.UTC)
+	newSpan := func(spanNo, parentNo int, startedAt time.Duration) *SpanData {
+		span := ptrace.NewSpan()
+		span.SetName(fmt.Sprintf("span %d", spanNo))
+		span.SetTraceID(testTraceID(1))
+		span.SetSpanID(testSpanID(1, spanNo))
+		span.SetParentSpanID(testSpanID(1, parentNo))
+		span.SetStartTimestamp(pcommon.NewTimestampFromTime(start.Add(startedAt)))
+		return &SpanData{Span: &span}
+	}
+	spans := map[int]*SpanData{
+		1: newSpan(1, 0, 0),
+		2: newSpan(2, 1, time.Second),
+		3: newSpan(3, 2, 2*time.Second),
+		4: newSpan(4, 99, 3*time.Second),
+		5: newSpan(5, 98, 5*time.Second),
+	}
+
+	tests := []struct {
+		name         string
+		order        []int
+		wantRoots    []string
+		wantReplaced []string
+	}{
+		{
+			name:         "in order",
+			order:        []int{1, 2, 3, 4, 5},
+			wantRoots:    []string{"span 1", "span 1", "span 1", "span 1", "span 1"},
+			wantReplaced: []string{"", "", "", "", ""},
+		},
+		{
+			name:         "reverse order",
+			order:        []int{5, 4, 3, 2, 1},
+			wantRoots:    []string{"span 5", "span 4", "span 3", "span 2", "span 1"},
+			wantReplaced: []string{"", "span 5", "span 4", "span 3", "span 2"},
+		},
+		{
+			name:         "not adjacent",
+			order:        []int{3, 1, 2},
+			wantRoots:    []string{"span 3", "span 1", "span 1"},
+			wantReplaced: []string{"", "span 3", ""},
+		},
+		{
+			name:         "with another root",
+			order:        []int{5, 3, 1, 2},
+			wantRoots:    []string{"span 5", "span 3", "span 1", "span 1"},
+			wantReplaced: []string{"", "span 5", "span 3", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewTraceCache()
+			for i, no := range tt.order {
+				newtracesvc, replaced := c.UpdateCache("test-service-1", spans[no])
+				assert.Equal(t, i == 0, newtracesvc)
+				replacedName := ""
+				if replaced != nil {
+					replacedName = replaced.Span.Name()
+				}
+				assert.Equal(t, tt.wantReplaced[i], replacedName)
+
+				got, ok := c.GetServiceRootSpan(testTraceID(1).String(), "test-service-1")
+				assert.True(t, ok)
+				assert.Equal(t, tt.wantRoots[i], got.Span.Name())
+			}
+		})
+	}
+
+	t.Run("not found", func(t *testing.T) {
+		c := NewTraceCache()
+		_, ok := c.GetServiceRootSpan(testTraceID(1).String(), "test-service-1")
+		assert.False(t, ok)
+	})
+}
+
 func TestGetLogsByTraceID(t *testing.T) {
 	c := NewLogCache()
 	logs := []*LogData{}
@@ -0,0 +1,182 @@
+package telemetry
+
+import (
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// TraceDiff is the comparison of the two traces
+type TraceDiff struct {
+	BaseDuration   time.Duration
+	TargetDuration time.Duration
+	// Spans is the aligned spans in the depth-first order of the span trees
+	Spans []*SpanDiff
+}
+
+// SpanDiff is the pair of the spans at the same path of the service and span names in the
+// two traces. Base or Target is nil if the span is missing in the trace.
+type SpanDiff struct {
+	Base   *SpanData
+	Target *SpanData
+	Depth  int
+	// Attributes is the span attributes differing between the matched spans
+	Attributes []AttributeDiff
+}
+
+// AttributeDiff is a span attribute having different values in the matched spans
+type AttributeDiff struct {
+	Key         string
+	BaseValue   string
+	TargetValue string
+	InBase      bool
+	InTarget    bool
+}
+
+// Span returns the span in the base trace, or the span in the target trace if it is missing
+// in the base
+func (d *SpanDiff) Span() *SpanData {
+	if d.Base != nil {
+		return d.Base
+	}
+	return d.Target
+}
+
+// DurationDelta returns the duration of the target span minus the base span. It is zero if
+// the span is missing in either of the traces.
+func (d *SpanDiff) DurationDelta() time.Duration {
+	if d.Base == nil || d.Target == nil {
+		return 0
+	}
+	return spanDuration(d.Target) - spanDuration(d.Base)
+}
+
+type diffNode struct {
+	span     *SpanData
+	children []*diffNode
+}
+
+// DiffTraces aligns the span trees of the two traces by the path of the service and span names.
+// The sibling spans having the same service and span name are matched in the order of the start
+// time.
+func (c *TraceCache) DiffTraces(baseTraceID, targetTraceID string) (*TraceDiff, bool) {
+	base, ok := c.GetSpansByTraceID(baseTraceID)
+	if !ok {
+		return nil, false
+	}
+	target, ok := c.GetSpansByTraceID(targetTraceID)
+	if !ok {
+		return nil, false
+	}
+
+	diff := &TraceDiff{
+		BaseDuration:   traceDuration(base),
+		TargetDuration: traceDuration(target),
+	}
+	alignSpans(newDiffTree(base), newDiffTree(target), 0, &diff.Spans)
+
+	return diff, true
+}
+
+func newDiffTree(spans []*SpanData) []*diffNode {
+	nodes := make(map[string]*diffNode, len(spans))
+	for _, sd := range spans {
+		nodes[sd.Span.SpanID().String()] = &diffNode{span: sd}
+	}
+	roots := []*diffNode{}
+	for _, sd := range spans {
+		node := nodes[sd.Span.SpanID().String()]
+		if parent, ok := nodes[sd.Span.ParentSpanID().String()]; ok {
+			parent.children = append(parent.children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	return roots
+}
+
+func alignSpans(base, target []*diffNode, depth int, diffs *[]*SpanDiff) {
+	sortDiffNodes(base)
+	sortDiffNodes(target)
+
+	matched := make([]bool, len(target))
+	for _, b := range base {
+		d := &SpanDiff{Base: b.span, Depth: depth}
+		var targetChildren []*diffNode
+		for i, t := range target {
+			if !matched[i] && diffKey(t.span) == diffKey(b.span) {
+				matched[i] = true
+				d.Target = t.span
+				d.Attributes = diffAttributes(b.span.Span.Attributes(), t.span.Span.Attributes())
+				targetChildren = t.children
+				break
+			}
+		}
+		*diffs = append(*diffs, d)
+		alignSpans(b.children, targetChildren, depth+1, diffs)
+	}
+	// the spans only in the target follow the ones in the base
+	for i, t := range target {
+		if matched[i] {
+			continue
+		}
+		*diffs = append(*diffs, &SpanDiff{Target: t.span, Depth: depth})
+		alignSpans(nil, t.children, depth+1, diffs)
+	}
+}
+
+func sortDiffNodes(nodes []*diffNode) {
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].span.Span.StartTimestamp().AsTime().Before(
+			nodes[j].span.Span.StartTimestamp().AsTime(),
+		)
+	})
+}
+
+func diffKey(sd *SpanData) string {
+	return sd.GetServiceName() + "/" + sd.Span.Name()
+}
+
+func diffAttributes(base, target pcommon.Map) []AttributeDiff {
+	keys := map[string]struct{}{}
+	collectKeys := func(k string, _ pcommon.Value) bool {
+		keys[k] = struct{}{}
+		return true
+	}
+	base.Range(collectKeys)
+	target.Range(collectKeys)
+
+	diffs := []AttributeDiff{}
+	for k := range keys {
+		d := AttributeDiff{Key: k}
+		if v, ok := base.Get(k); ok {
+			d.BaseValue, d.InBase = v.AsString(), true
+		}
+		if v, ok := target.Get(k); ok {
+			d.TargetValue, d.InTarget = v.AsString(), true
+		}
+		if d.InBase == d.InTarget && d.BaseValue == d.TargetValue {
+			continue
+		}
+		diffs = append(diffs, d)
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Key < diffs[j].Key
+	})
+	return diffs
+}
+
+func traceDuration(spans []*SpanData) time.Duration {
+	var start, end time.Time
+	for i, sd := range spans {
+		st, en := sd.Span.StartTimestamp().AsTime(), sd.Span.EndTimestamp().AsTime()
+		if i == 0 || st.Before(start) {
+			start = st
+		}
+		if i == 0 || en.After(end) {
+			end = en
+		}
+	}
+	return end.Sub(start)
+}
@@ -0,0 +1,91 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTraces(t *testing.T) {
+	// base (trace 1)               target (trace 2)
+	// api: GET /users [0, 100]     api: GET /users [0, 150] status code changed
+	//  ├- db: SELECT [10, 30]       ├- db: SELECT [10, 50]
+	//  ├- db: SELECT [40, 60]       ├- db: SELECT [60, 70]
+	//  └- cache: GET [70, 80]       └- auth: verify [80, 90]
+	ms := time.Millisecond
+	store := NewStore(clockwork.NewRealClock())
+	payload := generateTestTraces(
+		testSpan{traceID: 1, spanNo: 1, svc: "api", name: "GET /users", duration: 100 * ms, statusCode: 200},
+		testSpan{traceID: 1, spanNo: 2, parentNo: 1, svc: "db", name: "SELECT", offset: 10 * ms, duration: 20 * ms},
+		testSpan{traceID: 1, spanNo: 3, parentNo: 1, svc: "db", name: "SELECT", offset: 40 * ms, duration: 20 * ms},
+		testSpan{traceID: 1, spanNo: 4, parentNo: 1, svc: "cache", name: "GET", offset: 70 * ms, duration: 10 * ms},
+		testSpan{traceID: 2, spanNo: 1, svc: "api", name: "GET /users", duration: 150 * ms, statusCode: 500},
+		testSpan{traceID: 2, spanNo: 4, parentNo: 1, svc: "auth", name: "verify", offset: 80 * ms, duration: 10 * ms},
+		testSpan{traceID: 2, spanNo: 3, parentNo: 1, svc: "db", name: "SELECT", offset: 60 * ms, duration: 10 * ms},
+		testSpan{traceID: 2, spanNo: 2, parentNo: 1, svc: "db", name: "SELECT", offset: 10 * ms, duration: 40 * ms},
+	)
+	store.AddSpan(&payload)
+
+	got, ok := store.GetTraceCache().DiffTraces(testTraceID(1).String(), testTraceID(2).String())
+	require.True(t, ok)
+
+	assert.Equal(t, 100*ms, got.BaseDuration)
+	assert.Equal(t, 150*ms, got.TargetDuration)
+
+	type row struct {
+		name   string
+		depth  int
+		base   bool
+		target bool
+		delta  time.Duration
+	}
+	rows := make([]row, 0, len(got.Spans))
+	for _, d := range got.Spans {
+		rows = append(rows, row{
+			name:   d.Span().GetServiceName() + ": " + d.Span().Span.Name(),
+			depth:  d.Depth,
+			base:   d.Base != nil,
+			target: d.Target != nil,
+			delta:  d.DurationDelta(),
+		})
+	}
+	assert.Equal(t, []row{
+		{name: "api: GET /users", depth: 0, base: true, target: true, delta: 50 * ms},
+		{name: "db: SELECT", depth: 1, base: true, target: true, delta: 20 * ms},
+		{name: "db: SELECT", depth: 1, base: true, target: true, delta: -10 * ms},
+		{name: "cache: GET", depth: 1, base: true, target: false},
+		{name: "auth: verify", depth: 1, base: false, target: true},
+	}, rows)
+
+	assert.Equal(t, []AttributeDiff{
+		{Key: "http.response.status_code", BaseValue: "200", TargetValue: "500", InBase: true, InTarget: true},
+	}, got.Spans[0].Attributes)
+	assert.Empty(t, got.Spans[1].Attributes)
+
+	t.Run("not found", func(t *testing.T) {
+		_, ok := store.GetTraceCache().DiffTraces(testTraceID(1).String(), testTraceID(3).String())
+		assert.False(t, ok)
+	})
+}
+
+func TestDiffAttributes(t *testing.T) {
+	base := generateTestTraces(testSpan{traceID: 1, spanNo: 1}).ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	base.Attributes().PutStr("same", "v")
+	base.Attributes().PutStr("changed", "a")
+	base.Attributes().PutStr("removed", "r")
+	target := generateTestTraces(testSpan{traceID: 2, spanNo: 1}).ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	target.Attributes().PutStr("same", "v")
+	target.Attributes().PutStr("changed", "b")
+	target.Attributes().PutStr("added", "")
+
+	got := diffAttributes(base.Attributes(), target.Attributes())
+
+	assert.Equal(t, []AttributeDiff{
+		{Key: "added", InTarget: true},
+		{Key: "changed", BaseValue: "a", TargetValue: "b", InBase: true, InTarget: true},
+		{Key: "removed", BaseValue: "r", InBase: true},
+	}, got)
+}
@@ -0,0 +1,150 @@
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	TRACE_EVICTION_POLICY_FIFO           = "fifo"
+	TRACE_EVICTION_POLICY_KEEP_ERRORS    = "keep-errors"
+	TRACE_EVICTION_POLICY_FAIR_SHARE     = "fair-share"
+	TRACE_EVICTION_POLICY_DROP_BY_FILTER = "drop-by-filter"
+)
+
+// TraceEvictionPolicy chooses the service spans (rows in the trace table) to be evicted
+// when the number of them exceeds the max count
+type TraceEvictionPolicy interface {
+	// SelectEvictions returns n service spans to be evicted from svcspans, which is
+	// ordered by received time
+	SelectEvictions(svcspans SvcSpans, n int, cache *TraceCache) []*SpanData
+}
+
+// NewTraceEvictionPolicy returns the trace eviction policy of the given name.
+// The filter is a trace filter query used by the drop-by-filter policy.
+func NewTraceEvictionPolicy(name, filter string) (TraceEvictionPolicy, error) {
+	switch name {
+	case "", TRACE_EVICTION_POLICY_FIFO:
+		return fifoEviction{}, nil
+	case TRACE_EVICTION_POLICY_KEEP_ERRORS:
+		return keepErrorsEviction{}, nil
+	case TRACE_EVICTION_POLICY_FAIR_SHARE:
+		return fairShareEviction{}, nil
+	case TRACE_EVICTION_POLICY_DROP_BY_FILTER:
+		if filter == "" {
+			return nil, errors.New("filter is required for the drop-by-filter eviction policy")
+		}
+		q, err := compileTraceQuery(filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid eviction filter: %w", err)
+		}
+		return dropByFilterEviction{query: q}, nil
+	}
+	return nil, fmt.Errorf("unknown trace eviction policy %q, must be one of %s, %s, %s or %s",
+		name,
+		TRACE_EVICTION_POLICY_FIFO,
+		TRACE_EVICTION_POLICY_KEEP_ERRORS,
+		TRACE_EVICTION_POLICY_FAIR_SHARE,
+		TRACE_EVICTION_POLICY_DROP_BY_FILTER,
+	)
+}
+
+// WithTraceEvictionPolicy sets the policy to evict the service spans when the number of them
+// exceeds the max count. The oldest ones are evicted by default.
+func WithTraceEvictionPolicy(p TraceEvictionPolicy) StoreOption {
+	return func(s *Store) {
+		if p != nil {
+			s.traceEvictionPolicy = p
+		}
+	}
+}
+
+// fifoEviction evicts the oldest service spans
+type fifoEviction struct{}
+
+func (fifoEviction) SelectEvictions(svcspans SvcSpans, n int, _ *TraceCache) []*SpanData {
+	return svcspans[:min(n, len(svcspans))]
+}
+
+// keepErrorsEviction evicts the oldest service spans of the traces without errors first,
+// so the traces with errors are retained longest
+type keepErrorsEviction struct{}
+
+func (keepErrorsEviction) SelectEvictions(svcspans SvcSpans, n int, cache *TraceCache) []*SpanData {
+	hasError := make(map[string]bool)
+	return selectPreferred(svcspans, n, func(ss *SpanData) bool {
+		traceID := ss.Span.TraceID().String()
+		haserr, ok := hasError[traceID]
+		if !ok {
+			haserr = cache.HasErrorByTraceID(traceID)
+			hasError[traceID] = haserr
+		}
+		return !haserr
+	})
+}
+
+// dropByFilterEviction evicts the oldest service spans matching the filter first
+type dropByFilterEviction struct {
+	query *traceQuery
+}
+
+func (e dropByFilterEviction) SelectEvictions(svcspans SvcSpans, n int, cache *TraceCache) []*SpanData {
+	return selectPreferred(svcspans, n, func(ss *SpanData) bool {
+		return e.query.match(ss, cache)
+	})
+}
+
+// selectPreferred selects the oldest n service spans preferring the ones satisfying preferred.
+// The oldest ones are selected if there are not enough preferred ones.
+func selectPreferred(svcspans SvcSpans, n int, preferred func(ss *SpanData) bool) []*SpanData {
+	n = min(n, len(svcspans))
+	selected := make([]*SpanData, 0, n)
+	rest := make([]*SpanData, 0, n)
+	for _, ss := range svcspans {
+		if len(selected) == n {
+			return selected
+		}
+		if preferred(ss) {
+			selected = append(selected, ss)
+		} else if len(rest) < n {
+			rest = append(rest, ss)
+		}
+	}
+	return append(selected, rest[:n-len(selected)]...)
+}
+
+// fairShareEviction evicts the oldest service span of the service having the most service
+// spans one by one, so a noisy service does not push out the others
+type fairShareEviction struct{}
+
+func (fairShareEviction) SelectEvictions(svcspans SvcSpans, n int, _ *TraceCache) []*SpanData {
+	n = min(n, len(svcspans))
+	if n <= 0 {
+		return nil
+	}
+	// service spans per service in received order
+	bysvc := make(map[string][]*SpanData)
+	svcs := []string{}
+	for _, ss := range svcspans {
+		sname := ss.GetServiceName()
+		if _, ok := bysvc[sname]; !ok {
+			svcs = append(svcs, sname)
+		}
+		bysvc[sname] = append(bysvc[sname], ss)
+	}
+
+	selected := make([]*SpanData, 0, n)
+	for range n {
+		// the service having the most service spans, the one having the older span in a tie
+		target := svcs[0]
+		for _, sname := range svcs[1:] {
+			cur, best := bysvc[sname], bysvc[target]
+			if len(cur) > len(best) || (len(cur) == len(best) && len(cur) > 0 && cur[0].ReceivedAt.Before(best[0].ReceivedAt)) {
+				target = sname
+			}
+		}
+		selected = append(selected, bysvc[target][0])
+		bysvc[target] = bysvc[target][1:]
+	}
+	return selected
+}
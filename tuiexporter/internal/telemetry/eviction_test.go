@@ -0,0 +1,165 @@
+package telemetry
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreTraceEvictionPolicy(t *testing.T) {
+	// traces received in this order, one service span each
+	traces := []testSpan{
+		{traceID: 1, spanNo: 1, svc: "api"},
+		{traceID: 2, spanNo: 1, svc: "health", hasError: true},
+		{traceID: 3, spanNo: 1, svc: "health"},
+		{traceID: 4, spanNo: 1, svc: "health"},
+		{traceID: 5, spanNo: 1, svc: "worker"},
+	}
+
+	tests := []struct {
+		name   string
+		policy string
+		filter string
+		want   []string
+	}{
+		{
+			name:   "default",
+			policy: "",
+			want:   []string{"health/span-3-1", "health/span-4-1", "worker/span-5-1"},
+		},
+		{
+			name:   "fifo",
+			policy: TRACE_EVICTION_POLICY_FIFO,
+			want:   []string{"health/span-3-1", "health/span-4-1", "worker/span-5-1"},
+		},
+		{
+			name:   "keep errors",
+			policy: TRACE_EVICTION_POLICY_KEEP_ERRORS,
+			want:   []string{"health/span-2-1", "health/span-4-1", "worker/span-5-1"},
+		},
+		{
+			name:   "fair share",
+			policy: TRACE_EVICTION_POLICY_FAIR_SHARE,
+			want:   []string{"api/span-1-1", "health/span-4-1", "worker/span-5-1"},
+		},
+		{
+			name:   "drop by filter",
+			policy: TRACE_EVICTION_POLICY_DROP_BY_FILTER,
+			filter: "name=span-3-1",
+			want:   []string{"health/span-2-1", "health/span-4-1", "worker/span-5-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewTraceEvictionPolicy(tt.policy, tt.filter)
+			require.NoError(t, err)
+			store := NewStore(clockwork.NewRealClock(), WithMaxServiceSpanCount(3), WithTraceEvictionPolicy(policy))
+			for _, ts := range traces {
+				payload := generateTestTraces(ts)
+				store.AddSpan(&payload)
+			}
+
+			assert.Equal(t, tt.want, svcSpanNames(store.svcspans))
+			assert.Equal(t, tt.want, svcSpanNames(store.svcspansFiltered))
+			for _, ts := range traces {
+				_, ok := store.tracecache.GetSpansByTraceIDAndSvc(testTraceID(ts.traceID).String(), ts.svc)
+				assert.Equal(t, slices.Contains(tt.want, fmt.Sprintf("%s/span-%d-%d", ts.svc, ts.traceID, ts.spanNo)), ok)
+			}
+		})
+	}
+}
+
+func TestTraceEvictionPolicySelectEvictions(t *testing.T) {
+	// all spans are received at the same time
+	store := NewStore(clockwork.NewFakeClock())
+	payload := generateTestTraces(
+		testSpan{traceID: 1, spanNo: 1, svc: "api"},
+		testSpan{traceID: 2, spanNo: 1, svc: "health"},
+		testSpan{traceID: 3, spanNo: 1, svc: "api"},
+		testSpan{traceID: 4, spanNo: 1, svc: "api"},
+		testSpan{traceID: 5, spanNo: 1, svc: "health"},
+	)
+	store.AddSpan(&payload)
+
+	tests := []struct {
+		name   string
+		policy string
+		filter string
+		n      int
+		want   []string
+	}{
+		{
+			name:   "fifo",
+			policy: TRACE_EVICTION_POLICY_FIFO,
+			n:      2,
+			want:   []string{"api/span-1-1", "health/span-2-1"},
+		},
+		{
+			name:   "fifo more than svcspans",
+			policy: TRACE_EVICTION_POLICY_FIFO,
+			n:      6,
+			want:   []string{"api/span-1-1", "health/span-2-1", "api/span-3-1", "api/span-4-1", "health/span-5-1"},
+		},
+		{
+			name:   "fair share",
+			policy: TRACE_EVICTION_POLICY_FAIR_SHARE,
+			n:      3,
+			want:   []string{"api/span-1-1", "api/span-3-1", "health/span-2-1"},
+		},
+		{
+			name:   "drop by filter with not enough matches",
+			policy: TRACE_EVICTION_POLICY_DROP_BY_FILTER,
+			filter: "service.name=health",
+			n:      3,
+			want:   []string{"health/span-2-1", "health/span-5-1", "api/span-1-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewTraceEvictionPolicy(tt.policy, tt.filter)
+			require.NoError(t, err)
+			got := policy.SelectEvictions(store.svcspans, tt.n, store.tracecache)
+			assert.Equal(t, tt.want, svcSpanNames(got))
+		})
+	}
+}
+
+func TestNewTraceEvictionPolicyError(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		filter string
+		want   string
+	}{
+		{
+			name:   "unknown policy",
+			policy: "lru",
+			want:   `unknown trace eviction policy "lru", must be one of fifo, keep-errors, fair-share or drop-by-filter`,
+		},
+		{
+			name:   "drop by filter without filter",
+			policy: TRACE_EVICTION_POLICY_DROP_BY_FILTER,
+			want:   "filter is required for the drop-by-filter eviction policy",
+		},
+		{
+			name:   "invalid filter",
+			policy: TRACE_EVICTION_POLICY_DROP_BY_FILTER,
+			filter: "duration>fast",
+			want:   `invalid eviction filter: invalid duration "fast" at column 1`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTraceEvictionPolicy(tt.policy, tt.filter)
+			require.Error(t, err)
+			assert.Equal(t, tt.want, err.Error())
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package telemetry
+
+import (
+	"io"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// The export functions below write the filtered telemetry as a line of OTLP JSON,
+// which is the format the otlpjsonfile receiver reads.
+
+// ExportFilteredTraces writes the spans of the filtered service spans to the writer
+// and returns the number of written spans
+func (s *Store) ExportFilteredTraces(w io.Writer) (int, error) {
+	s.mut.Lock()
+	spans := []*SpanData{}
+	for _, ss := range s.svcspansFiltered {
+		if svcSpans, ok := s.tracecache.GetSpansByTraceIDAndSvc(ss.Span.TraceID().String(), ss.GetServiceName()); ok {
+			spans = append(spans, svcSpans...)
+		}
+	}
+	traces := buildTraces(spans)
+	s.mut.Unlock()
+
+	if len(spans) == 0 {
+		return 0, nil
+	}
+
+	b, err := (&ptrace.JSONMarshaler{}).MarshalTraces(traces)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(spans), writeJSONLine(w, b)
+}
+
+// ExportFilteredMetrics writes the points of the filtered metric series to the writer and
+// returns the number of written points
+func (s *Store) ExportFilteredMetrics(w io.Writer) (int, error) {
+	s.mut.Lock()
+	points := []*MetricData{}
+	for _, ms := range s.metricsFiltered {
+		points = append(points, ms.Points()...)
+	}
+	metrics := buildMetrics(points)
+	s.mut.Unlock()
+
+	if len(points) == 0 {
+		return 0, nil
+	}
+
+	b, err := (&pmetric.JSONMarshaler{}).MarshalMetrics(metrics)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(points), writeJSONLine(w, b)
+}
+
+// ExportFilteredLogs writes the filtered logs to the writer and returns
+// the number of written logs
+func (s *Store) ExportFilteredLogs(w io.Writer) (int, error) {
+	s.mut.Lock()
+	count := len(s.logsFiltered)
+	logs := buildLogs(s.logsFiltered)
+	s.mut.Unlock()
+
+	if count == 0 {
+		return 0, nil
+	}
+
+	b, err := (&plog.JSONMarshaler{}).MarshalLogs(logs)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, writeJSONLine(w, b)
+}
+
+func writeJSONLine(w io.Writer, b []byte) error {
+	_, err := w.Write(append(b, '\n'))
+	return err
+}
@@ -0,0 +1,85 @@
+package telemetry
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/test"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestStoreExportFilteredTraces(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock())
+	payload, _ := test.GenerateOTLPTracesPayload(t, 1, 2, []int{2, 1}, [][]int{{2, 1}, {1}})
+	store.AddSpan(&payload)
+	assert.NoError(t, store.ApplyFilterTraces("test-service-1", SORT_TYPE_NONE))
+
+	var buf bytes.Buffer
+	count, err := store.ExportFilteredTraces(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	assert.Equal(t, 1, len(lines))
+	got, err := (&ptrace.JSONUnmarshaler{}).UnmarshalTraces(lines[0])
+	require.NoError(t, err)
+	assert.Equal(t, 3, got.SpanCount())
+	assert.Equal(t, 1, got.ResourceSpans().Len())
+	assert.Equal(t, 2, got.ResourceSpans().At(0).ScopeSpans().Len())
+	assert.Equal(t, "test-service-1", GetServiceNameFromResource(got.ResourceSpans().At(0).Resource()))
+}
+
+func TestStoreExportFilteredMetrics(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock())
+	payload, _ := test.GenerateOTLPGaugeMetricsPayload(t, 2, []int{2, 1}, [][]int{{2, 1}, {1}})
+	store.AddMetric(&payload)
+	store.ApplyFilterMetrics("service-2")
+
+	var buf bytes.Buffer
+	count, err := store.ExportFilteredMetrics(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	got, err := (&pmetric.JSONUnmarshaler{}).UnmarshalMetrics(bytes.TrimSpace(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.MetricCount())
+	assert.Equal(t, "test-service-2", GetServiceNameFromResource(got.ResourceMetrics().At(0).Resource()))
+}
+
+func TestStoreExportFilteredLogs(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock())
+	payload, _ := test.GenerateOTLPLogsPayload(t, 1, 2, []int{2, 1}, [][]int{{2, 1}, {1}})
+	store.AddLog(&payload)
+	assert.NoError(t, store.ApplyFilterLogs("log body 1-0-0-0"))
+
+	var buf bytes.Buffer
+	count, err := store.ExportFilteredLogs(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	got, err := (&plog.JSONUnmarshaler{}).UnmarshalLogs(bytes.TrimSpace(buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.LogRecordCount())
+	assert.Equal(t, "log body 1-0-0-0", got.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().AsString())
+}
+
+func TestStoreExportEmpty(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock())
+
+	var buf bytes.Buffer
+	count, err := store.ExportFilteredTraces(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+	count, err = store.ExportFilteredMetrics(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+	count, err = store.ExportFilteredLogs(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, 0, buf.Len())
+}
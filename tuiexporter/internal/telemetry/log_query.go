@@ -0,0 +1,163 @@
+package telemetry
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/query"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	LOG_QUERY_KEY_SEVERITY   = "severity"
+	LOG_QUERY_KEY_BODY       = "body"
+	LOG_QUERY_KEY_EVENT_NAME = "event.name"
+	LOG_QUERY_KEY_TRACE_ID   = "trace_id"
+	LOG_QUERY_KEY_SPAN_ID    = "span_id"
+)
+
+// logQuerySeverities is the lowest severity number of each severity level
+var logQuerySeverities = map[string]plog.SeverityNumber{
+	"trace":   plog.SeverityNumberTrace,
+	"debug":   plog.SeverityNumberDebug,
+	"info":    plog.SeverityNumberInfo,
+	"warn":    plog.SeverityNumberWarn,
+	"warning": plog.SeverityNumberWarn,
+	"error":   plog.SeverityNumberError,
+	"fatal":   plog.SeverityNumberFatal,
+}
+
+// logQuery is a compiled query for the log table
+type logQuery struct {
+	terms []func(l *LogData) bool
+}
+
+func compileLogQuery(input string) (*logQuery, error) {
+	q, err := query.Parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	lq := &logQuery{}
+	for _, term := range q.Terms {
+		f, err := compileLogTerm(term)
+		if err != nil {
+			return nil, err
+		}
+		lq.terms = append(lq.terms, f)
+	}
+
+	return lq, nil
+}
+
+func compileLogTerm(term *query.Term) (func(l *LogData) bool, error) {
+	if term.IsText() {
+		return func(l *LogData) bool {
+			return term.MatchString(l.GetServiceName() + " " + l.Log.Body().AsString())
+		}, nil
+	}
+
+	switch term.Key {
+	case LOG_QUERY_KEY_SEVERITY:
+		return compileSeverityTerm(term)
+	case LOG_QUERY_KEY_BODY:
+		return func(l *LogData) bool {
+			return term.MatchString(l.Log.Body().AsString())
+		}, nil
+	case LOG_QUERY_KEY_EVENT_NAME:
+		return func(l *LogData) bool {
+			return term.MatchString(l.GetEventName())
+		}, nil
+	case LOG_QUERY_KEY_TRACE_ID:
+		// the ID is empty if it is not set, so `trace_id!=""` matches logs with a trace ID
+		return func(l *LogData) bool {
+			return term.MatchString(l.Log.TraceID().String())
+		}, nil
+	case LOG_QUERY_KEY_SPAN_ID:
+		return func(l *LogData) bool {
+			return term.MatchString(l.Log.SpanID().String())
+		}, nil
+	}
+
+	return func(l *LogData) bool {
+		sources := []attributeSource{
+			{prefix: "log.", attrs: l.Log.Attributes()},
+		}
+		if l.ScopeLog != nil {
+			sources = append(sources, attributeSource{prefix: "scope.", attrs: l.ScopeLog.Scope().Attributes()})
+		}
+		sources = append(sources, attributeSource{prefix: "resource.", attrs: l.ResourceLog.Resource().Attributes()})
+		return matchAttribute(term, sources...)
+	}, nil
+}
+
+// compileSeverityTerm compiles a term such as `severity>=WARN`. A severity level without
+// a suffix number (e.g. WARN) covers all of its severity numbers (WARN to WARN4).
+func compileSeverityTerm(term *query.Term) (func(l *LogData) bool, error) {
+	if term.Op.IsRegex() {
+		return func(l *LogData) bool {
+			return term.MatchString(l.Log.SeverityText())
+		}, nil
+	}
+	lo, hi, ok := parseSeverityRange(term.Value)
+	if !ok {
+		return nil, term.Errorf("invalid severity %q, must be one of trace, debug, info, warn, error, fatal or 1-24", term.Value)
+	}
+
+	return func(l *LogData) bool {
+		n := l.Log.SeverityNumber()
+		if n == plog.SeverityNumberUnspecified {
+			// fall back to the severity text such as "WARN"
+			n, _, _ = parseSeverityRange(l.Log.SeverityText())
+		}
+		switch {
+		case n == plog.SeverityNumberUnspecified:
+			return term.Op == query.OP_NE
+		case n < lo:
+			return term.Compare(-1)
+		case n > hi:
+			return term.Compare(1)
+		}
+		return term.Compare(0)
+	}, nil
+}
+
+// parseSeverityRange parses a severity level (e.g. WARN, warn3) or a severity number
+// and returns the range of severity numbers
+func parseSeverityRange(s string) (plog.SeverityNumber, plog.SeverityNumber, bool) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < int(plog.SeverityNumberTrace) || n > int(plog.SeverityNumberFatal4) {
+			return plog.SeverityNumberUnspecified, plog.SeverityNumberUnspecified, false
+		}
+		sn := plog.SeverityNumber(n) // #nosec G115
+		return sn, sn, true
+	}
+
+	s = strings.ToLower(s)
+	if base, ok := logQuerySeverities[s]; ok {
+		return base, base + 3, true
+	}
+	if len(s) > 1 {
+		if base, ok := logQuerySeverities[s[:len(s)-1]]; ok {
+			if d := s[len(s)-1]; d >= '1' && d <= '4' {
+				sn := base + plog.SeverityNumber(d-'1')
+				return sn, sn, true
+			}
+		}
+	}
+
+	return plog.SeverityNumberUnspecified, plog.SeverityNumberUnspecified, false
+}
+
+func (q *logQuery) isEmpty() bool {
+	return len(q.terms) == 0
+}
+
+func (q *logQuery) match(l *LogData) bool {
+	for _, f := range q.terms {
+		if !f(l) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,135 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/test"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestStoreApplyFilterLogsQuery(t *testing.T) {
+	// traceid: 1
+	//  └- resource: test-service-1
+	//  | └- scope: test-scope-1-1
+	//  |   └- log: log body 0-0-0-0 (WARN2, http.route: /users, event.name: user.created)
+	//  |   └- log: connection timeout to db (severity text only: ERROR)
+	//  └- resource: test-service-2
+	//    └- scope: test-scope-2-1
+	//      └- log: log body 1-0-0-0 (INFO, no trace and span ID)
+	//      └- log: log body 1-0-0-1 (INFO)
+	store := NewStore(clockwork.NewRealClock())
+	payload, testdata := test.GenerateOTLPLogsPayload(t, 1, 2, []int{1, 1}, [][]int{{1}, {1}})
+	testdata.Logs[0].SetSeverityNumber(plog.SeverityNumberWarn2)
+	testdata.Logs[0].SetSeverityText("WARN")
+	testdata.Logs[0].Attributes().PutStr("http.route", "/users")
+	testdata.Logs[0].Attributes().PutStr("event.name", "user.created")
+	testdata.Logs[1].SetSeverityNumber(plog.SeverityNumberUnspecified)
+	testdata.Logs[1].SetSeverityText("ERROR")
+	testdata.Logs[1].Body().SetStr("connection timeout to db")
+	testdata.Logs[2].SetTraceID(pcommon.NewTraceIDEmpty())
+	testdata.Logs[2].SetSpanID(pcommon.NewSpanIDEmpty())
+	store.AddLog(&payload)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "empty",
+			query: "",
+			want:  []string{"log body 0-0-0-0", "connection timeout to db", "log body 1-0-0-0", "log body 1-0-0-1"},
+		},
+		{name: "free text", query: "service-2 1-0-0-1", want: []string{"log body 1-0-0-1"}},
+		{name: "severity ge level", query: "severity>=WARN", want: []string{"log body 0-0-0-0", "connection timeout to db"}},
+		{name: "severity eq level", query: "severity=warn", want: []string{"log body 0-0-0-0"}},
+		{name: "severity eq level with number", query: "severity=WARN2", want: []string{"log body 0-0-0-0"}},
+		{name: "severity eq number", query: "severity=13", want: []string{}},
+		{name: "severity lt level", query: "severity<WARN", want: []string{"log body 1-0-0-0", "log body 1-0-0-1"}},
+		{name: "severity gt level", query: "severity>warning", want: []string{"connection timeout to db"}},
+		{name: "severity ne level", query: "severity!=info", want: []string{"log body 0-0-0-0", "connection timeout to db"}},
+		{name: "severity regex", query: "severity~^ERR", want: []string{"connection timeout to db"}},
+		{name: "body regex", query: `body~"timeout.*db"`, want: []string{"connection timeout to db"}},
+		{name: "body not regex", query: "body!~timeout", want: []string{"log body 0-0-0-0", "log body 1-0-0-0", "log body 1-0-0-1"}},
+		{name: "event name", query: "event.name=user.created", want: []string{"log body 0-0-0-0"}},
+		{name: "log attribute", query: "http.route=/users", want: []string{"log body 0-0-0-0"}},
+		{name: "prefixed log attribute", query: "log.http.route=/users", want: []string{"log body 0-0-0-0"}},
+		{name: "resource attribute", query: "resource.service.name=test-service-2", want: []string{"log body 1-0-0-0", "log body 1-0-0-1"}},
+		{name: "without trace id", query: `trace_id=""`, want: []string{"log body 1-0-0-0"}},
+		{name: "with span id", query: `span_id!=""`, want: []string{"log body 0-0-0-0", "connection timeout to db", "log body 1-0-0-1"}},
+		{name: "combined", query: `service.name=test-service-1 severity>=error body~timeout`, want: []string{"connection timeout to db"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, store.ApplyFilterLogs(tt.query))
+
+			got := []string{}
+			for _, l := range store.logsFiltered {
+				got = append(got, l.Log.Body().AsString())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStoreApplyFilterLogsQueryError(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock())
+	payload, _ := test.GenerateOTLPLogsPayload(t, 1, 2, []int{1, 1}, [][]int{{1}, {1}})
+	store.AddLog(&payload)
+	require.NoError(t, store.ApplyFilterLogs("service-2"))
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "syntax error", query: `body="timeout`, want: "unterminated quoted string at column 6"},
+		{name: "invalid regex", query: "body~(", want: "invalid regular expression: error parsing regexp: missing closing ): `(` at column 6"},
+		{name: "invalid severity", query: "severity>=loud", want: `invalid severity "loud", must be one of trace, debug, info, warn, error, fatal or 1-24 at column 1`},
+		{name: "severity out of range", query: "severity=25", want: `invalid severity "25", must be one of trace, debug, info, warn, error, fatal or 1-24 at column 1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := store.ApplyFilterLogs(tt.query)
+			require.Error(t, err)
+			assert.Equal(t, tt.want, err.Error())
+
+			// the current filter is kept
+			assert.Equal(t, "service-2", store.filterLog)
+			assert.Equal(t, 2, len(store.logsFiltered))
+		})
+	}
+}
+
+func TestParseSeverityRange(t *testing.T) {
+	tests := []struct {
+		input  string
+		wantLo plog.SeverityNumber
+		wantHi plog.SeverityNumber
+		wantOk bool
+	}{
+		{input: "TRACE", wantLo: plog.SeverityNumberTrace, wantHi: plog.SeverityNumberTrace4, wantOk: true},
+		{input: "Info", wantLo: plog.SeverityNumberInfo, wantHi: plog.SeverityNumberInfo4, wantOk: true},
+		{input: "warning", wantLo: plog.SeverityNumberWarn, wantHi: plog.SeverityNumberWarn4, wantOk: true},
+		{input: "error3", wantLo: plog.SeverityNumberError3, wantHi: plog.SeverityNumberError3, wantOk: true},
+		{input: "24", wantLo: plog.SeverityNumberFatal4, wantHi: plog.SeverityNumberFatal4, wantOk: true},
+		{input: "error5", wantOk: false},
+		{input: "0", wantOk: false},
+		{input: "", wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lo, hi, ok := parseSeverityRange(tt.input)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.wantLo, lo)
+			assert.Equal(t, tt.wantHi, hi)
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package telemetry
+
+// TogglePinTrace pins the trace, or unpins it if it is already pinned, and returns whether
+// the trace is pinned. Pinned traces are exempt from the rotation, the memory limit, the TTL
+// and Flush. A trace which is not in the store is not pinned.
+func (s *Store) TogglePinTrace(traceID string) bool {
+	if traceID == "" {
+		return false
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	defer s.markUpdatedLocked()
+
+	pinned := !s.tracecache.IsPinned(traceID)
+	if pinned {
+		if _, ok := s.tracecache.GetSpansByTraceID(traceID); !ok {
+			return false
+		}
+		s.tracecache.Pin(traceID)
+	} else {
+		s.tracecache.Unpin(traceID)
+	}
+	if s.filterPinnedOnly {
+		s.refilterSvcSpans()
+	}
+
+	return pinned
+}
+
+// IsPinned returns whether the trace is pinned
+func (s *Store) IsPinned(traceID string) bool {
+	return s.tracecache.IsPinned(traceID)
+}
+
+// TogglePinnedOnly toggles the pinned view, which shows only the pinned traces in the trace
+// table on top of the filter, and returns whether the pinned view is enabled.
+func (s *Store) TogglePinnedOnly() bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	defer s.markUpdatedLocked()
+
+	s.filterPinnedOnly = !s.filterPinnedOnly
+	s.refilterSvcSpans()
+
+	return s.filterPinnedOnly
+}
+
+// IsPinnedOnly returns whether the pinned view is enabled
+func (s *Store) IsPinnedOnly() bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.filterPinnedOnly
+}
+
+// isPinned returns whether the trace of the service span is pinned
+func (s *Store) isPinned(ss *SpanData) bool {
+	return s.tracecache.IsPinned(ss.Span.TraceID().String())
+}
+
+// unpinnedSvcSpans returns the service spans of the traces which are not pinned
+// keeping the received order
+func (s *Store) unpinnedSvcSpans() SvcSpans {
+	if !s.tracecache.HasPins() {
+		return s.svcspans
+	}
+	unpinned := make(SvcSpans, 0, len(s.svcspans))
+	for _, ss := range s.svcspans {
+		if !s.isPinned(ss) {
+			unpinned = append(unpinned, ss)
+		}
+	}
+	return unpinned
+}
+
+// flushUnpinnedSvcSpans deletes the service spans and their spans in the cache except
+// the pinned ones. The memory usage of the pinned ones is added to s.memoryBytes.
+func (s *Store) flushUnpinnedSvcSpans() {
+	var (
+		pinned   = SvcSpans{}
+		unpinned = SvcSpans{}
+	)
+	for _, ss := range s.svcspans {
+		if s.isPinned(ss) {
+			pinned = append(pinned, ss)
+			s.memoryBytes += s.svcSpanSize(ss)
+		} else {
+			unpinned = append(unpinned, ss)
+		}
+	}
+
+	s.tracecache.DeleteCache(unpinned)
+	s.svcspans = pinned
+	s.svcspansFiltered = removeItems(s.svcspansFiltered, toSet(unpinned))
+}
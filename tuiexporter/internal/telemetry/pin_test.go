@@ -0,0 +1,202 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreTogglePinTrace(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock())
+	payload := generateTestTraces(
+		testSpan{traceID: 1, spanNo: 1, svc: "api"},
+		testSpan{traceID: 1, spanNo: 2, parentNo: 1, svc: "db"},
+		testSpan{traceID: 2, spanNo: 1, svc: "api"},
+	)
+	store.AddSpan(&payload)
+	traceID := testTraceID(1).String()
+
+	assert.False(t, store.IsPinned(traceID))
+	assert.True(t, store.TogglePinTrace(traceID))
+	assert.True(t, store.IsPinned(traceID))
+	assert.False(t, store.IsPinned(testTraceID(2).String()))
+	assert.False(t, store.TogglePinTrace(traceID))
+	assert.False(t, store.IsPinned(traceID))
+	assert.False(t, store.TogglePinTrace(""))
+
+	// a trace not in the store is not pinned
+	assert.False(t, store.TogglePinTrace(testTraceID(3).String()))
+	assert.False(t, store.IsPinned(testTraceID(3).String()))
+	assert.False(t, store.tracecache.HasPins())
+}
+
+func TestTraceCachePinRemovedWithTrace(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock())
+	payload := generateTestTraces(
+		testSpan{traceID: 1, spanNo: 1, svc: "api"},
+		testSpan{traceID: 1, spanNo: 2, parentNo: 1, svc: "db"},
+	)
+	store.AddSpan(&payload)
+	traceID := testTraceID(1).String()
+	require.True(t, store.TogglePinTrace(traceID))
+	require.Equal(t, 2, len(store.svcspans))
+
+	// the pin is kept while any spans are left in the trace
+	store.tracecache.DeleteCache(store.svcspans[:1])
+	assert.True(t, store.IsPinned(traceID))
+
+	store.tracecache.DeleteCache(store.svcspans[1:])
+	assert.False(t, store.IsPinned(traceID))
+	assert.False(t, store.tracecache.HasPins())
+}
+
+func TestStorePinnedTraceSurvivesRotation(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock(), WithMaxServiceSpanCount(2))
+	for i := 1; i <= 2; i++ {
+		payload := generateTestTraces(testSpan{traceID: i, spanNo: 1, svc: "api"})
+		store.AddSpan(&payload)
+	}
+	store.TogglePinTrace(testTraceID(1).String())
+
+	for i := 3; i <= 4; i++ {
+		payload := generateTestTraces(testSpan{traceID: i, spanNo: 1, svc: "api"})
+		store.AddSpan(&payload)
+	}
+
+	want := []string{"api/span-1-1", "api/span-4-1"}
+	assert.Equal(t, want, svcSpanNames(store.svcspans))
+	assert.Equal(t, want, svcSpanNames(store.svcspansFiltered))
+	_, ok := store.tracecache.GetSpansByTraceID(testTraceID(1).String())
+	assert.True(t, ok)
+
+	t.Run("evicted after unpinned", func(t *testing.T) {
+		store.TogglePinTrace(testTraceID(1).String())
+		payload := generateTestTraces(testSpan{traceID: 5, spanNo: 1, svc: "api"})
+		store.AddSpan(&payload)
+
+		assert.Equal(t, []string{"api/span-4-1", "api/span-5-1"}, svcSpanNames(store.svcspans))
+	})
+}
+
+func TestStorePinnedTraceSurvivesFlush(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock())
+	payload := generateTestTraces(
+		testSpan{traceID: 1, spanNo: 1, svc: "api"},
+		testSpan{traceID: 2, spanNo: 1, svc: "api"},
+		testSpan{traceID: 2, spanNo: 2, parentNo: 1, svc: "db"},
+		testSpan{traceID: 3, spanNo: 1, svc: "api"},
+	)
+	store.AddSpan(&payload)
+	pinned := testTraceID(2).String()
+	store.TogglePinTrace(pinned)
+	pinnedSize := store.svcSpanSize(store.svcspans[1]) + store.svcSpanSize(store.svcspans[2])
+
+	var flushed int
+	store.RegisterOnFlushed(func() { flushed++ })
+	store.Flush()
+
+	want := []string{"api/span-2-1", "db/span-2-2"}
+	assert.Equal(t, want, svcSpanNames(store.svcspans))
+	assert.Equal(t, want, svcSpanNames(store.svcspansFiltered))
+	spans, ok := store.tracecache.GetSpansByTraceID(pinned)
+	assert.True(t, ok)
+	assert.Len(t, spans, 2)
+	_, ok = store.tracecache.GetSpansByTraceID(testTraceID(1).String())
+	assert.False(t, ok)
+	assert.Len(t, store.tracecache.spanid2span, 2)
+	assert.Equal(t, pinnedSize, store.memoryBytes)
+	assert.True(t, store.IsPinned(pinned))
+	assert.Equal(t, 1, flushed)
+
+	t.Run("flushed after unpinned", func(t *testing.T) {
+		store.TogglePinTrace(pinned)
+		store.Flush()
+
+		assert.Empty(t, store.svcspans)
+		assert.Empty(t, store.tracecache.spanid2span)
+		assert.Equal(t, int64(0), store.memoryBytes)
+	})
+}
+
+func TestStorePinnedTraceNeverExpires(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	store := NewStore(clock, WithTTL(time.Minute))
+	payload := generateTestTraces(
+		testSpan{traceID: 1, spanNo: 1, svc: "api"},
+		testSpan{traceID: 2, spanNo: 1, svc: "api"},
+	)
+	store.AddSpan(&payload)
+	store.TogglePinTrace(testTraceID(2).String())
+
+	clock.Advance(2 * time.Minute)
+	store.deleteExpired()
+
+	assert.Equal(t, []string{"api/span-2-1"}, svcSpanNames(store.svcspans))
+}
+
+func TestStorePinnedTraceSurvivesMemoryLimit(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	store := NewStore(clock)
+	for i := 1; i <= 3; i++ {
+		payload := generateTestTraces(testSpan{traceID: i, spanNo: 1, svc: "api"})
+		store.AddSpan(&payload)
+		clock.Advance(time.Second)
+	}
+	store.TogglePinTrace(testTraceID(1).String())
+
+	// leave room for two traces
+	store.maxMemoryBytes = store.memoryBytes * 2 / 3
+	payload := generateTestTraces(testSpan{traceID: 4, spanNo: 1, svc: "api"})
+	store.AddSpan(&payload)
+
+	names := svcSpanNames(store.svcspans)
+	assert.Contains(t, names, "api/span-1-1")
+	assert.NotContains(t, names, "api/span-2-1")
+	assert.Contains(t, names, "api/span-4-1")
+}
+
+func TestStoreTogglePinnedOnly(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock())
+	payload := generateTestTraces(
+		testSpan{traceID: 1, spanNo: 1, svc: "api"},
+		testSpan{traceID: 2, spanNo: 1, svc: "api"},
+		testSpan{traceID: 2, spanNo: 2, parentNo: 1, svc: "db"},
+		testSpan{traceID: 3, spanNo: 1, svc: "worker"},
+	)
+	store.AddSpan(&payload)
+	store.TogglePinTrace(testTraceID(2).String())
+
+	assert.False(t, store.IsPinnedOnly())
+	assert.True(t, store.TogglePinnedOnly())
+	assert.True(t, store.IsPinnedOnly())
+	assert.Equal(t, []string{"api/span-2-1", "db/span-2-2"}, svcSpanNames(store.SnapshotSvcSpans().items))
+
+	t.Run("combined with the filter", func(t *testing.T) {
+		require.NoError(t, store.ApplyFilterTraces("service.name=db", SORT_TYPE_NONE))
+		assert.Equal(t, []string{"db/span-2-2"}, svcSpanNames(store.svcspansFiltered))
+		require.NoError(t, store.ApplyFilterTraces("", SORT_TYPE_NONE))
+	})
+
+	t.Run("new spans of pinned traces", func(t *testing.T) {
+		payload := generateTestTraces(
+			testSpan{traceID: 2, spanNo: 3, parentNo: 1, svc: "cache"},
+			testSpan{traceID: 4, spanNo: 1, svc: "api"},
+		)
+		store.AddSpan(&payload)
+		assert.Equal(t, []string{"api/span-2-1", "db/span-2-2", "cache/span-2-3"}, svcSpanNames(store.svcspansFiltered))
+	})
+
+	t.Run("pin and unpin", func(t *testing.T) {
+		store.TogglePinTrace(testTraceID(4).String())
+		store.TogglePinTrace(testTraceID(2).String())
+		assert.Equal(t, []string{"api/span-4-1"}, svcSpanNames(store.svcspansFiltered))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.False(t, store.TogglePinnedOnly())
+		assert.Len(t, store.svcspansFiltered, 6)
+	})
+}
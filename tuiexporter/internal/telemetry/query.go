@@ -0,0 +1,47 @@
+package telemetry
+
+import (
+	"strings"
+
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/query"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// attributeSource is a set of attributes which can be specified by the key prefix in the query
+type attributeSource struct {
+	prefix string
+	attrs  pcommon.Map
+}
+
+// lookupAttribute looks up the attribute from the sources. If the key has the prefix of a source,
+// the attribute is looked up only from the source, otherwise the sources are looked up in order.
+func lookupAttribute(key string, sources ...attributeSource) (pcommon.Value, bool) {
+	for _, src := range sources {
+		if k, ok := strings.CutPrefix(key, src.prefix); ok {
+			if v, ok := src.attrs.Get(k); ok {
+				return v, true
+			}
+		}
+	}
+	// the prefix can be a part of the attribute key such as `log.file.name`
+	for _, src := range sources {
+		if v, ok := src.attrs.Get(key); ok {
+			return v, true
+		}
+	}
+	return pcommon.NewValueEmpty(), false
+}
+
+// matchAttribute returns whether the attribute of the term key satisfies the term
+func matchAttribute(term *query.Term, sources ...attributeSource) bool {
+	v, ok := lookupAttribute(term.Key, sources...)
+	if !ok {
+		// a missing attribute is not equal to anything
+		return isNegativeOp(term.Op)
+	}
+	return term.MatchString(v.AsString())
+}
+
+func isNegativeOp(op query.Op) bool {
+	return op == query.OP_NE || op == query.OP_NOT_MATCH
+}
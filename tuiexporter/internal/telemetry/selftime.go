@@ -0,0 +1,90 @@
+package telemetry
+
+import (
+	"sort"
+	"time"
+)
+
+// SpanSelfTime is a span with its self time
+type SpanSelfTime struct {
+	Span     *SpanData
+	SelfTime time.Duration
+}
+
+// SelfTime returns the self time (exclusive duration) of the span, the time not covered by
+// any of its children. The time covered by overlapping or concurrent children is counted once,
+// and the part of the children outside of the span is ignored.
+func SelfTime(span *SpanData, children []*SpanData) time.Duration {
+	start, end := span.Span.StartTimestamp().AsTime(), span.Span.EndTimestamp().AsTime()
+	if !end.After(start) {
+		return 0
+	}
+
+	type interval struct{ start, end time.Time }
+	intervals := make([]interval, 0, len(children))
+	for _, c := range children {
+		cst, cen := c.Span.StartTimestamp().AsTime(), c.Span.EndTimestamp().AsTime()
+		if cst.Before(start) {
+			cst = start
+		}
+		if cen.After(end) {
+			cen = end
+		}
+		if cen.After(cst) {
+			intervals = append(intervals, interval{start: cst, end: cen})
+		}
+	}
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start.Before(intervals[j].start)
+	})
+
+	// merge the overlapping intervals and sum up the covered time
+	var covered time.Duration
+	var cur *interval
+	for i := range intervals {
+		iv := intervals[i]
+		if cur != nil && !iv.start.After(cur.end) {
+			if iv.end.After(cur.end) {
+				cur.end = iv.end
+			}
+			continue
+		}
+		if cur != nil {
+			covered += cur.end.Sub(cur.start)
+		}
+		cur = &iv
+	}
+	if cur != nil {
+		covered += cur.end.Sub(cur.start)
+	}
+
+	return end.Sub(start) - covered
+}
+
+// GetSelfTimesByTraceID returns all spans for a given trace id with their self time,
+// ordered by the self time descending
+func (c *TraceCache) GetSelfTimesByTraceID(traceID string) ([]SpanSelfTime, bool) {
+	spans, ok := c.GetSpansByTraceID(traceID)
+	if !ok {
+		return nil, false
+	}
+
+	children := make(map[string][]*SpanData, len(spans))
+	for _, sd := range spans {
+		parent := sd.Span.ParentSpanID().String()
+		children[parent] = append(children[parent], sd)
+	}
+
+	selftimes := make([]SpanSelfTime, 0, len(spans))
+	for _, sd := range spans {
+		selftimes = append(selftimes, SpanSelfTime{
+			Span:     sd,
+			SelfTime: SelfTime(sd, children[sd.Span.SpanID().String()]),
+		})
+	}
+	sort.SliceStable(selftimes, func(i, j int) bool {
+		return selftimes[i].SelfTime > selftimes[j].SelfTime
+	})
+
+	return selftimes, true
+}
@@ -0,0 +1,50 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSelfTimesByTraceID(t *testing.T) {
+	// span-1-1 [0, 100]
+	//  ├- span-1-2 [10, 40]
+	//  │  └- span-1-5 [15, 25]
+	//  ├- span-1-3 [20, 60] concurrent with span-1-2
+	//  └- span-1-4 [80, 120] finished after the parent
+	store := NewStore(clockwork.NewRealClock())
+	payload := generateTestTraces(
+		testSpan{traceID: 1, spanNo: 1, svc: "api", duration: 100 * time.Millisecond},
+		testSpan{traceID: 1, spanNo: 2, parentNo: 1, svc: "api", offset: 10 * time.Millisecond, duration: 30 * time.Millisecond},
+		testSpan{traceID: 1, spanNo: 3, parentNo: 1, svc: "db", offset: 20 * time.Millisecond, duration: 40 * time.Millisecond},
+		testSpan{traceID: 1, spanNo: 4, parentNo: 1, svc: "worker", offset: 80 * time.Millisecond, duration: 40 * time.Millisecond},
+		testSpan{traceID: 1, spanNo: 5, parentNo: 2, svc: "api", offset: 15 * time.Millisecond, duration: 10 * time.Millisecond},
+	)
+	store.AddSpan(&payload)
+
+	got, ok := store.GetTraceCache().GetSelfTimesByTraceID(testTraceID(1).String())
+	require.True(t, ok)
+
+	names := make([]string, 0, len(got))
+	selftimes := make([]time.Duration, 0, len(got))
+	for _, st := range got {
+		names = append(names, st.Span.Span.Name())
+		selftimes = append(selftimes, st.SelfTime)
+	}
+	assert.Equal(t, []string{"span-1-3", "span-1-4", "span-1-1", "span-1-2", "span-1-5"}, names)
+	assert.Equal(t, []time.Duration{
+		40 * time.Millisecond,
+		40 * time.Millisecond,
+		30 * time.Millisecond,
+		20 * time.Millisecond,
+		10 * time.Millisecond,
+	}, selftimes)
+
+	t.Run("not found", func(t *testing.T) {
+		_, ok := store.GetTraceCache().GetSelfTimesByTraceID(testTraceID(2).String())
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,243 @@
+package telemetry
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// MetricSeries is a series of the data points of a metric identified by the resource, the scope,
+// the metric name and the data point attributes. The latest points are kept in a ring buffer,
+// each of which is a MetricData holding a single data point.
+type MetricSeries struct {
+	mu          sync.RWMutex
+	key         string
+	metricKey   string
+	serviceName string
+	metricName  string
+	metricType  pmetric.MetricType
+	attributes  string
+	points      []*MetricData
+	start       int
+	count       int
+}
+
+func newMetricSeries(key string, md *MetricData, capacity int) *MetricSeries {
+	return &MetricSeries{
+		key:         key,
+		metricKey:   metricKey(md),
+		serviceName: md.GetServiceName(),
+		metricName:  md.GetMetricName(),
+		metricType:  md.Metric.Type(),
+		attributes:  AttributesText(dataPointAttributes(*md.Metric, 0)),
+		points:      make([]*MetricData, max(capacity, 1)),
+	}
+}
+
+func (ms *MetricSeries) GetServiceName() string {
+	return ms.serviceName
+}
+
+func (ms *MetricSeries) GetMetricName() string {
+	return ms.metricName
+}
+
+func (ms *MetricSeries) GetMetricTypeText() string {
+	return ms.metricType.String()
+}
+
+// GetAttributesText returns the data point attributes identifying the series
+func (ms *MetricSeries) GetAttributesText() string {
+	return ms.attributes
+}
+
+// Len returns the number of the points in the series
+func (ms *MetricSeries) Len() int {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	return ms.count
+}
+
+// Latest returns the latest point, or nil if the series has no points
+func (ms *MetricSeries) Latest() *MetricData {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	if ms.count == 0 {
+		return nil
+	}
+	return ms.points[(ms.start+ms.count-1)%len(ms.points)]
+}
+
+// Points returns the points in the received order
+func (ms *MetricSeries) Points() []*MetricData {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+	points := make([]*MetricData, ms.count)
+	for i := range ms.count {
+		points[i] = ms.points[(ms.start+i)%len(ms.points)]
+	}
+	return points
+}
+
+// GetLastValueText returns the value of the latest point. Histograms and summaries are shown
+// with the count and the sum of the observations.
+func (ms *MetricSeries) GetLastValueText() string {
+	md := ms.Latest()
+	if md == nil {
+		return ""
+	}
+	switch md.Metric.Type() {
+	case pmetric.MetricTypeGauge:
+		return numberDataPointText(md.Metric.Gauge().DataPoints().At(0))
+	case pmetric.MetricTypeSum:
+		return numberDataPointText(md.Metric.Sum().DataPoints().At(0))
+	case pmetric.MetricTypeHistogram:
+		dp := md.Metric.Histogram().DataPoints().At(0)
+		return fmt.Sprintf("count=%d sum=%s", dp.Count(), formatFloat(dp.Sum()))
+	case pmetric.MetricTypeExponentialHistogram:
+		dp := md.Metric.ExponentialHistogram().DataPoints().At(0)
+		return fmt.Sprintf("count=%d sum=%s", dp.Count(), formatFloat(dp.Sum()))
+	case pmetric.MetricTypeSummary:
+		dp := md.Metric.Summary().DataPoints().At(0)
+		return fmt.Sprintf("count=%d sum=%s", dp.Count(), formatFloat(dp.Sum()))
+	}
+	return ""
+}
+
+// add appends the point and returns the oldest point overwritten if the buffer is full
+func (ms *MetricSeries) add(md *MetricData) *MetricData {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	md.series = ms
+	if ms.count < len(ms.points) {
+		ms.points[(ms.start+ms.count)%len(ms.points)] = md
+		ms.count++
+		return nil
+	}
+	old := ms.points[ms.start]
+	ms.points[ms.start] = md
+	ms.start = (ms.start + 1) % len(ms.points)
+	return old
+}
+
+// removeOldest removes the oldest point and returns the number of the remaining points
+func (ms *MetricSeries) removeOldest() int {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.count == 0 {
+		return 0
+	}
+	ms.points[ms.start] = nil
+	ms.start = (ms.start + 1) % len(ms.points)
+	ms.count--
+	return ms.count
+}
+
+// seriesKey returns the identity of the series the point belongs to
+func seriesKey(md *MetricData) string {
+	return metricKey(md) + "\x00" + AttributesText(dataPointAttributes(*md.Metric, 0))
+}
+
+// metricKey returns the identity of the metric the point belongs to, which is shared by
+// the series of the metric differing only in the data point attributes
+func metricKey(md *MetricData) string {
+	scope := md.ScopeMetric.Scope()
+	return strings.Join([]string{
+		AttributesText(md.ResourceMetric.Resource().Attributes()),
+		scope.Name(),
+		scope.Version(),
+		md.Metric.Name(),
+	}, "\x00")
+}
+
+// AttributesText returns the attributes as comma separated key=value pairs sorted by the key
+func AttributesText(attrs pcommon.Map) string {
+	pairs := make([]string, 0, attrs.Len())
+	attrs.Range(func(k string, v pcommon.Value) bool {
+		pairs = append(pairs, k+"="+v.AsString())
+		return true
+	})
+	sort.Strings(pairs)
+	return strings.Join(pairs, ", ")
+}
+
+func numberDataPointText(dp pmetric.NumberDataPoint) string {
+	if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		return strconv.FormatInt(dp.IntValue(), 10)
+	}
+	return formatFloat(dp.DoubleValue())
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// dataPointCount returns the number of the data points in the metric
+func dataPointCount(metric pmetric.Metric) int {
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		return metric.Gauge().DataPoints().Len()
+	case pmetric.MetricTypeSum:
+		return metric.Sum().DataPoints().Len()
+	case pmetric.MetricTypeHistogram:
+		return metric.Histogram().DataPoints().Len()
+	case pmetric.MetricTypeExponentialHistogram:
+		return metric.ExponentialHistogram().DataPoints().Len()
+	case pmetric.MetricTypeSummary:
+		return metric.Summary().DataPoints().Len()
+	}
+	return 0
+}
+
+// dataPointAttributes returns the attributes of the data point at the index
+func dataPointAttributes(metric pmetric.Metric, idx int) pcommon.Map {
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		return metric.Gauge().DataPoints().At(idx).Attributes()
+	case pmetric.MetricTypeSum:
+		return metric.Sum().DataPoints().At(idx).Attributes()
+	case pmetric.MetricTypeHistogram:
+		return metric.Histogram().DataPoints().At(idx).Attributes()
+	case pmetric.MetricTypeExponentialHistogram:
+		return metric.ExponentialHistogram().DataPoints().At(idx).Attributes()
+	case pmetric.MetricTypeSummary:
+		return metric.Summary().DataPoints().At(idx).Attributes()
+	}
+	return pcommon.NewMap()
+}
+
+// newPointMetric returns a copy of the metric holding only the data point at the index
+func newPointMetric(metric pmetric.Metric, idx int) pmetric.Metric {
+	m := pmetric.NewMetric()
+	m.SetName(metric.Name())
+	m.SetDescription(metric.Description())
+	m.SetUnit(metric.Unit())
+	metric.Metadata().CopyTo(m.Metadata())
+
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		metric.Gauge().DataPoints().At(idx).CopyTo(m.SetEmptyGauge().DataPoints().AppendEmpty())
+	case pmetric.MetricTypeSum:
+		sum := m.SetEmptySum()
+		sum.SetAggregationTemporality(metric.Sum().AggregationTemporality())
+		sum.SetIsMonotonic(metric.Sum().IsMonotonic())
+		metric.Sum().DataPoints().At(idx).CopyTo(sum.DataPoints().AppendEmpty())
+	case pmetric.MetricTypeHistogram:
+		histogram := m.SetEmptyHistogram()
+		histogram.SetAggregationTemporality(metric.Histogram().AggregationTemporality())
+		metric.Histogram().DataPoints().At(idx).CopyTo(histogram.DataPoints().AppendEmpty())
+	case pmetric.MetricTypeExponentialHistogram:
+		histogram := m.SetEmptyExponentialHistogram()
+		histogram.SetAggregationTemporality(metric.ExponentialHistogram().AggregationTemporality())
+		metric.ExponentialHistogram().DataPoints().At(idx).CopyTo(histogram.DataPoints().AppendEmpty())
+	case pmetric.MetricTypeSummary:
+		metric.Summary().DataPoints().At(idx).CopyTo(m.SetEmptySummary().DataPoints().AppendEmpty())
+	}
+
+	return m
+}
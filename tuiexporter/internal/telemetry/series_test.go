@@ -0,0 +1,171 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/test"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestMetricSeriesRingBuffer(t *testing.T) {
+	ms := &MetricSeries{points: make([]*MetricData, 3)}
+	points := make([]*MetricData, 5)
+	for i := range points {
+		points[i] = &MetricData{}
+	}
+
+	assert.Nil(t, ms.Latest())
+	assert.Nil(t, ms.add(points[0]))
+	assert.Nil(t, ms.add(points[1]))
+	assert.Nil(t, ms.add(points[2]))
+	assert.Equal(t, points[0], ms.add(points[3]))
+	assert.Equal(t, points[1], ms.add(points[4]))
+
+	assert.Equal(t, 3, ms.Len())
+	assert.Equal(t, points[2:], ms.Points())
+	assert.Equal(t, points[4], ms.Latest())
+	assert.Equal(t, ms, points[4].series)
+
+	assert.Equal(t, 2, ms.removeOldest())
+	assert.Equal(t, points[3:], ms.Points())
+	assert.Equal(t, 1, ms.removeOldest())
+	assert.Equal(t, 0, ms.removeOldest())
+	assert.Nil(t, ms.Latest())
+	assert.Equal(t, 0, ms.removeOldest())
+}
+
+func TestSeriesKey(t *testing.T) {
+	newPoint := func(fn func(pmetric.Metrics)) *MetricData {
+		payload, _ := test.GenerateOTLPGaugeMetricsPayload(t, 1, []int{1}, [][]int{{1}})
+		if fn != nil {
+			fn(payload)
+		}
+		rm := payload.ResourceMetrics().At(0)
+		sm := rm.ScopeMetrics().At(0)
+		metric := sm.Metrics().At(0)
+		return &MetricData{Metric: &metric, ResourceMetric: &rm, ScopeMetric: &sm}
+	}
+	base := seriesKey(newPoint(nil))
+
+	tests := []struct {
+		name     string
+		fn       func(pmetric.Metrics)
+		wantSame bool
+	}{
+		{
+			name: "same identity in another batch",
+			fn: func(m pmetric.Metrics) {
+				m.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0).SetDoubleValue(10)
+			},
+			wantSame: true,
+		},
+		{
+			name: "different resource",
+			fn: func(m pmetric.Metrics) {
+				m.ResourceMetrics().At(0).Resource().Attributes().PutStr("service.name", "other")
+			},
+		},
+		{
+			name: "different scope",
+			fn: func(m pmetric.Metrics) {
+				m.ResourceMetrics().At(0).ScopeMetrics().At(0).Scope().SetName("other")
+			},
+		},
+		{
+			name: "different name",
+			fn: func(m pmetric.Metrics) {
+				m.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).SetName("other")
+			},
+		},
+		{
+			name: "different data point attributes",
+			fn: func(m pmetric.Metrics) {
+				m.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0).Attributes().PutStr("k", "v")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantSame, seriesKey(newPoint(tt.fn)) == base)
+		})
+	}
+}
+
+func TestMetricSeriesGetLastValueText(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(m pmetric.Metric)
+		want string
+	}{
+		{
+			name: "double gauge",
+			fn: func(m pmetric.Metric) {
+				m.SetEmptyGauge().DataPoints().AppendEmpty().SetDoubleValue(1.5)
+			},
+			want: "1.5",
+		},
+		{
+			name: "int sum",
+			fn: func(m pmetric.Metric) {
+				m.SetEmptySum().DataPoints().AppendEmpty().SetIntValue(42)
+			},
+			want: "42",
+		},
+		{
+			name: "histogram",
+			fn: func(m pmetric.Metric) {
+				dp := m.SetEmptyHistogram().DataPoints().AppendEmpty()
+				dp.SetCount(3)
+				dp.SetSum(4.5)
+			},
+			want: "count=3 sum=4.5",
+		},
+		{
+			name: "summary",
+			fn: func(m pmetric.Metric) {
+				dp := m.SetEmptySummary().DataPoints().AppendEmpty()
+				dp.SetCount(2)
+				dp.SetSum(10)
+			},
+			want: "count=2 sum=10",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := pmetric.NewMetric()
+			tt.fn(m)
+			ms := &MetricSeries{points: make([]*MetricData, 1)}
+			ms.add(&MetricData{Metric: &m})
+
+			assert.Equal(t, tt.want, ms.GetLastValueText())
+		})
+	}
+}
+
+func TestNewPointMetric(t *testing.T) {
+	m := pmetric.NewMetric()
+	m.SetName("requests")
+	m.SetUnit("1")
+	sum := m.SetEmptySum()
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	sum.SetIsMonotonic(true)
+	for i := range 3 {
+		dp := sum.DataPoints().AppendEmpty()
+		dp.SetIntValue(int64(i))
+		dp.Attributes().PutInt("idx", int64(i))
+	}
+
+	got := newPointMetric(m, 1)
+
+	assert.Equal(t, 3, dataPointCount(m))
+	assert.Equal(t, "requests", got.Name())
+	assert.Equal(t, "1", got.Unit())
+	assert.Equal(t, pmetric.AggregationTemporalityDelta, got.Sum().AggregationTemporality())
+	assert.True(t, got.Sum().IsMonotonic())
+	assert.Equal(t, 1, got.Sum().DataPoints().Len())
+	assert.Equal(t, int64(1), got.Sum().DataPoints().At(0).IntValue())
+	assert.Equal(t, "idx=1", AttributesText(dataPointAttributes(got, 0)))
+}
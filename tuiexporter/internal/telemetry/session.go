@@ -0,0 +1,501 @@
+package telemetry
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+const (
+	SESSION_FORMAT_JSON  SessionFormat = "json"
+	SESSION_FORMAT_PROTO SessionFormat = "proto"
+)
+
+// SessionFormat is the encoding of the session file
+type SessionFormat string
+
+// SessionFormatFromPath returns the session format decided by the file extension.
+// The OTLP protobuf encoding is used for ".pb" and ".binpb", and OTLP JSON for others.
+func SessionFormatFromPath(path string) SessionFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".pb", ".binpb":
+		return SESSION_FORMAT_PROTO
+	}
+	return SESSION_FORMAT_JSON
+}
+
+type sessionSignal byte
+
+const (
+	sessionSignalTraces sessionSignal = iota + 1
+	sessionSignalMetrics
+	sessionSignalLogs
+)
+
+// sessionRecord is a payload of a single signal received at the same time
+type sessionRecord struct {
+	signal     sessionSignal
+	receivedAt time.Time
+	traces     ptrace.Traces
+	metrics    pmetric.Metrics
+	logs       plog.Logs
+}
+
+// sessionJSONRecord is a line of the session file in JSON format
+type sessionJSONRecord struct {
+	ReceivedAt time.Time       `json:"receivedAt"`
+	Traces     json.RawMessage `json:"traces,omitempty"`
+	Metrics    json.RawMessage `json:"metrics,omitempty"`
+	Logs       json.RawMessage `json:"logs,omitempty"`
+}
+
+// sessionProtoHeaderSize is the size of the header of a record in protobuf format
+// which consists of the signal (1 byte), the received time in unix nano (8 bytes)
+// and the payload length (4 bytes)
+const sessionProtoHeaderSize = 13
+
+// SaveSession writes all spans, metrics and logs in the store to the file with their
+// received time. The file is replaced atomically.
+func (s *Store) SaveSession(path string) error {
+	s.mut.Lock()
+	records := s.snapshotLocked()
+	s.mut.Unlock()
+
+	dir, name := filepath.Split(filepath.Clean(path))
+	if dir == "" {
+		dir = "."
+	}
+	f, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// no-op after the rename succeeds
+		_ = os.Remove(f.Name())
+	}()
+
+	w := bufio.NewWriter(f)
+	if err := writeSessionRecords(w, records, SessionFormatFromPath(path)); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// LoadSession restores spans, metrics and logs from the file written by SaveSession
+// keeping their original received time. It does nothing if the file does not exist.
+// Note that the TTL applies to the restored data by the original received time, so the
+// data older than the TTL is deleted by the next sweep.
+func (s *Store) LoadSession(path string) error {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	records, err := readSessionRecords(bufio.NewReader(f), SessionFormatFromPath(path))
+	if err != nil {
+		return fmt.Errorf("failed to read session file %s: %w", path, err)
+	}
+
+	var changed signals
+	s.mut.Lock()
+	for _, r := range records {
+		receivedAt := func() time.Time {
+			return r.receivedAt
+		}
+		switch r.signal {
+		case sessionSignalTraces:
+			changed |= signalSpans | s.addSpanLocked(&r.traces, receivedAt)
+		case sessionSignalMetrics:
+			changed |= signalMetrics | s.addMetricLocked(&r.metrics, receivedAt)
+		case sessionSignalLogs:
+			changed |= signalLogs | s.addLogLocked(&r.logs, receivedAt)
+		}
+	}
+	s.markUpdatedLocked()
+	s.mut.Unlock()
+
+	s.notifySignals(changed)
+
+	return nil
+}
+
+// StartSessionSaver starts a background goroutine which saves the session to the file
+// at the given interval when the store has been updated. The returned function stops
+// the goroutine after saving the session for the last time.
+func (s *Store) StartSessionSaver(path string, interval time.Duration) (stop func()) {
+	ticker := s.clockwork.NewTicker(interval)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	save := func() {
+		if err := s.SaveSession(path); err != nil {
+			log.Printf("failed to save session: %v", err)
+		}
+	}
+
+	// the version counts every update even when the clock does not advance
+	getVersion := func() uint64 {
+		s.mut.Lock()
+		defer s.mut.Unlock()
+		return s.version
+	}
+	// taken before the goroutine starts not to miss the updates in the meantime
+	savedVersion := getVersion()
+
+	go func() {
+		defer close(finished)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				save()
+				return
+			case <-ticker.Chan():
+				version := getVersion()
+				if version == savedVersion {
+					continue
+				}
+				save()
+				savedVersion = version
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-finished
+		})
+	}
+}
+
+// snapshotLocked copies the data in the store into records ordered by received time.
+// The caller must hold s.mut.
+func (s *Store) snapshotLocked() []*sessionRecord {
+	records := []*sessionRecord{}
+
+	spans := []*SpanData{}
+	for _, ss := range s.svcspans {
+		if svcSpans, ok := s.tracecache.GetSpansByTraceIDAndSvc(ss.Span.TraceID().String(), ss.GetServiceName()); ok {
+			spans = append(spans, svcSpans...)
+		}
+	}
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].ReceivedAt.Before(spans[j].ReceivedAt)
+	})
+	// The spans from the same resource in a single payload are kept together
+	for start := 0; start < len(spans); {
+		end := start + 1
+		for end < len(spans) && spans[end].ResourceSpan == spans[start].ResourceSpan {
+			end++
+		}
+		records = append(records, &sessionRecord{
+			signal:     sessionSignalTraces,
+			receivedAt: spans[start].ReceivedAt,
+			traces:     buildTraces(spans[start:end]),
+		})
+		start = end
+	}
+
+	for start := 0; start < len(s.metrics); {
+		end := start + 1
+		for end < len(s.metrics) && s.metrics[end].ResourceMetric == s.metrics[start].ResourceMetric {
+			end++
+		}
+		records = append(records, &sessionRecord{
+			signal:     sessionSignalMetrics,
+			receivedAt: s.metrics[start].ReceivedAt,
+			metrics:    buildMetrics(s.metrics[start:end]),
+		})
+		start = end
+	}
+
+	for start := 0; start < len(s.logs); {
+		end := start + 1
+		for end < len(s.logs) && s.logs[end].ResourceLog == s.logs[start].ResourceLog {
+			end++
+		}
+		records = append(records, &sessionRecord{
+			signal:     sessionSignalLogs,
+			receivedAt: s.logs[start].ReceivedAt,
+			logs:       buildLogs(s.logs[start:end]),
+		})
+		start = end
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].receivedAt.Before(records[j].receivedAt)
+	})
+
+	return records
+}
+
+func buildTraces(spans []*SpanData) ptrace.Traces {
+	traces := ptrace.NewTraces()
+	rss := map[*ptrace.ResourceSpans]ptrace.ResourceSpans{}
+	sss := map[*ptrace.ScopeSpans]ptrace.ScopeSpans{}
+
+	for _, sd := range spans {
+		rs, ok := rss[sd.ResourceSpan]
+		if !ok {
+			rs = traces.ResourceSpans().AppendEmpty()
+			sd.ResourceSpan.Resource().CopyTo(rs.Resource())
+			rs.SetSchemaUrl(sd.ResourceSpan.SchemaUrl())
+			rss[sd.ResourceSpan] = rs
+		}
+		ss, ok := sss[sd.ScopeSpans]
+		if !ok {
+			ss = rs.ScopeSpans().AppendEmpty()
+			sd.ScopeSpans.Scope().CopyTo(ss.Scope())
+			ss.SetSchemaUrl(sd.ScopeSpans.SchemaUrl())
+			sss[sd.ScopeSpans] = ss
+		}
+		sd.Span.CopyTo(ss.Spans().AppendEmpty())
+	}
+
+	return traces
+}
+
+func buildMetrics(metrics []*MetricData) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rms := map[*pmetric.ResourceMetrics]pmetric.ResourceMetrics{}
+	sms := map[*pmetric.ScopeMetrics]pmetric.ScopeMetrics{}
+
+	for _, m := range metrics {
+		rm, ok := rms[m.ResourceMetric]
+		if !ok {
+			rm = md.ResourceMetrics().AppendEmpty()
+			m.ResourceMetric.Resource().CopyTo(rm.Resource())
+			rm.SetSchemaUrl(m.ResourceMetric.SchemaUrl())
+			rms[m.ResourceMetric] = rm
+		}
+		sm, ok := sms[m.ScopeMetric]
+		if !ok {
+			sm = rm.ScopeMetrics().AppendEmpty()
+			m.ScopeMetric.Scope().CopyTo(sm.Scope())
+			sm.SetSchemaUrl(m.ScopeMetric.SchemaUrl())
+			sms[m.ScopeMetric] = sm
+		}
+		m.Metric.CopyTo(sm.Metrics().AppendEmpty())
+	}
+
+	return md
+}
+
+func buildLogs(logs []*LogData) plog.Logs {
+	ld := plog.NewLogs()
+	rls := map[*plog.ResourceLogs]plog.ResourceLogs{}
+	sls := map[*plog.ScopeLogs]plog.ScopeLogs{}
+
+	for _, l := range logs {
+		rl, ok := rls[l.ResourceLog]
+		if !ok {
+			rl = ld.ResourceLogs().AppendEmpty()
+			l.ResourceLog.Resource().CopyTo(rl.Resource())
+			rl.SetSchemaUrl(l.ResourceLog.SchemaUrl())
+			rls[l.ResourceLog] = rl
+		}
+		sl, ok := sls[l.ScopeLog]
+		if !ok {
+			sl = rl.ScopeLogs().AppendEmpty()
+			l.ScopeLog.Scope().CopyTo(sl.Scope())
+			sl.SetSchemaUrl(l.ScopeLog.SchemaUrl())
+			sls[l.ScopeLog] = sl
+		}
+		l.Log.CopyTo(sl.LogRecords().AppendEmpty())
+	}
+
+	return ld
+}
+
+func writeSessionRecords(w io.Writer, records []*sessionRecord, format SessionFormat) error {
+	for _, r := range records {
+		var err error
+		if format == SESSION_FORMAT_PROTO {
+			err = writeSessionProtoRecord(w, r)
+		} else {
+			err = writeSessionJSONRecord(w, r)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeSessionJSONRecord(w io.Writer, r *sessionRecord) error {
+	var (
+		jr  = sessionJSONRecord{ReceivedAt: r.receivedAt}
+		err error
+	)
+	switch r.signal {
+	case sessionSignalTraces:
+		jr.Traces, err = (&ptrace.JSONMarshaler{}).MarshalTraces(r.traces)
+	case sessionSignalMetrics:
+		jr.Metrics, err = (&pmetric.JSONMarshaler{}).MarshalMetrics(r.metrics)
+	case sessionSignalLogs:
+		jr.Logs, err = (&plog.JSONMarshaler{}).MarshalLogs(r.logs)
+	}
+	if err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(jr)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(line, '\n'))
+	return err
+}
+
+func writeSessionProtoRecord(w io.Writer, r *sessionRecord) error {
+	var (
+		payload []byte
+		err     error
+	)
+	switch r.signal {
+	case sessionSignalTraces:
+		payload, err = (&ptrace.ProtoMarshaler{}).MarshalTraces(r.traces)
+	case sessionSignalMetrics:
+		payload, err = (&pmetric.ProtoMarshaler{}).MarshalMetrics(r.metrics)
+	case sessionSignalLogs:
+		payload, err = (&plog.ProtoMarshaler{}).MarshalLogs(r.logs)
+	}
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, sessionProtoHeaderSize)
+	header[0] = byte(r.signal)
+	binary.BigEndian.PutUint64(header[1:9], uint64(r.receivedAt.UnixNano())) // #nosec G115
+	binary.BigEndian.PutUint32(header[9:], uint32(len(payload)))             // #nosec G115
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err = w.Write(payload)
+	return err
+}
+
+func readSessionRecords(r *bufio.Reader, format SessionFormat) ([]*sessionRecord, error) {
+	records := []*sessionRecord{}
+	for {
+		var (
+			record *sessionRecord
+			err    error
+		)
+		if format == SESSION_FORMAT_PROTO {
+			record, err = readSessionProtoRecord(r)
+		} else {
+			record, err = readSessionJSONRecord(r)
+		}
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if record != nil {
+			records = append(records, record)
+		}
+	}
+}
+
+// readSessionJSONRecord reads a line of the session file. It returns nil record for empty lines.
+func readSessionJSONRecord(r *bufio.Reader) (*sessionRecord, error) {
+	line, err := r.ReadBytes('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && len(line) > 0) {
+		return nil, err
+	}
+	if len(strings.TrimSpace(string(line))) == 0 {
+		return nil, nil
+	}
+
+	var jr sessionJSONRecord
+	if err := json.Unmarshal(line, &jr); err != nil {
+		return nil, err
+	}
+
+	record := &sessionRecord{receivedAt: jr.ReceivedAt}
+	switch {
+	case len(jr.Traces) > 0:
+		record.signal = sessionSignalTraces
+		record.traces, err = (&ptrace.JSONUnmarshaler{}).UnmarshalTraces(jr.Traces)
+	case len(jr.Metrics) > 0:
+		record.signal = sessionSignalMetrics
+		record.metrics, err = (&pmetric.JSONUnmarshaler{}).UnmarshalMetrics(jr.Metrics)
+	case len(jr.Logs) > 0:
+		record.signal = sessionSignalLogs
+		record.logs, err = (&plog.JSONUnmarshaler{}).UnmarshalLogs(jr.Logs)
+	default:
+		return nil, errors.New("no telemetry in the session record")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+func readSessionProtoRecord(r *bufio.Reader) (*sessionRecord, error) {
+	header := make([]byte, sessionProtoHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, errors.New("truncated session record")
+		}
+		return nil, err
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(header[9:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, errors.New("truncated session record")
+	}
+
+	var err error
+	record := &sessionRecord{
+		signal:     sessionSignal(header[0]),
+		receivedAt: time.Unix(0, int64(binary.BigEndian.Uint64(header[1:9]))), // #nosec G115
+	}
+	switch record.signal {
+	case sessionSignalTraces:
+		record.traces, err = (&ptrace.ProtoUnmarshaler{}).UnmarshalTraces(payload)
+	case sessionSignalMetrics:
+		record.metrics, err = (&pmetric.ProtoUnmarshaler{}).UnmarshalMetrics(payload)
+	case sessionSignalLogs:
+		record.logs, err = (&plog.ProtoUnmarshaler{}).UnmarshalLogs(payload)
+	default:
+		return nil, fmt.Errorf("unknown signal in the session record: %d", header[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
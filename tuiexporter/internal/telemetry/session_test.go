@@ -0,0 +1,117 @@
+package telemetry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/test"
+)
+
+func TestSessionFormatFromPath(t *testing.T) {
+	assert.Equal(t, SESSION_FORMAT_PROTO, SessionFormatFromPath("/tmp/session.pb"))
+	assert.Equal(t, SESSION_FORMAT_PROTO, SessionFormatFromPath("/tmp/session.BINPB"))
+	assert.Equal(t, SESSION_FORMAT_JSON, SessionFormatFromPath("/tmp/session.json"))
+	assert.Equal(t, SESSION_FORMAT_JSON, SessionFormatFromPath("/tmp/session"))
+}
+
+func TestStoreSaveAndLoadSession(t *testing.T) {
+	for _, name := range []string{"session.jsonl", "session.pb"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+
+			clock := clockwork.NewFakeClockAt(time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC))
+			store := NewStore(clock)
+			tp, _ := test.GenerateOTLPTracesPayload(t, 1, 2, []int{2, 1}, [][]int{{2, 1}, {1}})
+			mp, _ := test.GenerateOTLPGaugeMetricsPayload(t, 2, []int{2, 1}, [][]int{{2, 1}, {1}})
+			lp, _ := test.GenerateOTLPLogsPayload(t, 1, 2, []int{2, 1}, [][]int{{2, 1}, {1}})
+			store.AddLog(&lp)
+			clock.Advance(time.Second)
+			store.AddSpan(&tp)
+			clock.Advance(time.Second)
+			store.AddMetric(&mp)
+
+			require.NoError(t, store.SaveSession(path))
+
+			// restore the session a day later
+			restored := NewStore(clockwork.NewFakeClockAt(clock.Now().Add(24 * time.Hour)))
+			require.NoError(t, restored.LoadSession(path))
+
+			assert.Equal(t, len(store.svcspans), len(restored.svcspans))
+			assert.Equal(t, len(store.svcspansFiltered), len(restored.svcspansFiltered))
+			for i, ss := range store.svcspans {
+				assert.Equal(t, ss.Span.SpanID(), restored.svcspans[i].Span.SpanID())
+				assert.Equal(t, ss.GetServiceName(), restored.svcspans[i].GetServiceName())
+				assert.True(t, ss.ReceivedAt.Equal(restored.svcspans[i].ReceivedAt))
+			}
+			spans, ok := restored.tracecache.GetSpansByTraceIDAndSvc(store.svcspans[0].Span.TraceID().String(), "test-service-1")
+			assert.True(t, ok)
+			assert.Equal(t, 3, len(spans))
+
+			assert.Equal(t, len(store.metrics), len(restored.metrics))
+			for i, m := range store.metrics {
+				assert.Equal(t, m.Metric.Name(), restored.metrics[i].Metric.Name())
+				assert.Equal(t, m.GetServiceName(), restored.metrics[i].GetServiceName())
+				assert.True(t, m.ReceivedAt.Equal(restored.metrics[i].ReceivedAt))
+			}
+			ms, ok := restored.metriccache.GetMetricsBySvcAndMetricName("test-service-1", "metric 0-0")
+			assert.True(t, ok)
+			assert.Equal(t, 2, len(ms))
+			assert.Equal(t, len(store.series), len(restored.series))
+
+			assert.Equal(t, len(store.logs), len(restored.logs))
+			for i, l := range store.logs {
+				assert.Equal(t, l.Log.Body().AsString(), restored.logs[i].Log.Body().AsString())
+				assert.True(t, l.ReceivedAt.Equal(restored.logs[i].ReceivedAt))
+			}
+			logs, ok := restored.logcache.GetLogsByTraceID(store.logs[0].GetTraceID())
+			assert.True(t, ok)
+			assert.Equal(t, 8, len(logs))
+		})
+	}
+}
+
+func TestStoreLoadSessionNotExist(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock())
+	assert.NoError(t, store.LoadSession(filepath.Join(t.TempDir(), "not-exist.json")))
+	assert.Equal(t, 0, len(store.svcspans))
+}
+
+func TestStoreLoadSessionBroken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	require.NoError(t, os.WriteFile(path, []byte("{broken\n"), 0600))
+
+	store := NewStore(clockwork.NewRealClock())
+	assert.Error(t, store.LoadSession(path))
+}
+
+func TestStoreStartSessionSaver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	clock := clockwork.NewFakeClock()
+	store := NewStore(clock)
+	stop := store.StartSessionSaver(path, 10*time.Second)
+
+	lp, _ := test.GenerateOTLPLogsPayload(t, 1, 1, []int{1}, [][]int{{1}})
+	store.AddLog(&lp)
+
+	clock.BlockUntil(1)
+	clock.Advance(10 * time.Second)
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	// the session is saved on stop as well
+	clock.Advance(time.Second)
+	store.AddLog(&lp)
+	stop()
+
+	restored := NewStore(clockwork.NewRealClock())
+	require.NoError(t, restored.LoadSession(path))
+	assert.Equal(t, 4, len(restored.logs))
+}
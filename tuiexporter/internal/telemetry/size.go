@@ -0,0 +1,125 @@
+package telemetry
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+const (
+	// itemOverhead is the approximate size of the wrapper struct and cache entries for a single item
+	itemOverhead = 256
+	// valueOverhead is the approximate size of a single pcommon.Value
+	valueOverhead = 16
+	// dataPointOverhead is the approximate size of a single metric datapoint without attributes
+	dataPointOverhead = 64
+)
+
+// The size estimation below is not accurate at all. It only aims to give a
+// stable order of magnitude of the retained pdata so that the store can evict
+// old items when the memory budget is exceeded.
+
+func estimateSpanSize(span ptrace.Span) int64 {
+	size := int64(itemOverhead)
+	size += int64(len(span.Name()) + len(span.TraceState().AsRaw()) + len(span.Status().Message()))
+	size += estimateMapSize(span.Attributes())
+	for i := 0; i < span.Events().Len(); i++ {
+		event := span.Events().At(i)
+		size += valueOverhead + int64(len(event.Name())) + estimateMapSize(event.Attributes())
+	}
+	for i := 0; i < span.Links().Len(); i++ {
+		link := span.Links().At(i)
+		size += valueOverhead + int64(len(link.TraceState().AsRaw())) + estimateMapSize(link.Attributes())
+	}
+	return size
+}
+
+func estimateMetricSize(metric pmetric.Metric) int64 {
+	size := int64(itemOverhead)
+	size += int64(len(metric.Name()) + len(metric.Description()) + len(metric.Unit()))
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		size += estimateNumberDataPointsSize(metric.Gauge().DataPoints())
+	case pmetric.MetricTypeSum:
+		size += estimateNumberDataPointsSize(metric.Sum().DataPoints())
+	case pmetric.MetricTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			size += dataPointOverhead + estimateMapSize(dp.Attributes())
+			size += int64(dp.BucketCounts().Len()+dp.ExplicitBounds().Len()) * 8
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			size += dataPointOverhead + estimateMapSize(dp.Attributes())
+			size += int64(dp.Positive().BucketCounts().Len()+dp.Negative().BucketCounts().Len()) * 8
+		}
+	case pmetric.MetricTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			size += dataPointOverhead + estimateMapSize(dp.Attributes())
+			size += int64(dp.QuantileValues().Len()) * 16
+		}
+	}
+	return size
+}
+
+func estimateNumberDataPointsSize(dps pmetric.NumberDataPointSlice) int64 {
+	var size int64
+	for i := 0; i < dps.Len(); i++ {
+		size += dataPointOverhead + estimateMapSize(dps.At(i).Attributes())
+	}
+	return size
+}
+
+func estimateLogSize(lr plog.LogRecord) int64 {
+	size := int64(itemOverhead)
+	size += int64(len(lr.SeverityText()) + len(lr.EventName()))
+	size += estimateValueSize(lr.Body())
+	size += estimateMapSize(lr.Attributes())
+	return size
+}
+
+func estimateResourceSize(resource pcommon.Resource, scope pcommon.InstrumentationScope) int64 {
+	size := estimateMapSize(resource.Attributes())
+	size += int64(len(scope.Name())+len(scope.Version())) + estimateMapSize(scope.Attributes())
+	return size
+}
+
+func estimateMapSize(m pcommon.Map) int64 {
+	var size int64
+	m.Range(func(k string, v pcommon.Value) bool {
+		size += int64(len(k)) + estimateValueSize(v)
+		return true
+	})
+	return size
+}
+
+func estimateValueSize(v pcommon.Value) int64 {
+	size := int64(valueOverhead)
+	switch v.Type() {
+	case pcommon.ValueTypeStr:
+		size += int64(len(v.Str()))
+	case pcommon.ValueTypeBytes:
+		size += int64(v.Bytes().Len())
+	case pcommon.ValueTypeMap:
+		size += estimateMapSize(v.Map())
+	case pcommon.ValueTypeSlice:
+		for i := 0; i < v.Slice().Len(); i++ {
+			size += estimateValueSize(v.Slice().At(i))
+		}
+	}
+	return size
+}
+
+// sharedSize returns the size of the shared resource divided by the number of items sharing it
+func sharedSize(size int64, count int) int64 {
+	if count <= 0 {
+		return 0
+	}
+	return size / int64(count)
+}
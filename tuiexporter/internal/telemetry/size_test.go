@@ -0,0 +1,39 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestEstimateValueSize(t *testing.T) {
+	str := pcommon.NewValueStr("12345")
+	assert.Equal(t, int64(valueOverhead+5), estimateValueSize(str))
+
+	m := pcommon.NewValueMap()
+	m.Map().PutStr("key", "12345")
+	assert.Equal(t, int64(valueOverhead+3+valueOverhead+5), estimateValueSize(m))
+
+	sl := pcommon.NewValueSlice()
+	sl.Slice().AppendEmpty().SetStr("12345")
+	sl.Slice().AppendEmpty().SetInt(1)
+	assert.Equal(t, int64(valueOverhead+valueOverhead+5+valueOverhead), estimateValueSize(sl))
+}
+
+func TestEstimateSpanSize(t *testing.T) {
+	span := ptrace.NewSpan()
+	base := estimateSpanSize(span)
+	assert.Equal(t, int64(itemOverhead), base)
+
+	span.SetName("span")
+	span.Attributes().PutStr("key", "value")
+	span.Events().AppendEmpty().SetName("event")
+	assert.Equal(t, base+4+(3+valueOverhead+5)+(valueOverhead+5), estimateSpanSize(span))
+}
+
+func TestSharedSize(t *testing.T) {
+	assert.Equal(t, int64(0), sharedSize(100, 0))
+	assert.Equal(t, int64(25), sharedSize(100, 4))
+}
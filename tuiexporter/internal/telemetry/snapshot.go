@@ -0,0 +1,73 @@
+package telemetry
+
+import "slices"
+
+// Snapshot is an immutable view of items in the store at a version.
+// It is safe to read from any goroutine while the store is updated.
+type Snapshot[T any] struct {
+	items   []*T
+	version uint64
+}
+
+// NewSnapshot creates a snapshot holding a copy of the items
+func NewSnapshot[T any](items []*T, version uint64) *Snapshot[T] {
+	return &Snapshot[T]{
+		items:   slices.Clone(items),
+		version: version,
+	}
+}
+
+// Len returns the number of items
+func (s *Snapshot[T]) Len() int {
+	return len(s.items)
+}
+
+// At returns the item at the given index, or nil if the index is out of range
+func (s *Snapshot[T]) At(idx int) *T {
+	if idx < 0 || idx >= len(s.items) {
+		return nil
+	}
+	return s.items[idx]
+}
+
+// Version returns the version of the store when the snapshot was taken
+func (s *Snapshot[T]) Version() uint64 {
+	return s.version
+}
+
+// SnapshotSvcSpans returns a snapshot of the filtered service spans
+func (s *Store) SnapshotSvcSpans() *Snapshot[SpanData] {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return snapshotOf(&s.svcspansSnapshot, s.svcspansFiltered, s.version)
+}
+
+// SnapshotMetrics returns a snapshot of the filtered metric series
+func (s *Store) SnapshotMetrics() *Snapshot[MetricSeries] {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return snapshotOf(&s.metricsSnapshot, s.metricsFiltered, s.version)
+}
+
+// SnapshotLogs returns a snapshot of the filtered logs
+func (s *Store) SnapshotLogs() *Snapshot[LogData] {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return snapshotOf(&s.logsSnapshot, s.logsFiltered, s.version)
+}
+
+// snapshotOf returns the cached snapshot, or takes a new one if the store has been updated
+// since the cached one was taken. The caller must hold s.mut.
+func snapshotOf[T any](cached **Snapshot[T], items []*T, version uint64) *Snapshot[T] {
+	if *cached == nil || (*cached).version != version {
+		*cached = NewSnapshot(items, version)
+	}
+	return *cached
+}
+
+// markUpdatedLocked records an update of the store, which invalidates the snapshots.
+// The caller must hold s.mut.
+func (s *Store) markUpdatedLocked() {
+	s.version++
+	s.updatedAt = s.clockwork.Now()
+}
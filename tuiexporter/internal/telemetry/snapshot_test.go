@@ -0,0 +1,209 @@
+package telemetry
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/test"
+)
+
+func TestSnapshot(t *testing.T) {
+	items := []*int{ptr(1), ptr(2)}
+	snapshot := NewSnapshot(items, 3)
+
+	// the snapshot is not affected by the changes of the original slice
+	items[0] = ptr(10)
+
+	assert.Equal(t, 2, snapshot.Len())
+	assert.Equal(t, 1, *snapshot.At(0))
+	assert.Equal(t, 2, *snapshot.At(1))
+	assert.Nil(t, snapshot.At(-1))
+	assert.Nil(t, snapshot.At(2))
+	assert.Equal(t, uint64(3), snapshot.Version())
+
+	empty := NewSnapshot[int](nil, 0)
+	assert.Equal(t, 0, empty.Len())
+	assert.Nil(t, empty.At(0))
+}
+
+func TestStoreSnapshots(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock())
+	tp, _ := test.GenerateOTLPTracesPayload(t, 1, 2, []int{1, 1}, [][]int{{1}, {1}})
+	mp, _ := test.GenerateOTLPGaugeMetricsPayload(t, 1, []int{1}, [][]int{{1}})
+	lp, gl := test.GenerateOTLPLogsPayload(t, 1, 1, []int{1}, [][]int{{2}})
+	store.AddSpan(&tp)
+	store.AddMetric(&mp)
+	store.AddLog(&lp)
+
+	spans := store.SnapshotSvcSpans()
+	metrics := store.SnapshotMetrics()
+	logs := store.SnapshotLogs()
+	assert.Equal(t, 2, spans.Len())
+	assert.Equal(t, 1, metrics.Len())
+	assert.Equal(t, len(gl.Logs), logs.Len())
+
+	t.Run("cached until the store is updated", func(t *testing.T) {
+		assert.Same(t, spans, store.SnapshotSvcSpans())
+		assert.Same(t, metrics, store.SnapshotMetrics())
+		assert.Same(t, logs, store.SnapshotLogs())
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		require.NoError(t, store.ApplyFilterTraces("test-service-2", SORT_TYPE_NONE))
+
+		got := store.SnapshotSvcSpans()
+		assert.Greater(t, got.Version(), spans.Version())
+		assert.Equal(t, 1, got.Len())
+		assert.Equal(t, "test-service-2", got.At(0).GetServiceName())
+		// the previous snapshot is kept as is
+		assert.Equal(t, 2, spans.Len())
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		store.Flush()
+
+		assert.Equal(t, 0, store.SnapshotSvcSpans().Len())
+		assert.Equal(t, 0, store.SnapshotMetrics().Len())
+		assert.Equal(t, 0, store.SnapshotLogs().Len())
+		assert.Equal(t, 1, metrics.Len())
+		assert.Equal(t, len(gl.Logs), logs.Len())
+	})
+}
+
+func TestStoreCallbacksWithoutLock(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock())
+
+	// the callbacks read the store, which would deadlock if they were called with the lock held
+	var spans, metrics, logs int
+	store.SetOnSpanAdded(func() { spans = store.SnapshotSvcSpans().Len() })
+	store.SetOnMetricAdded(func() { metrics = store.SnapshotMetrics().Len() })
+	store.SetOnLogAdded(func() { logs = store.SnapshotLogs().Len() })
+
+	tp, _ := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{1}})
+	mp, gm := test.GenerateOTLPGaugeMetricsPayload(t, 1, []int{1}, [][]int{{2}})
+	lp, gl := test.GenerateOTLPLogsPayload(t, 1, 1, []int{1}, [][]int{{3}})
+	store.AddSpan(&tp)
+	store.AddMetric(&mp)
+	store.AddLog(&lp)
+
+	assert.Equal(t, 1, spans)
+	// a series per data point
+	assert.Equal(t, gm.Metrics[0].Gauge().DataPoints().Len(), metrics)
+	assert.Equal(t, len(gl.Logs), logs)
+}
+
+func TestStoreSnapshot_ConcurrentIngest(t *testing.T) {
+	store := NewStore(
+		clockwork.NewRealClock(),
+		WithMaxServiceSpanCount(50),
+		WithMaxMetricCount(50),
+		WithMaxLogCount(50),
+	)
+
+	const (
+		writers         = 4
+		writesPerWorker = 200
+		traceIDSpace    = 200 // traceID is encoded as a single byte by the test generator
+	)
+
+	sortTypes := []SortType{SORT_TYPE_NONE, SORT_TYPE_LATENCY_DESC, SORT_TYPE_LATENCY_ASC}
+
+	var writerWG, readerWG sync.WaitGroup
+	stop := make(chan struct{})
+
+	// Reader goroutine: mimics the tview draw loop rendering all rows of the tables
+	// and checks the snapshots are not changed while they are rendered.
+	readerWG.Add(1)
+	go func() {
+		defer readerWG.Done()
+		var version uint64
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			spans := store.SnapshotSvcSpans()
+			metrics := store.SnapshotMetrics()
+			logs := store.SnapshotLogs()
+			if spans.Version() < version {
+				t.Errorf("version went back from %d to %d", version, spans.Version())
+			}
+			version = spans.Version()
+
+			rendered := make([]string, 0, spans.Len()+metrics.Len()+logs.Len())
+			for i := range spans.Len() {
+				rendered = append(rendered, spans.At(i).GetServiceName()+spans.At(i).GetSpanName())
+			}
+			for i := range metrics.Len() {
+				rendered = append(rendered, metrics.At(i).GetMetricName())
+			}
+			for i := range logs.Len() {
+				rendered = append(rendered, logs.At(i).GetResolvedBody())
+			}
+
+			n := 0
+			for i := range spans.Len() {
+				if rendered[n] != spans.At(i).GetServiceName()+spans.At(i).GetSpanName() {
+					t.Errorf("span snapshot was changed at %d", i)
+				}
+				n++
+			}
+			for i := range metrics.Len() {
+				if rendered[n] != metrics.At(i).GetMetricName() {
+					t.Errorf("metric snapshot was changed at %d", i)
+				}
+				n++
+			}
+			for i := range logs.Len() {
+				if rendered[n] != logs.At(i).GetResolvedBody() {
+					t.Errorf("log snapshot was changed at %d", i)
+				}
+				n++
+			}
+		}
+	}()
+
+	// Filter goroutine: mimics the user typing filter queries while receiving data.
+	readerWG.Add(1)
+	go func() {
+		defer readerWG.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			q := fmt.Sprintf("test-service-%d", i%3)
+			_ = store.ApplyFilterTraces(q, sortTypes[i%len(sortTypes)])
+			store.ApplyFilterMetrics(q)
+			_ = store.ApplyFilterLogs(q)
+			store.UpdatedAt()
+		}
+	}()
+
+	// Writer goroutines: simulate multiple OTLP receivers ingesting signals.
+	for w := 0; w < writers; w++ {
+		writerWG.Add(1)
+		go func(workerID int) {
+			defer writerWG.Done()
+			for i := 0; i < writesPerWorker; i++ {
+				traceID := ((workerID*writesPerWorker + i) % traceIDSpace) + 1
+				tp, _ := test.GenerateOTLPTracesPayload(t, traceID, 2, []int{1, 1}, [][]int{{2}, {1}})
+				mp, _ := test.GenerateOTLPGaugeMetricsPayload(t, 2, []int{1, 1}, [][]int{{1}, {1}})
+				lp, _ := test.GenerateOTLPLogsPayload(t, traceID, 2, []int{1, 1}, [][]int{{1}, {1}})
+				store.AddSpan(&tp)
+				store.AddMetric(&mp)
+				store.AddLog(&lp)
+			}
+		}(w)
+	}
+
+	writerWG.Wait()
+	close(stop)
+	readerWG.Wait()
+}
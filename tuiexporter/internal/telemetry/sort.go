@@ -1,6 +1,10 @@
 package telemetry
 
-import "sort"
+import (
+	"slices"
+	"sort"
+	"time"
+)
 
 const (
 	SORT_TYPE_NONE         SortType = "none"
@@ -30,31 +34,39 @@ func (t SortType) GetHeaderLabel() string {
 }
 
 func sortSvcSpans(svcSpans SvcSpans, sortType SortType) {
+	less := svcSpanLess(sortType)
+	sort.SliceStable(svcSpans, func(i, j int) bool {
+		return less(svcSpans[i], svcSpans[j])
+	})
+}
+
+// insertSvcSpan inserts the service span into the sorted service spans.
+// The span is placed after the spans which are equal in the order.
+func insertSvcSpan(svcSpans SvcSpans, sd *SpanData, sortType SortType) SvcSpans {
+	less := svcSpanLess(sortType)
+	idx := sort.Search(len(svcSpans), func(i int) bool {
+		return less(sd, svcSpans[i])
+	})
+	return slices.Insert(svcSpans, idx, sd)
+}
+
+func svcSpanLess(sortType SortType) func(a, b *SpanData) bool {
 	switch sortType {
-	case SORT_TYPE_NONE:
-		sort.Slice(svcSpans, func(i, j int) bool {
-			// default sort is received_at asc
-			return svcSpans[i].ReceivedAt.Before(svcSpans[j].ReceivedAt)
-		})
 	case SORT_TYPE_LATENCY_DESC:
-		sort.Slice(svcSpans, func(i, j int) bool {
-			istart := svcSpans[i].Span.StartTimestamp().AsTime()
-			iend := svcSpans[i].Span.EndTimestamp().AsTime()
-			iduration := iend.Sub(istart)
-			jstart := svcSpans[j].Span.StartTimestamp().AsTime()
-			jend := svcSpans[j].Span.EndTimestamp().AsTime()
-			jduration := jend.Sub(jstart)
-			return iduration > jduration
-		})
+		return func(a, b *SpanData) bool {
+			return spanDuration(a) > spanDuration(b)
+		}
 	case SORT_TYPE_LATENCY_ASC:
-		sort.Slice(svcSpans, func(i, j int) bool {
-			istart := svcSpans[i].Span.StartTimestamp().AsTime()
-			iend := svcSpans[i].Span.EndTimestamp().AsTime()
-			iduration := iend.Sub(istart)
-			jstart := svcSpans[j].Span.StartTimestamp().AsTime()
-			jend := svcSpans[j].Span.EndTimestamp().AsTime()
-			jduration := jend.Sub(jstart)
-			return iduration < jduration
-		})
+		return func(a, b *SpanData) bool {
+			return spanDuration(a) < spanDuration(b)
+		}
+	}
+	// default sort is received_at asc
+	return func(a, b *SpanData) bool {
+		return a.ReceivedAt.Before(b.ReceivedAt)
 	}
 }
+
+func spanDuration(sd *SpanData) time.Duration {
+	return sd.Span.EndTimestamp().AsTime().Sub(sd.Span.StartTimestamp().AsTime())
+}
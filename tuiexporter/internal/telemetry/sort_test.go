@@ -148,3 +148,55 @@ func TestSortSvcSpans(t *testing.T) {
 		})
 	}
 }
+
+func TestInsertSvcSpan(t *testing.T) {
+	now := time.Now()
+	span100ms := &SpanData{
+		Span:       test.GenerateSpanWithDuration(t, "100ms", 100*time.Millisecond),
+		ReceivedAt: now,
+	}
+	span50ms := &SpanData{
+		Span:       test.GenerateSpanWithDuration(t, "50ms", 50*time.Millisecond),
+		ReceivedAt: now.Add(time.Second),
+	}
+	span200ms := &SpanData{
+		Span:       test.GenerateSpanWithDuration(t, "200ms", 200*time.Millisecond),
+		ReceivedAt: now.Add(2 * time.Second),
+	}
+	another100ms := &SpanData{
+		Span:       test.GenerateSpanWithDuration(t, "100ms", 100*time.Millisecond),
+		ReceivedAt: now.Add(3 * time.Second),
+	}
+
+	tests := []struct {
+		name     string
+		sortType SortType
+		want     SvcSpans
+	}{
+		{
+			name:     "SORT_TYPE_NONE",
+			sortType: SORT_TYPE_NONE,
+			want:     SvcSpans{span100ms, span50ms, span200ms, another100ms},
+		},
+		{
+			name:     "SORT_TYPE_LATENCY_DESC",
+			sortType: SORT_TYPE_LATENCY_DESC,
+			want:     SvcSpans{span200ms, span100ms, another100ms, span50ms},
+		},
+		{
+			name:     "SORT_TYPE_LATENCY_ASC",
+			sortType: SORT_TYPE_LATENCY_ASC,
+			want:     SvcSpans{span50ms, span100ms, another100ms, span200ms},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SvcSpans{}
+			for _, sd := range []*SpanData{span100ms, span50ms, span200ms, another100ms} {
+				got = insertSvcSpan(got, sd, tt.sortType)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
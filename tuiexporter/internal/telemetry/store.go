@@ -1,7 +1,8 @@
 package telemetry
 
 import (
-	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -19,6 +20,8 @@ const (
 	MAX_SERVICE_SPAN_COUNT = 1000
 	MAX_METRIC_COUNT       = 3000
 	MAX_LOG_COUNT          = 1000
+	// MAX_SERIES_POINT_COUNT is the number of the latest points retained in a metric series
+	MAX_SERIES_POINT_COUNT = 120
 )
 
 // SpanData is a struct to represent a span
@@ -27,6 +30,7 @@ type SpanData struct {
 	ResourceSpan *ptrace.ResourceSpans
 	ScopeSpans   *ptrace.ScopeSpans
 	ReceivedAt   time.Time
+	size         int64
 }
 
 // IsRoot returns true if the span is a root span
@@ -58,21 +62,24 @@ func (sd *SpanData) GetSpanName() string {
 // This is a slice of one span of a single service
 type SvcSpans []*SpanData
 
-func (ss *SvcSpans) replaceBySpanID(replaceSpanID string, data *SpanData) {
+func (ss *SvcSpans) replace(old, data *SpanData) {
 	for i, s := range *ss {
-		if s.Span.SpanID().String() == replaceSpanID {
+		if s == old {
 			(*ss)[i] = data
 			return
 		}
 	}
 }
 
-// MetricData is a struct to represent a metric
+// MetricData is a struct to represent a point of a metric series. The metric holds
+// a single data point.
 type MetricData struct {
 	Metric         *pmetric.Metric
 	ResourceMetric *pmetric.ResourceMetrics
 	ScopeMetric    *pmetric.ScopeMetrics
 	ReceivedAt     time.Time
+	size           int64
+	series         *MetricSeries
 }
 
 // HasNumberDatapoints returns whether it has number datapoints
@@ -92,28 +99,13 @@ func (md *MetricData) GetMetricTypeText() string {
 	return md.Metric.Type().String()
 }
 
-func (md *MetricData) GetDataPointNum() string {
-	switch md.Metric.Type() {
-	case pmetric.MetricTypeGauge:
-		return fmt.Sprintf("%d", md.Metric.Gauge().DataPoints().Len())
-	case pmetric.MetricTypeSum:
-		return fmt.Sprintf("%d", md.Metric.Sum().DataPoints().Len())
-	case pmetric.MetricTypeHistogram:
-		return fmt.Sprintf("%d", md.Metric.Histogram().DataPoints().Len())
-	case pmetric.MetricTypeExponentialHistogram:
-		return fmt.Sprintf("%d", md.Metric.ExponentialHistogram().DataPoints().Len())
-	case pmetric.MetricTypeSummary:
-		return fmt.Sprintf("%d", md.Metric.Summary().DataPoints().Len())
-	}
-	return ""
-}
-
 // LogData is a struct to represent a log
 type LogData struct {
 	Log         *plog.LogRecord
 	ResourceLog *plog.ResourceLogs
 	ScopeLog    *plog.ScopeLogs
 	ReceivedAt  time.Time
+	size        int64
 }
 
 func (l *LogData) GetResolvedBody() string {
@@ -164,44 +156,115 @@ type Store struct {
 	filterSvc           string
 	filterMetric        string
 	filterLog           string
+	filterTraceQuery    *traceQuery
+	filterLogQuery      *logQuery
+	filterPinnedOnly    bool
 	sortTrace           SortType
 	svcspans            SvcSpans
 	svcspansFiltered    SvcSpans
 	tracecache          *TraceCache
 	metrics             []*MetricData
-	metricsFiltered     []*MetricData
+	series              []*MetricSeries
+	seriesByKey         map[string]*MetricSeries
+	metricsFiltered     []*MetricSeries
 	metriccache         *MetricCache
 	logs                []*LogData
 	logsFiltered        []*LogData
 	logcache            *LogCache
 	updatedAt           time.Time
+	version             uint64
+	svcspansSnapshot    *Snapshot[SpanData]
+	metricsSnapshot     *Snapshot[MetricSeries]
+	logsSnapshot        *Snapshot[LogData]
 	maxServiceSpanCount int
 	maxMetricCount      int
+	maxSeriesPointCount int
 	maxLogCount         int
+	maxMemoryBytes      int64
+	memoryBytes         int64
+	ttl                 time.Duration
+	traceEvictionPolicy TraceEvictionPolicy
 	onSpanAdded         func()
 	onMetricAdded       func()
 	onLogAdded          func()
 	onFlushed           []func()
 }
 
+// StoreOption is a function to configure the store
+type StoreOption func(*Store)
+
+// WithMaxServiceSpanCount sets the maximum number of service spans (rows in the trace table)
+// retained in the store. Non-positive values are ignored.
+func WithMaxServiceSpanCount(n int) StoreOption {
+	return func(s *Store) {
+		if n > 0 {
+			s.maxServiceSpanCount = n
+		}
+	}
+}
+
+// WithMaxMetricCount sets the maximum number of metric series retained in the store.
+// Non-positive values are ignored.
+func WithMaxMetricCount(n int) StoreOption {
+	return func(s *Store) {
+		if n > 0 {
+			s.maxMetricCount = n
+		}
+	}
+}
+
+// WithMaxLogCount sets the maximum number of logs retained in the store.
+// Non-positive values are ignored.
+func WithMaxLogCount(n int) StoreOption {
+	return func(s *Store) {
+		if n > 0 {
+			s.maxLogCount = n
+		}
+	}
+}
+
+// WithMaxMemoryBytes sets the approximate memory budget of the retained telemetry.
+// When the estimated size exceeds the budget, the oldest data is evicted across
+// traces, metrics and logs. Non-positive values disable the budget.
+func WithMaxMemoryBytes(n int64) StoreOption {
+	return func(s *Store) {
+		if n > 0 {
+			s.maxMemoryBytes = n
+		}
+	}
+}
+
 // NewStore creates a new store
-func NewStore(clock clockwork.Clock) *Store {
-	return &Store{
+func NewStore(clock clockwork.Clock, opts ...StoreOption) *Store {
+	s := &Store{
 		mut:                 sync.Mutex{},
 		clockwork:           clock,
+		filterTraceQuery:    &traceQuery{},
+		filterLogQuery:      &logQuery{},
+		sortTrace:           SORT_TYPE_NONE,
 		svcspans:            SvcSpans{},
 		svcspansFiltered:    SvcSpans{},
 		tracecache:          NewTraceCache(),
 		metrics:             []*MetricData{},
-		metricsFiltered:     []*MetricData{},
+		series:              []*MetricSeries{},
+		seriesByKey:         map[string]*MetricSeries{},
+		metricsFiltered:     []*MetricSeries{},
 		metriccache:         NewMetricCache(),
 		logs:                []*LogData{},
 		logsFiltered:        []*LogData{},
 		logcache:            NewLogCache(),
-		maxServiceSpanCount: MAX_SERVICE_SPAN_COUNT, // TODO: make this configurable
-		maxMetricCount:      MAX_METRIC_COUNT,       // TODO: make this configurable
-		maxLogCount:         MAX_LOG_COUNT,          // TODO: make this configurable
+		maxServiceSpanCount: MAX_SERVICE_SPAN_COUNT,
+		maxMetricCount:      MAX_METRIC_COUNT,
+		maxSeriesPointCount: MAX_SERIES_POINT_COUNT,
+		maxLogCount:         MAX_LOG_COUNT,
+		traceEvictionPolicy: fifoEviction{},
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
 // GetTraceCache returns the trace cache
@@ -224,27 +287,15 @@ func (s *Store) GetSvcSpans() *SvcSpans {
 	return &s.svcspans
 }
 
-// GetFilteredSvcSpans returns the filtered service spans in the store
-func (s *Store) GetFilteredSvcSpans() *SvcSpans {
-	return &s.svcspansFiltered
-}
-
-// GetFilteredMetrics returns the filetered metrics in the store
-func (s *Store) GetFilteredMetrics() *[]*MetricData {
-	return &s.metricsFiltered
-}
-
-// GetFilteredLogs returns the filtered logs in the store
-func (s *Store) GetFilteredLogs() *[]*LogData {
-	return &s.logsFiltered
-}
-
 // UpdatedAt returns the last updated time
 func (s *Store) UpdatedAt() time.Time {
+	s.mut.Lock()
+	defer s.mut.Unlock()
 	return s.updatedAt
 }
 
-// SetOnSpanAdded sets the callback function to be called when a span is added
+// SetOnSpanAdded sets the callback function to be called when a span is added.
+// The callbacks are called without holding the lock of the store.
 func (s *Store) SetOnSpanAdded(f func()) {
 	s.onSpanAdded = f
 }
@@ -264,81 +315,135 @@ func (s *Store) RegisterOnFlushed(f func()) {
 	s.onFlushed = append(s.onFlushed, f)
 }
 
-// ApplyFilterTraces applies a filter and sort to the traces
-func (s *Store) ApplyFilterTraces(svc string, sortType SortType) {
+// ApplyFilterTraces applies a filter query and sort to the traces.
+// If the query is invalid, the current filter is kept and the error is returned.
+func (s *Store) ApplyFilterTraces(svc string, sortType SortType) error {
+	q, err := compileTraceQuery(svc)
+	if err != nil {
+		return err
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	defer s.markUpdatedLocked()
+
 	s.filterSvc = svc
+	s.filterTraceQuery = q
 	s.sortTrace = sortType
+	s.refilterSvcSpans()
+
+	return nil
+}
+
+// refilterSvcSpans evaluates the filter against all service spans and sorts them
+func (s *Store) refilterSvcSpans() {
 	s.svcspansFiltered = []*SpanData{}
 
-	if svc == "" {
-		s.svcspansFiltered = s.svcspans
-		sortSvcSpans(s.svcspansFiltered, sortType)
+	if s.filterTraceQuery.isEmpty() && !s.filterPinnedOnly {
+		// copy not to reorder the service spans which are evicted in received order
+		s.svcspansFiltered = append(s.svcspansFiltered, s.svcspans...)
+		sortSvcSpans(s.svcspansFiltered, s.sortTrace)
 		return
 	}
 
 	for _, span := range s.svcspans {
-		sname := GetServiceNameFromResource(span.ResourceSpan.Resource())
-		target := sname + " " + span.Span.Name()
-		if strings.Contains(target, svc) {
+		if s.matchSvcSpan(span) {
 			s.svcspansFiltered = append(s.svcspansFiltered, span)
 		}
 	}
 
-	sortSvcSpans(s.svcspansFiltered, sortType)
+	sortSvcSpans(s.svcspansFiltered, s.sortTrace)
 }
 
-func (s *Store) updateFilterService() {
-	s.ApplyFilterTraces(s.filterSvc, s.sortTrace)
+// matchSvcSpan returns whether the service span is shown in the trace table
+func (s *Store) matchSvcSpan(sd *SpanData) bool {
+	if s.filterPinnedOnly && !s.isPinned(sd) {
+		return false
+	}
+	return s.filterTraceQuery.match(sd, s.tracecache)
 }
 
-// ApplyFilterMetrics applies a filter to the metrics
-func (s *Store) ApplyFilterMetrics(filter string) {
-	s.filterMetric = filter
-	s.metricsFiltered = []*MetricData{}
+// updateFilteredSvcSpans merges the changed service spans into the filtered service spans
+// without evaluating and sorting all service spans again. The stale service spans are removed
+// before the changed ones are evaluated, and the replaced ones are never added.
+func (s *Store) updateFilteredSvcSpans(changed, stale []*SpanData, replaced map[*SpanData]struct{}) {
+	if len(stale) > 0 {
+		s.svcspansFiltered = removeItems(s.svcspansFiltered, toSet(stale))
+	}
 
-	if filter == "" {
-		s.metricsFiltered = s.metrics
-		return
+	seen := make(map[*SpanData]struct{}, len(changed))
+	for _, sd := range changed {
+		if _, ok := replaced[sd]; ok {
+			continue
+		}
+		if _, ok := seen[sd]; ok {
+			continue
+		}
+		seen[sd] = struct{}{}
+		if s.matchSvcSpan(sd) {
+			s.svcspansFiltered = insertSvcSpan(s.svcspansFiltered, sd, s.sortTrace)
+		}
 	}
+}
 
-	for _, metric := range s.metrics {
-		sname := GetServiceNameFromResource(metric.ResourceMetric.Resource())
-		target := sname + " " + metric.Metric.Name()
-		if strings.Contains(target, filter) {
-			s.metricsFiltered = append(s.metricsFiltered, metric)
+// ApplyFilterMetrics applies a filter to the metric series
+func (s *Store) ApplyFilterMetrics(filter string) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	defer s.markUpdatedLocked()
+
+	s.filterMetric = filter
+	s.metricsFiltered = []*MetricSeries{}
+
+	for _, ms := range s.series {
+		if matchMetric(ms, filter) {
+			s.metricsFiltered = append(s.metricsFiltered, ms)
 		}
 	}
 }
 
-func (s *Store) updateFilterMetrics() {
-	s.ApplyFilterMetrics(s.filterMetric)
+func matchMetric(ms *MetricSeries, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	return strings.Contains(ms.GetServiceName()+" "+ms.GetMetricName(), filter)
 }
 
-// ApplyFilterLogs applies a filter to the logs
-func (s *Store) ApplyFilterLogs(filter string) {
+// ApplyFilterLogs applies a filter query to the logs.
+// If the query is invalid, the current filter is kept and the error is returned.
+func (s *Store) ApplyFilterLogs(filter string) error {
+	q, err := compileLogQuery(filter)
+	if err != nil {
+		return err
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	defer s.markUpdatedLocked()
+
 	s.filterLog = filter
+	s.filterLogQuery = q
 	s.logsFiltered = []*LogData{}
 
-	if filter == "" {
-		s.logsFiltered = s.logs
-		return
+	if q.isEmpty() {
+		s.logsFiltered = append(s.logsFiltered, s.logs...)
+		return nil
 	}
 
 	for _, log := range s.logs {
-		sname := GetServiceNameFromResource(log.ResourceLog.Resource())
-		target := sname + " " + log.Log.Body().AsString()
-		if strings.Contains(target, filter) {
+		if q.match(log) {
 			s.logsFiltered = append(s.logsFiltered, log)
 		}
 	}
-}
 
-func (s *Store) updateFilterLogs() {
-	s.ApplyFilterLogs(s.filterLog)
+	return nil
 }
 
 // GetTraceIDByFilteredIdx returns the trace at the given index
 func (s *Store) GetTraceIDByFilteredIdx(idx int) string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
 	if idx >= 0 && idx < len(s.svcspansFiltered) {
 		return s.svcspansFiltered[idx].Span.TraceID().String()
 	}
@@ -347,6 +452,9 @@ func (s *Store) GetTraceIDByFilteredIdx(idx int) string {
 
 // GetFilteredServiceSpansByIdx returns the spans for a given service at the given index
 func (s *Store) GetFilteredServiceSpansByIdx(idx int) []*SpanData {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
 	if idx < 0 || idx >= len(s.svcspansFiltered) {
 		return []*SpanData{}
 	}
@@ -358,51 +466,37 @@ func (s *Store) GetFilteredServiceSpansByIdx(idx int) []*SpanData {
 	return spans
 }
 
-// RecalculateServiceRootSpanByIdx recalculates service root span of the specified index
-func (s *Store) RecalculateServiceRootSpanByIdx(idx int) {
+// GetFilteredMetricByIdx returns the metric series at the given index
+func (s *Store) GetFilteredMetricByIdx(idx int) *MetricSeries {
 	s.mut.Lock()
-	defer func() {
-		s.updatedAt = s.clockwork.Now()
-		s.mut.Unlock()
-	}()
-
-	if idx < 0 || idx >= len(s.svcspansFiltered) {
-		return
-	}
-	traceID := s.svcspansFiltered[idx].Span.TraceID().String()
-	currentSpanID := s.svcspansFiltered[idx].Span.SpanID().String()
-	sname, ok := s.svcspansFiltered[idx].ResourceSpan.Resource().Attributes().Get("service.name")
-	if !ok {
-		return
-	}
-
-	spans := s.tracecache.tracesvc2spans[traceID][sname.AsString()]
-	spanMemo := make(map[string]bool)
-	for _, span := range spans {
-		spanMemo[span.Span.SpanID().String()] = true
-	}
-	for _, span := range spans {
-		parentSpanID := span.Span.ParentSpanID().String()
-		spanID := span.Span.SpanID().String()
-		if _, ok := spanMemo[parentSpanID]; !ok {
-			// TODO: Condider orphan span?
-			sd := s.tracecache.spanid2span[spanID]
-			s.svcspansFiltered[idx] = sd
-			s.svcspans.replaceBySpanID(currentSpanID, sd)
-		}
-	}
-}
+	defer s.mut.Unlock()
 
-// GetFilteredMetricByIdx returns the metric at the given index
-func (s *Store) GetFilteredMetricByIdx(idx int) *MetricData {
 	if idx < 0 || idx >= len(s.metricsFiltered) {
 		return nil
 	}
 	return s.metricsFiltered[idx]
 }
 
+// GetMetricSeries returns the series of the same metric as the given series, which share
+// the resource, the scope and the metric name, in the order they were created
+func (s *Store) GetMetricSeries(ms *MetricSeries) []*MetricSeries {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	series := []*MetricSeries{}
+	for _, sibling := range s.series {
+		if sibling.metricKey == ms.metricKey {
+			series = append(series, sibling)
+		}
+	}
+	return series
+}
+
 // GetFilteredLogByIdx returns the log at the given index
 func (s *Store) GetFilteredLogByIdx(idx int) *LogData {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
 	if idx < 0 || idx >= len(s.logsFiltered) {
 		return nil
 	}
@@ -412,16 +506,29 @@ func (s *Store) GetFilteredLogByIdx(idx int) *LogData {
 // AddSpan adds spans to the store
 func (s *Store) AddSpan(traces *ptrace.Traces) {
 	s.mut.Lock()
-	defer func() {
-		s.updatedAt = s.clockwork.Now()
-		s.mut.Unlock()
-	}()
+	evicted := s.addSpanLocked(traces, s.clockwork.Now)
+	s.markUpdatedLocked()
+	s.mut.Unlock()
+
+	s.notifySignals(signalSpans | evicted)
+}
+
+// addSpanLocked adds spans with the received time returned by receivedAt. It returns the signals
+// evicted by the memory limit. The caller must hold s.mut.
+func (s *Store) addSpanLocked(traces *ptrace.Traces, receivedAt func() time.Time) signals {
+	var (
+		changed  = []*SpanData{}
+		stale    = []*SpanData{}
+		replaced = map[*SpanData]struct{}{}
+	)
+	evalAllSpans := s.filterTraceQuery.hasSpanTerms()
 
 	for rsi := 0; rsi < traces.ResourceSpans().Len(); rsi++ {
 		rs := traces.ResourceSpans().At(rsi)
 
 		for ssi := 0; ssi < rs.ScopeSpans().Len(); ssi++ {
 			ss := rs.ScopeSpans().At(ssi)
+			shared := sharedSize(estimateResourceSize(rs.Resource(), ss.Scope()), ss.Spans().Len())
 
 			for si := 0; si < ss.Spans().Len(); si++ {
 				span := ss.Spans().At(si)
@@ -430,92 +537,142 @@ func (s *Store) AddSpan(traces *ptrace.Traces) {
 					Span:         &span,
 					ResourceSpan: &rs,
 					ScopeSpans:   &ss,
-					ReceivedAt:   s.clockwork.Now(),
+					ReceivedAt:   receivedAt(),
+					size:         estimateSpanSize(span) + shared,
 				}
-				newtracesvc, replaceSpanID := s.tracecache.UpdateCache(sname, sd)
-				if newtracesvc {
+				s.memoryBytes += sd.size
+				newtracesvc, old := s.tracecache.UpdateCache(sname, sd)
+				switch {
+				case newtracesvc:
 					s.svcspans = append(s.svcspans, sd)
-				} else if len(replaceSpanID) > 0 {
+					changed = append(changed, sd)
+				case old != nil:
+					// the new span is not always the new service root span, e.g. it connects
+					// the current service root span to an earlier root
+					row, _ := s.tracecache.GetServiceRootSpan(sd.Span.TraceID().String(), sname)
+					stale = append(stale, old)
+					replaced[old] = struct{}{}
 					// FIXME: More efficient logic is needed
-					s.svcspans.replaceBySpanID(replaceSpanID, sd)
+					s.svcspans.replace(old, row)
+					changed = append(changed, row)
+				case evalAllSpans:
+					// the new span can change the result of the filter evaluated against all spans of the service
+					if row, ok := s.tracecache.GetServiceRootSpan(sd.Span.TraceID().String(), sname); ok {
+						stale = append(stale, row)
+						changed = append(changed, row)
+					}
 				}
 			}
 		}
 	}
 
+	s.updateFilteredSvcSpans(changed, stale, replaced)
+
 	// data rotation
 	if len(s.svcspans) > s.maxServiceSpanCount {
-		deleteSpans := s.svcspans[:len(s.svcspans)-s.maxServiceSpanCount]
-
-		s.tracecache.DeleteCache(deleteSpans)
-
-		s.svcspans = s.svcspans[len(s.svcspans)-s.maxServiceSpanCount:]
-	}
-
-	s.updateFilterService()
-
-	if s.onSpanAdded != nil {
-		s.onSpanAdded()
+		n := len(s.svcspans) - s.maxServiceSpanCount
+		s.evictSvcSpans(s.traceEvictionPolicy.SelectEvictions(s.unpinnedSvcSpans(), n, s.tracecache))
 	}
+	return s.enforceMemoryLimit()
 }
 
 // AddMetric adds metrics to the store
 func (s *Store) AddMetric(metrics *pmetric.Metrics) {
 	s.mut.Lock()
-	defer func() {
-		s.updatedAt = s.clockwork.Now()
-		s.mut.Unlock()
-	}()
+	evicted := s.addMetricLocked(metrics, s.clockwork.Now)
+	s.markUpdatedLocked()
+	s.mut.Unlock()
+
+	s.notifySignals(signalMetrics | evicted)
+}
+
+// addMetricLocked adds metrics with the received time returned by receivedAt. Each data point
+// is added to the series it belongs to as a point. It returns the signals evicted by the memory
+// limit. The caller must hold s.mut.
+func (s *Store) addMetricLocked(metrics *pmetric.Metrics, receivedAt func() time.Time) signals {
+	overwritten := map[*MetricData]struct{}{}
 
 	for rmi := 0; rmi < metrics.ResourceMetrics().Len(); rmi++ {
 		rm := metrics.ResourceMetrics().At(rmi)
 
 		for smi := 0; smi < rm.ScopeMetrics().Len(); smi++ {
 			sm := rm.ScopeMetrics().At(smi)
+			npoints := 0
+			for si := 0; si < sm.Metrics().Len(); si++ {
+				npoints += dataPointCount(sm.Metrics().At(si))
+			}
+			shared := sharedSize(estimateResourceSize(rm.Resource(), sm.Scope()), npoints)
 
 			for si := 0; si < sm.Metrics().Len(); si++ {
 				sname := GetServiceNameFromResource(rm.Resource())
 				metric := sm.Metrics().At(si)
-				sd := &MetricData{
-					Metric:         &metric,
-					ResourceMetric: &rm,
-					ScopeMetric:    &sm,
-					ReceivedAt:     s.clockwork.Now(),
+				for dpi := 0; dpi < dataPointCount(metric); dpi++ {
+					point := newPointMetric(metric, dpi)
+					md := &MetricData{
+						Metric:         &point,
+						ResourceMetric: &rm,
+						ScopeMetric:    &sm,
+						ReceivedAt:     receivedAt(),
+						size:           estimateMetricSize(point) + shared,
+					}
+					s.memoryBytes += md.size
+					s.metrics = append(s.metrics, md)
+					s.metriccache.UpdateCache(sname, md)
+					if old := s.addToSeries(md); old != nil {
+						overwritten[old] = struct{}{}
+					}
 				}
-				s.metrics = append(s.metrics, sd)
-				s.metriccache.UpdateCache(sname, sd)
 			}
 		}
 	}
 
-	// data rotation
-	if len(s.metrics) > s.maxMetricCount {
-		deleteMetrics := s.metrics[:len(s.metrics)-s.maxMetricCount]
-		s.metrics = s.metrics[len(s.metrics)-s.maxMetricCount:]
-
-		s.metriccache.DeleteCache(deleteMetrics)
+	// the oldest points pushed out of the ring buffers of the series
+	if len(overwritten) > 0 {
+		s.deletePoints(overwritten)
 	}
 
-	s.updateFilterMetrics()
+	// data rotation
+	if len(s.series) > s.maxMetricCount {
+		s.evictSeries(len(s.series) - s.maxMetricCount)
+	}
+	return s.enforceMemoryLimit()
+}
 
-	if s.onMetricAdded != nil {
-		s.onMetricAdded()
+// addToSeries adds the point to the series it belongs to, creating the series if it is new.
+// It returns the oldest point overwritten in the series if its ring buffer is full.
+func (s *Store) addToSeries(md *MetricData) *MetricData {
+	key := seriesKey(md)
+	ms, ok := s.seriesByKey[key]
+	if !ok {
+		ms = newMetricSeries(key, md, s.maxSeriesPointCount)
+		s.seriesByKey[key] = ms
+		s.series = append(s.series, ms)
+		if matchMetric(ms, s.filterMetric) {
+			s.metricsFiltered = append(s.metricsFiltered, ms)
+		}
 	}
+	return ms.add(md)
 }
 
 // AddLog adds logs to the store
 func (s *Store) AddLog(logs *plog.Logs) {
 	s.mut.Lock()
-	defer func() {
-		s.updatedAt = s.clockwork.Now()
-		s.mut.Unlock()
-	}()
+	evicted := s.addLogLocked(logs, s.clockwork.Now)
+	s.markUpdatedLocked()
+	s.mut.Unlock()
 
+	s.notifySignals(signalLogs | evicted)
+}
+
+// addLogLocked adds logs with the received time returned by receivedAt. It returns the signals
+// evicted by the memory limit. The caller must hold s.mut.
+func (s *Store) addLogLocked(logs *plog.Logs, receivedAt func() time.Time) signals {
 	for rli := 0; rli < logs.ResourceLogs().Len(); rli++ {
 		rl := logs.ResourceLogs().At(rli)
 
 		for sli := 0; sli < rl.ScopeLogs().Len(); sli++ {
 			sl := rl.ScopeLogs().At(sli)
+			shared := sharedSize(estimateResourceSize(rl.Resource(), sl.Scope()), sl.LogRecords().Len())
 
 			for li := 0; li < sl.LogRecords().Len(); li++ {
 				lr := sl.LogRecords().At(li)
@@ -523,49 +680,265 @@ func (s *Store) AddLog(logs *plog.Logs) {
 					Log:         &lr,
 					ResourceLog: &rl,
 					ScopeLog:    &sl,
-					ReceivedAt:  s.clockwork.Now(),
+					ReceivedAt:  receivedAt(),
+					size:        estimateLogSize(lr) + shared,
 				}
+				s.memoryBytes += ld.size
 				s.logs = append(s.logs, ld)
 				s.logcache.UpdateCache(ld)
+				if s.filterLogQuery.match(ld) {
+					s.logsFiltered = append(s.logsFiltered, ld)
+				}
 			}
 		}
 	}
 
 	// data rotation
 	if len(s.logs) > s.maxLogCount {
-		deleteLogs := s.logs[:len(s.logs)-s.maxLogCount]
-		s.logs = s.logs[len(s.logs)-s.maxLogCount:]
-
-		s.logcache.DeleteCache(deleteLogs)
-	}
-
-	s.updateFilterLogs()
-
-	if s.onLogAdded != nil {
-		s.onLogAdded()
+		s.evictLogs(len(s.logs) - s.maxLogCount)
 	}
+	return s.enforceMemoryLimit()
 }
 
-// Flush clears the store including the cache
+// Flush clears the store including the cache except the pinned traces
 func (s *Store) Flush() {
 	s.mut.Lock()
-	defer func() {
-		s.updatedAt = s.clockwork.Now()
-		s.mut.Unlock()
-	}()
-
-	s.svcspans = SvcSpans{}
-	s.svcspansFiltered = SvcSpans{}
-	s.tracecache.flush()
+	s.memoryBytes = 0
+	if s.tracecache.HasPins() {
+		s.flushUnpinnedSvcSpans()
+	} else {
+		s.svcspans = SvcSpans{}
+		s.svcspansFiltered = SvcSpans{}
+		s.tracecache.flush()
+	}
 	s.metrics = []*MetricData{}
-	s.metricsFiltered = []*MetricData{}
+	s.series = []*MetricSeries{}
+	s.seriesByKey = map[string]*MetricSeries{}
+	s.metricsFiltered = []*MetricSeries{}
 	s.metriccache.flush()
 	s.logs = []*LogData{}
 	s.logsFiltered = []*LogData{}
 	s.logcache.flush()
-	s.updatedAt = s.clockwork.Now()
+	s.markUpdatedLocked()
+	s.mut.Unlock()
 
 	for _, f := range s.onFlushed {
 		f()
 	}
 }
+
+// notify calls the callback function if it is set
+func notify(f func()) {
+	if f != nil {
+		f()
+	}
+}
+
+// signals is a set of the telemetry signals in the store
+type signals uint8
+
+const (
+	signalSpans signals = 1 << iota
+	signalMetrics
+	signalLogs
+)
+
+// notifySignals calls the callback of each signal in the set once. The caller must not hold s.mut
+// so that the callbacks can read the store.
+func (s *Store) notifySignals(changed signals) {
+	if changed&signalSpans != 0 {
+		notify(s.onSpanAdded)
+	}
+	if changed&signalMetrics != 0 {
+		notify(s.onMetricAdded)
+	}
+	if changed&signalLogs != 0 {
+		notify(s.onLogAdded)
+	}
+}
+
+// evictSvcSpans deletes the service spans and their spans in the cache
+func (s *Store) evictSvcSpans(deleteSpans []*SpanData) {
+	for _, ss := range deleteSpans {
+		s.memoryBytes -= s.svcSpanSize(ss)
+	}
+
+	s.tracecache.DeleteCache(deleteSpans)
+
+	deleted := toSet(deleteSpans)
+	s.svcspans = removeItems(s.svcspans, deleted)
+	s.svcspansFiltered = removeItems(s.svcspansFiltered, deleted)
+}
+
+// evictMetrics deletes the oldest n points and the series having no points left
+func (s *Store) evictMetrics(n int) {
+	deleteMetrics := s.metrics[:n]
+	emptied := map[*MetricSeries]struct{}{}
+	for _, m := range deleteMetrics {
+		s.memoryBytes -= m.size
+		// the points of a series are evicted from the oldest as well as the points in the store
+		if m.series.removeOldest() == 0 {
+			emptied[m.series] = struct{}{}
+		}
+	}
+	s.metrics = s.metrics[n:]
+
+	s.metriccache.DeleteCache(deleteMetrics)
+	s.removeSeries(emptied)
+}
+
+// evictSeries deletes n series which were updated least recently and their points
+func (s *Store) evictSeries(n int) {
+	series := slices.Clone(s.series)
+	sort.SliceStable(series, func(i, j int) bool {
+		return series[i].Latest().ReceivedAt.Before(series[j].Latest().ReceivedAt)
+	})
+
+	deleteSeries := toSet(series[:n])
+	deletePoints := map[*MetricData]struct{}{}
+	for ms := range deleteSeries {
+		for _, m := range ms.Points() {
+			deletePoints[m] = struct{}{}
+		}
+	}
+	s.deletePoints(deletePoints)
+	s.removeSeries(deleteSeries)
+}
+
+// deletePoints deletes the points from the store and the cache. The series are not updated.
+func (s *Store) deletePoints(points map[*MetricData]struct{}) {
+	deleteMetrics := make([]*MetricData, 0, len(points))
+	for m := range points {
+		s.memoryBytes -= m.size
+		deleteMetrics = append(deleteMetrics, m)
+	}
+	s.metrics = removeItems(s.metrics, points)
+
+	s.metriccache.DeleteCache(deleteMetrics)
+}
+
+// removeSeries removes the series from the store
+func (s *Store) removeSeries(series map[*MetricSeries]struct{}) {
+	if len(series) == 0 {
+		return
+	}
+	for ms := range series {
+		delete(s.seriesByKey, ms.key)
+	}
+	s.series = removeItems(s.series, series)
+	s.metricsFiltered = removeItems(s.metricsFiltered, series)
+}
+
+// evictLogs deletes the oldest n logs
+func (s *Store) evictLogs(n int) {
+	deleteLogs := s.logs[:n]
+	for _, l := range deleteLogs {
+		s.memoryBytes -= l.size
+	}
+	s.logs = s.logs[n:]
+	s.logsFiltered = removeItems(s.logsFiltered, toSet(deleteLogs))
+
+	s.logcache.DeleteCache(deleteLogs)
+}
+
+// svcSpanSize returns the estimated size of all spans belonging to the service span
+func (s *Store) svcSpanSize(ss *SpanData) int64 {
+	spans, ok := s.tracecache.GetSpansByTraceIDAndSvc(ss.Span.TraceID().String(), ss.GetServiceName())
+	if !ok {
+		return ss.size
+	}
+	var size int64
+	for _, span := range spans {
+		size += span.size
+	}
+	return size
+}
+
+// enforceMemoryLimit evicts the oldest data across traces, metrics and logs
+// until the estimated memory usage fits in the budget. Pinned traces are not evicted.
+// It returns the signals which have been evicted.
+func (s *Store) enforceMemoryLimit() signals {
+	var evicted signals
+	if s.maxMemoryBytes <= 0 || s.memoryBytes <= s.maxMemoryBytes {
+		return evicted
+	}
+
+	var (
+		usage                   = s.memoryBytes
+		svcspans                = s.unpinnedSvcSpans()
+		nspans, nmetrics, nlogs int
+	)
+	for usage > s.maxMemoryBytes {
+		var oldest *time.Time
+		kind := -1
+		if nspans < len(svcspans) {
+			oldest, kind = &svcspans[nspans].ReceivedAt, 0
+		}
+		if nmetrics < len(s.metrics) && (oldest == nil || s.metrics[nmetrics].ReceivedAt.Before(*oldest)) {
+			oldest, kind = &s.metrics[nmetrics].ReceivedAt, 1
+		}
+		if nlogs < len(s.logs) && (oldest == nil || s.logs[nlogs].ReceivedAt.Before(*oldest)) {
+			kind = 2
+		}
+
+		switch kind {
+		case 0:
+			usage -= s.svcSpanSize(svcspans[nspans])
+			nspans++
+		case 1:
+			usage -= s.metrics[nmetrics].size
+			nmetrics++
+		case 2:
+			usage -= s.logs[nlogs].size
+			nlogs++
+		default:
+			// nothing left to evict
+			usage = 0
+		}
+	}
+
+	if nspans > 0 {
+		s.evictSvcSpans(svcspans[:nspans])
+		evicted |= signalSpans
+	}
+	if nmetrics > 0 {
+		s.evictMetrics(nmetrics)
+		evicted |= signalMetrics
+	}
+	if nlogs > 0 {
+		s.evictLogs(nlogs)
+		evicted |= signalLogs
+	}
+	return evicted
+}
+
+// removeItems returns the items except the ones in the set keeping the order
+func removeItems[T comparable](items []T, remove map[T]struct{}) []T {
+	// The oldest items are usually removed, which are at the head
+	head := 0
+	for head < len(items) {
+		if _, ok := remove[items[head]]; !ok {
+			break
+		}
+		head++
+	}
+	if head == len(remove) {
+		return items[head:]
+	}
+
+	result := make([]T, 0, len(items)-head)
+	for _, item := range items[head:] {
+		if _, ok := remove[item]; !ok {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func toSet[T comparable](items []T) map[T]struct{} {
+	set := make(map[T]struct{}, len(items))
+	for _, item := range items {
+		set[item] = struct{}{}
+	}
+	return set
+}
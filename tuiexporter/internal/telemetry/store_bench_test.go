@@ -0,0 +1,107 @@
+package telemetry
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// The benchmarks add data to a store filled up to the max count. The "full recompute"
+// cases apply the filter to all data after every ingest as it used to be, to compare
+// with the incremental update.
+
+func BenchmarkStoreAddSpan(b *testing.B) {
+	queries := []string{
+		"",
+		"svc-1",
+		"status=error http.response.status_code>=500",
+	}
+	sortTypes := []SortType{SORT_TYPE_NONE, SORT_TYPE_LATENCY_DESC}
+
+	for _, fullRecompute := range []bool{false, true} {
+		for _, sortType := range sortTypes {
+			for _, q := range queries {
+				name := fmt.Sprintf("incremental/%s/%q", sortType, q)
+				if fullRecompute {
+					name = fmt.Sprintf("full recompute/%s/%q", sortType, q)
+				}
+				b.Run(name, func(b *testing.B) {
+					store := NewStore(clockwork.NewRealClock())
+					if err := store.ApplyFilterTraces(q, sortType); err != nil {
+						b.Fatal(err)
+					}
+					traceID := 0
+					addTrace := func() {
+						traceID++
+						traces := generateTestTraces(
+							testSpan{traceID: traceID, spanNo: 1, svc: fmt.Sprintf("svc-%d", traceID%3), duration: time.Duration(traceID%1000) * time.Millisecond, statusCode: 200},
+							testSpan{traceID: traceID, spanNo: 2, parentNo: 1, svc: fmt.Sprintf("svc-%d", traceID%3), duration: time.Millisecond, statusCode: 500, hasError: traceID%10 == 0},
+						)
+						store.AddSpan(&traces)
+						if fullRecompute {
+							_ = store.ApplyFilterTraces(q, sortType)
+						}
+					}
+					for range MAX_SERVICE_SPAN_COUNT {
+						addTrace()
+					}
+
+					b.ResetTimer()
+					for range b.N {
+						addTrace()
+					}
+				})
+			}
+		}
+	}
+}
+
+func BenchmarkStoreAddLog(b *testing.B) {
+	queries := []string{
+		"",
+		"severity>=WARN body~timeout",
+	}
+
+	for _, fullRecompute := range []bool{false, true} {
+		for _, q := range queries {
+			name := fmt.Sprintf("incremental/%q", q)
+			if fullRecompute {
+				name = fmt.Sprintf("full recompute/%q", q)
+			}
+			b.Run(name, func(b *testing.B) {
+				store := NewStore(clockwork.NewRealClock())
+				if err := store.ApplyFilterLogs(q); err != nil {
+					b.Fatal(err)
+				}
+				n := 0
+				addLogs := func() {
+					n++
+					logs := plog.NewLogs()
+					rl := logs.ResourceLogs().AppendEmpty()
+					rl.Resource().Attributes().PutStr("service.name", fmt.Sprintf("svc-%d", n%3))
+					lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+					for i := range 10 {
+						lr := lrs.AppendEmpty()
+						lr.SetSeverityNumber(plog.SeverityNumber(1 + (n+i)%24)) // #nosec G115
+						lr.Body().SetStr(fmt.Sprintf("request %d-%d timeout", n, i))
+					}
+					store.AddLog(&logs)
+					if fullRecompute {
+						_ = store.ApplyFilterLogs(q)
+					}
+				}
+				for range MAX_LOG_COUNT / 10 {
+					addLogs()
+				}
+
+				b.ResetTimer()
+				for range b.N {
+					addLogs()
+				}
+			})
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package telemetry
 
 import (
+	"encoding/binary"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/test"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/ptrace"
@@ -47,9 +51,6 @@ func TestStoreGetter(t *testing.T) {
 	assert.Equal(t, store.metriccache, store.GetMetricCache())
 	assert.Equal(t, store.logcache, store.GetLogCache())
 	assert.Equal(t, &store.svcspans, store.GetSvcSpans())
-	assert.Equal(t, &store.svcspansFiltered, store.GetFilteredSvcSpans())
-	assert.Equal(t, &store.metricsFiltered, store.GetFilteredMetrics())
-	assert.Equal(t, &store.logsFiltered, store.GetFilteredLogs())
 	assert.Equal(t, store.updatedAt, store.UpdatedAt())
 }
 
@@ -73,7 +74,7 @@ func TestStoreSpanFilters(t *testing.T) {
 	testdata.RSpans[2].Resource().Attributes().Clear()
 	store.AddSpan(&payload)
 
-	store.ApplyFilterTraces("0-0", SORT_TYPE_NONE)
+	assert.NoError(t, store.ApplyFilterTraces("0-0", SORT_TYPE_NONE))
 	assert.Equal(t, 3, len(store.svcspansFiltered))
 	assert.Equal(t, traceID, store.GetTraceIDByFilteredIdx(0))
 	assert.Equal(t, traceID, store.GetTraceIDByFilteredIdx(1))
@@ -83,7 +84,7 @@ func TestStoreSpanFilters(t *testing.T) {
 	assert.Equal(t, "span-0-0-1", store.GetFilteredServiceSpansByIdx(0)[1].Span.Name())
 	// spans in test-service-2
 	assert.Equal(t, "span-1-0-0", store.GetFilteredServiceSpansByIdx(1)[0].Span.Name())
-	store.ApplyFilterTraces("service-2", SORT_TYPE_NONE)
+	assert.NoError(t, store.ApplyFilterTraces("service-2", SORT_TYPE_NONE))
 	assert.Equal(t, 1, len(store.svcspansFiltered))
 	assert.Equal(t, traceID, store.GetTraceIDByFilteredIdx(0))
 	assert.Equal(t, "", store.GetTraceIDByFilteredIdx(1))
@@ -125,7 +126,7 @@ func TestStoreSpanFilters(t *testing.T) {
 	}
 
 	// spans in unknown service
-	store.ApplyFilterTraces("unknown", SORT_TYPE_NONE)
+	assert.NoError(t, store.ApplyFilterTraces("unknown", SORT_TYPE_NONE))
 	assert.Equal(t, "span-2-0-0", store.GetFilteredServiceSpansByIdx(0)[0].Span.Name())
 }
 
@@ -149,8 +150,9 @@ func TestStoreMetricFilters(t *testing.T) {
 
 	store.ApplyFilterMetrics("service-2")
 	assert.Equal(t, 1, len(store.metricsFiltered))
+	// metric-1-1-1 has a series per data point
 	store.ApplyFilterMetrics("metric 0")
-	assert.Equal(t, 2, len(store.metricsFiltered))
+	assert.Equal(t, 3, len(store.metricsFiltered))
 
 	tests := []struct {
 		name string
@@ -159,12 +161,12 @@ func TestStoreMetricFilters(t *testing.T) {
 	}{
 		{
 			name: "invalid index",
-			idx:  2,
+			idx:  3,
 			want: nil,
 		},
 		{
 			name: "valid index",
-			idx:  1,
+			idx:  2,
 			want: &MetricData{
 				Metric:         testdata.Metrics[1],  // metric-1-2-1
 				ResourceMetric: testdata.RMetrics[0], // test-service-1
@@ -177,9 +179,10 @@ func TestStoreMetricFilters(t *testing.T) {
 		t.Run("GetFilteredMetricByIdx_"+tt.name, func(t *testing.T) {
 			got := store.GetFilteredMetricByIdx(tt.idx)
 			if tt.want != nil {
-				assert.Equal(t, tt.want.Metric, got.Metric)
-				assert.Equal(t, tt.want.ResourceMetric, got.ResourceMetric)
-				assert.Equal(t, tt.want.ScopeMetric, got.ScopeMetric)
+				latest := got.Latest()
+				assert.Equal(t, tt.want.Metric.Name(), latest.Metric.Name())
+				assert.Equal(t, tt.want.ResourceMetric, latest.ResourceMetric)
+				assert.Equal(t, tt.want.ScopeMetric, latest.ScopeMetric)
 			} else {
 				assert.Nil(t, got)
 			}
@@ -210,9 +213,9 @@ func TestStoreLogFilters(t *testing.T) {
 	payload, testdata := test.GenerateOTLPLogsPayload(t, 1, 2, []int{2, 1}, [][]int{{2, 1}, {1}})
 	store.AddLog(&payload)
 
-	store.ApplyFilterLogs("service-2")
+	assert.NoError(t, store.ApplyFilterLogs("service-2"))
 	assert.Equal(t, 2, len(store.logsFiltered))
-	store.ApplyFilterLogs("log body 1-0-0-0")
+	assert.NoError(t, store.ApplyFilterLogs("log body 1-0-0-0"))
 	assert.Equal(t, 1, len(store.logsFiltered))
 
 	tests := []struct {
@@ -420,7 +423,7 @@ func TestStoreAddSpanServiceSpanCalculation(t *testing.T) {
 	assert.Equal(t, "span-0-0-0", store.svcspans[0].Span.Name())
 }
 
-func TestStoreAddSpanServiceSpanCalculationLimitation(t *testing.T) {
+func TestStoreAddSpanServiceRootSpanOutOfOrder(t *testing.T) {
 	// traceid: 1
 	//  └- resource: test-service-1
 	//    └- scope: test-scope-1-1
@@ -478,20 +481,17 @@ func TestStoreAddSpanServiceSpanCalculationLimitation(t *testing.T) {
 
 	store.AddSpan(&payload2)
 
-	// The service root span should still be span-1-1-3
+	// The service root span should still be span-1-1-3 because span-1-1-1 and span-1-1-3
+	// are not connected yet and started at the same time
 	assert.Equal(t, 1, len(store.svcspans))
 	assert.Equal(t, "span-0-0-2", store.svcspans[0].Span.Name())
 
 	store.AddSpan(&payload3)
 
-	// Finally, The service root span should be span-1-1-2
-	assert.Equal(t, 1, len(store.svcspans))
-	assert.Equal(t, "span-0-0-1", store.svcspans[0].Span.Name())
-
-	// By RecalculateServiceRootSpanByIdx, we can get span-1-1-1 as the root span
-	store.RecalculateServiceRootSpanByIdx(0)
+	// Finally, span-1-1-2 connects them and the service root span should be span-1-1-1
 	assert.Equal(t, 1, len(store.svcspans))
 	assert.Equal(t, "span-0-0-0", store.svcspans[0].Span.Name())
+	assert.Equal(t, SvcSpans{store.svcspans[0]}, store.svcspansFiltered)
 }
 
 func TestStoreAddMetricWithoutRotation(t *testing.T) {
@@ -509,7 +509,7 @@ func TestStoreAddMetricWithoutRotation(t *testing.T) {
 	//      └- metric: metric-2-1-1
 	//        └- datapoint: dp-2-1-1-1
 	store := NewStore(clockwork.NewRealClock())
-	store.maxMetricCount = 3 // no rotation
+	store.maxMetricCount = 4 // no rotation
 	before := store.updatedAt
 	payload, testdata := test.GenerateOTLPGaugeMetricsPayload(t, 2, []int{2, 1}, [][]int{{2, 1}, {1}})
 	store.AddMetric(&payload)
@@ -517,25 +517,35 @@ func TestStoreAddMetricWithoutRotation(t *testing.T) {
 	assert.Equal(t, "", store.filterMetric)
 	assert.True(t, before.Before(store.updatedAt))
 
-	// assert metrics
-	assert.Equal(t, 3, len(store.metrics))
-	assert.Equal(t, testdata.Metrics[0], store.metrics[0].Metric)          // metric-1-1-1
-	assert.Equal(t, testdata.RMetrics[0], store.metrics[0].ResourceMetric) // test-service-1
-	assert.Equal(t, testdata.SMetrics[0], store.metrics[0].ScopeMetric)    // test-scope-1-1
-	assert.Equal(t, testdata.Metrics[2], store.metrics[2].Metric)          // metric-2-1-1
-	assert.Equal(t, testdata.RMetrics[1], store.metrics[2].ResourceMetric) // test-service-2
-	assert.Equal(t, testdata.SMetrics[2], store.metrics[2].ScopeMetric)    // test-scope-2-1
+	// assert metrics, which are the points holding a single data point
+	assert.Equal(t, 4, len(store.metrics))
+	assert.Equal(t, testdata.Metrics[0].Name(), store.metrics[0].Metric.Name()) // metric-1-1-1
+	assert.Equal(t, testdata.RMetrics[0], store.metrics[0].ResourceMetric)      // test-service-1
+	assert.Equal(t, testdata.SMetrics[0], store.metrics[0].ScopeMetric)         // test-scope-1-1
+	assert.Equal(t, 1, store.metrics[0].Metric.Gauge().DataPoints().Len())
+	assert.Equal(t, 1.0, store.metrics[0].Metric.Gauge().DataPoints().At(0).DoubleValue()) // dp-1-1-1-1
+	assert.Equal(t, 2.0, store.metrics[1].Metric.Gauge().DataPoints().At(0).DoubleValue()) // dp-1-1-1-2
+	assert.Equal(t, testdata.Metrics[2].Name(), store.metrics[3].Metric.Name())            // metric-2-1-1
+	assert.Equal(t, testdata.RMetrics[1], store.metrics[3].ResourceMetric)                 // test-service-2
+	assert.Equal(t, testdata.SMetrics[2], store.metrics[3].ScopeMetric)                    // test-scope-2-1
+
+	// assert series, one per data point attributes
+	assert.Equal(t, 4, len(store.series))
+	assert.Equal(t, 4, len(store.seriesByKey))
+	assert.Equal(t, "dp index=0", store.series[0].GetAttributesText())
+	assert.Equal(t, "dp index=1", store.series[1].GetAttributesText())
+	assert.Equal(t, []*MetricData{store.metrics[1]}, store.series[1].Points())
 
 	// assert metricsFiltered
-	assert.Equal(t, 3, len(store.metricsFiltered))
-	assert.Equal(t, testdata.Metrics[0], store.metricsFiltered[0].Metric) // metric-1-1-1
-	assert.Equal(t, testdata.Metrics[2], store.metricsFiltered[2].Metric) // metric-2-1-1
+	assert.Equal(t, 4, len(store.metricsFiltered))
+	assert.Equal(t, store.series[0], store.metricsFiltered[0]) // metric-1-1-1
+	assert.Equal(t, store.series[3], store.metricsFiltered[3]) // metric-2-1-1
 
 	// assert cache svcmetric2metrics
 	assert.Equal(t, 2, len(store.metriccache.svcmetric2metrics))
 	assert.Equal(t, 2, len(store.metriccache.svcmetric2metrics["test-service-1"])) // metric-1-1-1, metric-1-2-1
 	assert.Equal(t, 1, len(store.metriccache.svcmetric2metrics["test-service-2"])) // metric-2-1-1
-	assert.Equal(t, testdata.Metrics[1], store.metriccache.svcmetric2metrics["test-service-1"]["metric 0-1"][0].Metric)
+	assert.Equal(t, store.metrics[2], store.metriccache.svcmetric2metrics["test-service-1"]["metric 0-1"][0])
 }
 
 func TestStoreAddMetricWithRotation(t *testing.T) {
@@ -553,7 +563,7 @@ func TestStoreAddMetricWithRotation(t *testing.T) {
 	//      └- metric: metric-2-1-1
 	//        └- datapoint: dp-2-1-1-1
 	store := NewStore(clockwork.NewRealClock())
-	store.maxMetricCount = 1 // no rotation
+	store.maxMetricCount = 1
 	before := store.updatedAt
 	payload, testdata := test.GenerateOTLPGaugeMetricsPayload(t, 2, []int{2, 1}, [][]int{{2, 1}, {1}})
 	store.AddMetric(&payload)
@@ -563,18 +573,67 @@ func TestStoreAddMetricWithRotation(t *testing.T) {
 
 	// assert metrics
 	assert.Equal(t, 1, len(store.metrics))
-	assert.Equal(t, testdata.Metrics[2], store.metrics[0].Metric)          // metric-2-1-1
-	assert.Equal(t, testdata.RMetrics[1], store.metrics[0].ResourceMetric) // test-service-2
-	assert.Equal(t, testdata.SMetrics[2], store.metrics[0].ScopeMetric)    // test-scope-2-1
+	assert.Equal(t, testdata.Metrics[2].Name(), store.metrics[0].Metric.Name()) // metric-2-1-1
+	assert.Equal(t, testdata.RMetrics[1], store.metrics[0].ResourceMetric)      // test-service-2
+	assert.Equal(t, testdata.SMetrics[2], store.metrics[0].ScopeMetric)         // test-scope-2-1
+
+	// assert series
+	assert.Equal(t, 1, len(store.series))
+	assert.Equal(t, 1, len(store.seriesByKey))
 
 	// assert metricsFiltered
 	assert.Equal(t, 1, len(store.metricsFiltered))
-	assert.Equal(t, testdata.Metrics[2], store.metricsFiltered[0].Metric) // metric-2-1-1
+	assert.Equal(t, store.metrics[0], store.metricsFiltered[0].Latest()) // metric-2-1-1
 
 	// assert cache svcmetric2metrics
 	assert.Equal(t, 1, len(store.metriccache.svcmetric2metrics))
 	assert.Equal(t, 1, len(store.metriccache.svcmetric2metrics["test-service-2"])) // metric-2-1-1
-	assert.Equal(t, testdata.Metrics[2], store.metriccache.svcmetric2metrics["test-service-2"]["metric 1-0"][0].Metric)
+	assert.Equal(t, store.metrics[0], store.metriccache.svcmetric2metrics["test-service-2"]["metric 1-0"][0])
+}
+
+func TestStoreAddMetricToSeries(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock())
+	store.maxSeriesPointCount = 2
+
+	for i := range 3 {
+		payload, _ := test.GenerateOTLPGaugeMetricsPayload(t, 1, []int{1}, [][]int{{1}})
+		payload.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0).SetDoubleValue(float64(i))
+		store.AddMetric(&payload)
+	}
+
+	// the same series is updated by every batch and the oldest point is dropped
+	assert.Equal(t, 1, len(store.series))
+	assert.Equal(t, 1, len(store.metricsFiltered))
+	assert.Equal(t, 2, len(store.metrics))
+	ms := store.series[0]
+	assert.Equal(t, store.metrics, ms.Points())
+	assert.Equal(t, 2, ms.Len())
+	assert.Equal(t, "2", ms.GetLastValueText())
+	assert.Equal(t, 2, len(store.metriccache.svcmetric2metrics["test-service-1"]["metric 0-0"]))
+
+	// the series is deleted with its last point
+	store.evictMetrics(2)
+	assert.Equal(t, 0, len(store.series))
+	assert.Equal(t, 0, len(store.seriesByKey))
+	assert.Equal(t, 0, len(store.metricsFiltered))
+}
+
+func TestStoreGetMetricSeries(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock())
+
+	// two instances of the service export the same metric with two data points
+	payload, _ := test.GenerateOTLPGaugeMetricsPayload(t, 1, []int{1}, [][]int{{2}})
+	payload.ResourceMetrics().At(0).Resource().Attributes().PutStr("service.instance.id", "1")
+	other := payload.ResourceMetrics().AppendEmpty()
+	payload.ResourceMetrics().At(0).CopyTo(other)
+	other.Resource().Attributes().PutStr("service.instance.id", "2")
+	store.AddMetric(&payload)
+
+	assert.Equal(t, 4, len(store.series))
+	got := store.GetMetricSeries(store.series[1])
+	assert.Equal(t, []*MetricSeries{store.series[0], store.series[1]}, got)
+	got = store.GetMetricSeries(store.series[2])
+	assert.Equal(t, []*MetricSeries{store.series[2], store.series[3]}, got)
 }
 
 func TestStoreAddLogWithoutRotation(t *testing.T) {
@@ -745,10 +804,137 @@ func TestStoreFlush(t *testing.T) {
 
 	// assert metrics
 	assert.Equal(t, 0, len(store.metrics))
+	assert.Equal(t, 0, len(store.series))
+	assert.Equal(t, 0, len(store.seriesByKey))
 	assert.Equal(t, 0, len(store.metricsFiltered))
 	assert.Equal(t, 0, len(store.metriccache.svcmetric2metrics))
 }
 
+func TestNewStoreWithOptions(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		store := NewStore(clockwork.NewRealClock())
+		assert.Equal(t, MAX_SERVICE_SPAN_COUNT, store.maxServiceSpanCount)
+		assert.Equal(t, MAX_METRIC_COUNT, store.maxMetricCount)
+		assert.Equal(t, MAX_LOG_COUNT, store.maxLogCount)
+		assert.Equal(t, int64(0), store.maxMemoryBytes)
+	})
+	t.Run("with options", func(t *testing.T) {
+		store := NewStore(
+			clockwork.NewRealClock(),
+			WithMaxServiceSpanCount(10),
+			WithMaxMetricCount(20),
+			WithMaxLogCount(30),
+			WithMaxMemoryBytes(1024),
+		)
+		assert.Equal(t, 10, store.maxServiceSpanCount)
+		assert.Equal(t, 20, store.maxMetricCount)
+		assert.Equal(t, 30, store.maxLogCount)
+		assert.Equal(t, int64(1024), store.maxMemoryBytes)
+	})
+	t.Run("non-positive values are ignored", func(t *testing.T) {
+		store := NewStore(
+			clockwork.NewRealClock(),
+			WithMaxServiceSpanCount(0),
+			WithMaxMetricCount(-1),
+			WithMaxLogCount(0),
+			WithMaxMemoryBytes(-1),
+		)
+		assert.Equal(t, MAX_SERVICE_SPAN_COUNT, store.maxServiceSpanCount)
+		assert.Equal(t, MAX_METRIC_COUNT, store.maxMetricCount)
+		assert.Equal(t, MAX_LOG_COUNT, store.maxLogCount)
+		assert.Equal(t, int64(0), store.maxMemoryBytes)
+	})
+}
+
+func TestStoreMemoryLimit(t *testing.T) {
+	tp, _ := test.GenerateOTLPTracesPayload(t, 1, 2, []int{2, 1}, [][]int{{2, 1}, {1}})
+	mp, _ := test.GenerateOTLPGaugeMetricsPayload(t, 2, []int{2, 1}, [][]int{{2, 1}, {1}})
+	lp, _ := test.GenerateOTLPLogsPayload(t, 1, 2, []int{2, 1}, [][]int{{2, 1}, {1}})
+
+	// measure the estimated size of each signal without a budget
+	var spanBytes, metricBytes, logBytes int64
+	{
+		store := NewStore(clockwork.NewRealClock())
+		store.AddSpan(&tp)
+		spanBytes = store.memoryBytes
+		store.AddMetric(&mp)
+		metricBytes = store.memoryBytes - spanBytes
+		store.AddLog(&lp)
+		logBytes = store.memoryBytes - spanBytes - metricBytes
+		assert.Positive(t, spanBytes)
+		assert.Positive(t, metricBytes)
+		assert.Positive(t, logBytes)
+
+		store.Flush()
+		assert.Equal(t, int64(0), store.memoryBytes)
+	}
+
+	t.Run("oldest signal is evicted first", func(t *testing.T) {
+		clock := clockwork.NewFakeClock()
+		store := NewStore(clock, WithMaxMemoryBytes(metricBytes+logBytes))
+		store.AddSpan(&tp)
+		clock.Advance(time.Second)
+		store.AddMetric(&mp)
+		clock.Advance(time.Second)
+		store.AddLog(&lp)
+
+		assert.Equal(t, 0, len(store.svcspans))
+		assert.Equal(t, 0, len(store.svcspansFiltered))
+		assert.Equal(t, 0, len(store.tracecache.tracesvc2spans))
+		assert.Equal(t, 4, len(store.metrics))
+		assert.Equal(t, 8, len(store.logs))
+		assert.Equal(t, metricBytes+logBytes, store.memoryBytes)
+	})
+
+	t.Run("the evicted signals are notified", func(t *testing.T) {
+		clock := clockwork.NewFakeClock()
+		store := NewStore(clock, WithMaxMemoryBytes(metricBytes+logBytes))
+		store.AddSpan(&tp)
+		clock.Advance(time.Second)
+		store.AddMetric(&mp)
+		clock.Advance(time.Second)
+
+		var spans, metrics, logs int
+		store.SetOnSpanAdded(func() { spans++ })
+		store.SetOnMetricAdded(func() { metrics++ })
+		store.SetOnLogAdded(func() { logs++ })
+		store.AddLog(&lp)
+
+		assert.Equal(t, 0, len(store.svcspans))
+		assert.Equal(t, 1, spans)
+		assert.Equal(t, 0, metrics)
+		assert.Equal(t, 1, logs)
+	})
+
+	t.Run("evicts across signals in received order", func(t *testing.T) {
+		clock := clockwork.NewFakeClock()
+		store := NewStore(clock, WithMaxMemoryBytes(logBytes))
+		store.AddMetric(&mp)
+		clock.Advance(time.Second)
+		store.AddSpan(&tp)
+		clock.Advance(time.Second)
+		store.AddLog(&lp)
+
+		assert.Equal(t, 0, len(store.metrics))
+		assert.Equal(t, 0, len(store.series))
+		assert.Equal(t, 0, len(store.metricsFiltered))
+		assert.Equal(t, 0, len(store.svcspans))
+		assert.Equal(t, 8, len(store.logs))
+		assert.Equal(t, 8, len(store.logsFiltered))
+		assert.LessOrEqual(t, store.memoryBytes, logBytes)
+	})
+
+	t.Run("partial eviction", func(t *testing.T) {
+		clock := clockwork.NewFakeClock()
+		store := NewStore(clock, WithMaxMemoryBytes(logBytes-1))
+		store.AddLog(&lp)
+
+		assert.Equal(t, 7, len(store.logs))
+		assert.Equal(t, 7, len(store.logsFiltered))
+		assert.LessOrEqual(t, store.memoryBytes, logBytes-1)
+	})
+}
+
 func TestLogDataGetResolvedBody(t *testing.T) {
 	l, _ := test.GenerateOTLPLogsPayload(t, 1, 1, []int{1}, [][]int{{1}})
 	lr := l.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
@@ -765,3 +951,203 @@ func TestLogDataGetResolvedBody(t *testing.T) {
 
 	assert.Equal(t, want, ld.GetResolvedBody())
 }
+
+// testSpan describes a span of a payload generated by generateTestTraces
+type testSpan struct {
+	traceID    int
+	spanNo     int
+	parentNo   int
+	svc        string
+	name       string
+	offset     time.Duration
+	duration   time.Duration
+	statusCode int64
+	hasError   bool
+}
+
+func generateTestTraces(spans ...testSpan) ptrace.Traces {
+	traces := ptrace.NewTraces()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, ts := range spans {
+		rs := traces.ResourceSpans().AppendEmpty()
+		rs.Resource().Attributes().PutStr("service.name", ts.svc)
+		span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+		span.SetName(fmt.Sprintf("span-%d-%d", ts.traceID, ts.spanNo))
+		if ts.name != "" {
+			span.SetName(ts.name)
+		}
+		span.SetTraceID(testTraceID(ts.traceID))
+		span.SetSpanID(testSpanID(ts.traceID, ts.spanNo))
+		if ts.parentNo > 0 {
+			span.SetParentSpanID(testSpanID(ts.traceID, ts.parentNo))
+		}
+		span.SetStartTimestamp(pcommon.NewTimestampFromTime(start.Add(ts.offset)))
+		span.SetEndTimestamp(pcommon.NewTimestampFromTime(start.Add(ts.offset + ts.duration)))
+		span.Attributes().PutInt("http.response.status_code", ts.statusCode)
+		if ts.hasError {
+			span.Status().SetCode(ptrace.StatusCodeError)
+		}
+	}
+	return traces
+}
+
+func testTraceID(traceID int) pcommon.TraceID {
+	var id pcommon.TraceID
+	binary.BigEndian.PutUint32(id[:], uint32(traceID)) // #nosec G115
+	return id
+}
+
+func testSpanID(traceID, spanNo int) pcommon.SpanID {
+	var id pcommon.SpanID
+	binary.BigEndian.PutUint32(id[:], uint32(traceID)) // #nosec G115
+	id[4] = byte(spanNo)                               // #nosec G115
+	return id
+}
+
+// generateIncrementalTestBatches generates batches in which the child span of a trace arrives
+// first and its parent span arrives in the next batch, which replaces the service root span.
+func generateIncrementalTestBatches(n int) []ptrace.Traces {
+	child := func(i int) testSpan {
+		statusCode := int64(200)
+		if i%5 == 0 {
+			statusCode = 500
+		}
+		return testSpan{
+			traceID:    i,
+			spanNo:     2,
+			parentNo:   1,
+			svc:        fmt.Sprintf("svc-%d", i%3),
+			duration:   time.Duration(i) * time.Millisecond,
+			statusCode: statusCode,
+			hasError:   i%4 == 0,
+		}
+	}
+	root := func(i int) testSpan {
+		// distinct durations not to depend on the order of ties
+		return testSpan{
+			traceID:    i,
+			spanNo:     1,
+			svc:        fmt.Sprintf("svc-%d", i%3),
+			duration:   time.Duration(1000+(i*37)%101) * time.Millisecond,
+			statusCode: 200,
+		}
+	}
+
+	batches := make([]ptrace.Traces, 0, n)
+	for i := 1; i <= n; i++ {
+		spans := []testSpan{child(i)}
+		if i > 1 {
+			spans = append(spans, root(i-1))
+		}
+		batches = append(batches, generateTestTraces(spans...))
+	}
+	return batches
+}
+
+func svcSpanNames(svcSpans SvcSpans) []string {
+	names := make([]string, 0, len(svcSpans))
+	for _, sd := range svcSpans {
+		names = append(names, sd.GetServiceName()+"/"+sd.Span.Name())
+	}
+	return names
+}
+
+func TestStoreIncrementalTraceFilter(t *testing.T) {
+	queries := []string{
+		"",
+		"svc-1",
+		"status=error",
+		"status!=error span-1",
+		"http.response.status_code>=500",
+		"duration>1020ms",
+	}
+	sortTypes := []SortType{SORT_TYPE_NONE, SORT_TYPE_LATENCY_DESC, SORT_TYPE_LATENCY_ASC}
+	batches := generateIncrementalTestBatches(40)
+
+	for _, sortType := range sortTypes {
+		for _, q := range queries {
+			t.Run(fmt.Sprintf("%s %q", sortType, q), func(t *testing.T) {
+				store := NewStore(clockwork.NewRealClock(), WithMaxServiceSpanCount(20))
+				require.NoError(t, store.ApplyFilterTraces(q, sortType))
+
+				for i := range batches {
+					store.AddSpan(&batches[i])
+					got := svcSpanNames(store.svcspansFiltered)
+
+					// compare with the full recompute
+					require.NoError(t, store.ApplyFilterTraces(q, sortType))
+					assert.Equal(t, svcSpanNames(store.svcspansFiltered), got, "batch %d", i)
+				}
+			})
+		}
+	}
+}
+
+func TestStoreFilterKeepsSvcSpansOrder(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock())
+	store.AddSpan(ptr(generateTestTraces(
+		testSpan{traceID: 1, spanNo: 1, svc: "svc", duration: 10 * time.Millisecond},
+		testSpan{traceID: 2, spanNo: 1, svc: "svc", duration: 30 * time.Millisecond},
+		testSpan{traceID: 3, spanNo: 1, svc: "svc", duration: 20 * time.Millisecond},
+	)))
+
+	require.NoError(t, store.ApplyFilterTraces("", SORT_TYPE_LATENCY_DESC))
+	assert.Equal(t, []string{"svc/span-2-1", "svc/span-3-1", "svc/span-1-1"}, svcSpanNames(store.svcspansFiltered))
+	// the service spans are still in received order to evict the oldest ones
+	assert.Equal(t, []string{"svc/span-1-1", "svc/span-2-1", "svc/span-3-1"}, svcSpanNames(store.svcspans))
+
+	store.AddSpan(ptr(generateTestTraces(
+		testSpan{traceID: 4, spanNo: 1, svc: "svc", duration: 25 * time.Millisecond},
+	)))
+	assert.Equal(t, []string{"svc/span-2-1", "svc/span-4-1", "svc/span-3-1", "svc/span-1-1"}, svcSpanNames(store.svcspansFiltered))
+}
+
+func TestStoreIncrementalMetricAndLogFilter(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock(), WithMaxMetricCount(5), WithMaxLogCount(5))
+	store.ApplyFilterMetrics("service-2")
+	require.NoError(t, store.ApplyFilterLogs("severity>=info service-1"))
+
+	for range 4 {
+		metrics, _ := test.GenerateOTLPGaugeMetricsPayload(t, 2, []int{1, 1}, [][]int{{1}, {1}})
+		store.AddMetric(&metrics)
+		logs, _ := test.GenerateOTLPLogsPayload(t, 1, 2, []int{1, 1}, [][]int{{1}, {1}})
+		store.AddLog(&logs)
+
+		gotMetrics := append([]*MetricSeries{}, store.metricsFiltered...)
+		gotLogs := append([]*LogData{}, store.logsFiltered...)
+
+		// compare with the full recompute
+		store.ApplyFilterMetrics("service-2")
+		require.NoError(t, store.ApplyFilterLogs("severity>=info service-1"))
+		assert.Equal(t, store.metricsFiltered, gotMetrics)
+		assert.Equal(t, store.logsFiltered, gotLogs)
+	}
+	// the metrics of service-2 are the points of a single series
+	assert.Equal(t, 1, len(store.metricsFiltered))
+	assert.Equal(t, 2, len(store.logsFiltered))
+}
+
+func TestRemoveItems(t *testing.T) {
+	tests := []struct {
+		name   string
+		items  []int
+		remove []int
+		want   []int
+	}{
+		{name: "nothing", items: []int{1, 2, 3}, remove: []int{}, want: []int{1, 2, 3}},
+		{name: "head", items: []int{1, 2, 3}, remove: []int{2, 1}, want: []int{3}},
+		{name: "middle", items: []int{1, 2, 3, 4}, remove: []int{1, 3}, want: []int{2, 4}},
+		{name: "not included", items: []int{1, 2, 3}, remove: []int{1, 5}, want: []int{2, 3}},
+		{name: "all", items: []int{1, 2, 3}, remove: []int{1, 2, 3}, want: []int{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, removeItems(tt.items, toSet(tt.remove)))
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,191 @@
+package telemetry
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/query"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+const (
+	TRACE_QUERY_KEY_DURATION = "duration"
+	TRACE_QUERY_KEY_STATUS   = "status"
+	TRACE_QUERY_KEY_KIND     = "kind"
+	TRACE_QUERY_KEY_NAME     = "name"
+)
+
+var traceQueryStatusCodes = map[string]ptrace.StatusCode{
+	"unset": ptrace.StatusCodeUnset,
+	"ok":    ptrace.StatusCodeOk,
+	"error": ptrace.StatusCodeError,
+}
+
+var traceQuerySpanKinds = map[string]ptrace.SpanKind{
+	"unspecified": ptrace.SpanKindUnspecified,
+	"internal":    ptrace.SpanKindInternal,
+	"server":      ptrace.SpanKindServer,
+	"client":      ptrace.SpanKindClient,
+	"producer":    ptrace.SpanKindProducer,
+	"consumer":    ptrace.SpanKindConsumer,
+}
+
+// spanTerm is a compiled term evaluated against each span of a row.
+// A positive term matches if any span satisfies it, and a negative
+// term (!= or !~) matches only if all spans satisfy it.
+type spanTerm struct {
+	match    func(sd *SpanData) bool
+	negative bool
+}
+
+// traceQuery is a compiled query for the trace table.
+// Free texts and duration are evaluated against the row (service root span) and the
+// other terms are evaluated against the spans of the service in the trace.
+type traceQuery struct {
+	rowTerms  []func(row *SpanData) bool
+	spanTerms []spanTerm
+}
+
+func compileTraceQuery(input string) (*traceQuery, error) {
+	q, err := query.Parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	tq := &traceQuery{}
+	for _, term := range q.Terms {
+		switch {
+		case term.IsText():
+			tq.rowTerms = append(tq.rowTerms, func(row *SpanData) bool {
+				return term.MatchString(row.GetServiceName() + " " + row.Span.Name())
+			})
+		case term.Key == TRACE_QUERY_KEY_DURATION:
+			f, err := compileDurationTerm(term)
+			if err != nil {
+				return nil, err
+			}
+			tq.rowTerms = append(tq.rowTerms, f)
+		default:
+			f, err := compileSpanTerm(term)
+			if err != nil {
+				return nil, err
+			}
+			tq.spanTerms = append(tq.spanTerms, spanTerm{
+				match:    f,
+				negative: isNegativeOp(term.Op),
+			})
+		}
+	}
+
+	return tq, nil
+}
+
+func compileDurationTerm(term *query.Term) (func(row *SpanData) bool, error) {
+	if term.Op.IsRegex() {
+		return nil, term.Errorf("operator '%s' is not supported for %s", term.Op, term.Key)
+	}
+	want, err := time.ParseDuration(term.Value)
+	if err != nil {
+		return nil, term.Errorf("invalid duration %q", term.Value)
+	}
+
+	return func(row *SpanData) bool {
+		got := row.Span.EndTimestamp().AsTime().Sub(row.Span.StartTimestamp().AsTime())
+		switch {
+		case got < want:
+			return term.Compare(-1)
+		case got > want:
+			return term.Compare(1)
+		}
+		return term.Compare(0)
+	}, nil
+}
+
+func compileSpanTerm(term *query.Term) (func(sd *SpanData) bool, error) {
+	switch term.Key {
+	case TRACE_QUERY_KEY_STATUS:
+		if term.Op != query.OP_EQ && term.Op != query.OP_NE {
+			return nil, term.Errorf("operator '%s' is not supported for %s", term.Op, term.Key)
+		}
+		code, ok := traceQueryStatusCodes[strings.ToLower(term.Value)]
+		if !ok {
+			return nil, term.Errorf("invalid status %q, must be one of unset, ok or error", term.Value)
+		}
+		return func(sd *SpanData) bool {
+			return (sd.Span.Status().Code() == code) == (term.Op == query.OP_EQ)
+		}, nil
+	case TRACE_QUERY_KEY_KIND:
+		if term.Op.IsOrdering() {
+			return nil, term.Errorf("operator '%s' is not supported for %s", term.Op, term.Key)
+		}
+		if term.Op.IsRegex() {
+			return func(sd *SpanData) bool {
+				return term.MatchString(sd.Span.Kind().String())
+			}, nil
+		}
+		kind, ok := traceQuerySpanKinds[strings.ToLower(term.Value)]
+		if !ok {
+			return nil, term.Errorf("invalid kind %q, must be one of unspecified, internal, server, client, producer or consumer", term.Value)
+		}
+		return func(sd *SpanData) bool {
+			return (sd.Span.Kind() == kind) == (term.Op == query.OP_EQ)
+		}, nil
+	case TRACE_QUERY_KEY_NAME:
+		return func(sd *SpanData) bool {
+			return term.MatchString(sd.Span.Name())
+		}, nil
+	}
+
+	return func(sd *SpanData) bool {
+		sources := []attributeSource{
+			{prefix: "span.", attrs: sd.Span.Attributes()},
+		}
+		if sd.ScopeSpans != nil {
+			sources = append(sources, attributeSource{prefix: "scope.", attrs: sd.ScopeSpans.Scope().Attributes()})
+		}
+		sources = append(sources, attributeSource{prefix: "resource.", attrs: sd.ResourceSpan.Resource().Attributes()})
+		return matchAttribute(term, sources...)
+	}, nil
+}
+
+func (q *traceQuery) isEmpty() bool {
+	return len(q.rowTerms) == 0 && len(q.spanTerms) == 0
+}
+
+// hasSpanTerms returns true if the result can be changed by the spans added to the row
+func (q *traceQuery) hasSpanTerms() bool {
+	return len(q.spanTerms) > 0
+}
+
+// match returns whether the row satisfies all terms. The spans of the service in the trace
+// are looked up from the cache only when needed.
+func (q *traceQuery) match(row *SpanData, cache *TraceCache) bool {
+	for _, f := range q.rowTerms {
+		if !f(row) {
+			return false
+		}
+	}
+	if len(q.spanTerms) == 0 {
+		return true
+	}
+
+	spans, ok := cache.GetSpansByTraceIDAndSvc(row.Span.TraceID().String(), row.GetServiceName())
+	if !ok {
+		spans = []*SpanData{row}
+	}
+	for _, t := range q.spanTerms {
+		if !matchSpans(t, spans) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchSpans(t spanTerm, spans []*SpanData) bool {
+	for _, sd := range spans {
+		if t.match(sd) != t.negative {
+			return !t.negative
+		}
+	}
+	return t.negative
+}
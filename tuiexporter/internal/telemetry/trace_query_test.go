@@ -0,0 +1,111 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/test"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestStoreApplyFilterTracesQuery(t *testing.T) {
+	// traceid: 1
+	//  └- resource: test-service-1
+	//  | └- scope: test-scope-1-1
+	//  | | └- span: span-0-0-0 (row)
+	//  | | └- span: span-0-0-1 (http.response.status_code: 500, status: error)
+	//  | └- scope: test-scope-1-2
+	//  |   └- span: span-0-1-0
+	//  └- resource: test-service-2
+	//    └- scope: test-scope-2-1
+	//      └- span: span-1-0-0 (row, kind: server, duration: 500ms)
+	store := NewStore(clockwork.NewRealClock())
+	payload, testdata := test.GenerateOTLPTracesPayload(t, 1, 2, []int{2, 1}, [][]int{{2, 1}, {1}})
+	testdata.Spans[1].Attributes().PutInt("http.response.status_code", 500)
+	testdata.Spans[1].Status().SetCode(ptrace.StatusCodeError)
+	testdata.Spans[3].SetKind(ptrace.SpanKindServer)
+	testdata.Spans[3].SetEndTimestamp(pcommon.NewTimestampFromTime(
+		testdata.Spans[3].StartTimestamp().AsTime().Add(500 * time.Millisecond),
+	))
+	store.AddSpan(&payload)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "empty", query: "", want: []string{"span-0-0-0", "span-1-0-0"}},
+		{name: "free text", query: "service-2", want: []string{"span-1-0-0"}},
+		{name: "multiple free texts", query: "test-service span-0", want: []string{"span-0-0-0"}},
+		{name: "resource attribute", query: "service.name=test-service-1", want: []string{"span-0-0-0"}},
+		{name: "prefixed resource attribute", query: "resource.service.name=test-service-2", want: []string{"span-1-0-0"}},
+		{name: "span attribute in any span", query: "http.response.status_code>=500", want: []string{"span-0-0-0"}},
+		{name: "prefixed span attribute", query: "span.http.response.status_code=500", want: []string{"span-0-0-0"}},
+		{name: "numeric attribute not matched", query: "http.response.status_code<500", want: []string{}},
+		{name: "missing attribute equal", query: "missing=foo", want: []string{}},
+		{name: "missing attribute not equal", query: "missing!=foo", want: []string{"span-0-0-0", "span-1-0-0"}},
+		{name: "status error", query: "status=error", want: []string{"span-0-0-0"}},
+		{name: "status not error", query: "status!=ERROR", want: []string{"span-1-0-0"}},
+		{name: "duration", query: "duration>250ms", want: []string{"span-1-0-0"}},
+		{name: "duration and status", query: "duration<=200ms status=ok", want: []string{"span-0-0-0"}},
+		{name: "kind", query: "kind=server", want: []string{"span-1-0-0"}},
+		{name: "kind not", query: "kind!=Server", want: []string{"span-0-0-0"}},
+		{name: "kind regex", query: "kind~^Int", want: []string{"span-0-0-0"}},
+		{name: "name in any span", query: `name="span-0-1-0"`, want: []string{"span-0-0-0"}},
+		{name: "name not matched in all spans", query: "name!~^span-0", want: []string{"span-1-0-0"}},
+		{
+			name:  "combined",
+			query: "service.name=test-service-1 http.response.status_code>=500 duration>100ms status=error",
+			want:  []string{"span-0-0-0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, store.ApplyFilterTraces(tt.query, SORT_TYPE_NONE))
+
+			got := []string{}
+			for _, sd := range store.svcspansFiltered {
+				got = append(got, sd.Span.Name())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStoreApplyFilterTracesQueryError(t *testing.T) {
+	store := NewStore(clockwork.NewRealClock())
+	payload, _ := test.GenerateOTLPTracesPayload(t, 1, 2, []int{1, 1}, [][]int{{1}, {1}})
+	store.AddSpan(&payload)
+	require.NoError(t, store.ApplyFilterTraces("service-2", SORT_TYPE_NONE))
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "syntax error", query: "status=", want: "missing value after '=' at column 7"},
+		{name: "invalid duration", query: "duration>fast", want: `invalid duration "fast" at column 1`},
+		{name: "regex for duration", query: "a=b duration~1s", want: "operator '~' is not supported for duration at column 5"},
+		{name: "invalid status", query: "status=failed", want: `invalid status "failed", must be one of unset, ok or error at column 1`},
+		{name: "ordering for status", query: "status>ok", want: "operator '>' is not supported for status at column 1"},
+		{name: "invalid kind", query: "kind=web", want: `invalid kind "web", must be one of unspecified, internal, server, client, producer or consumer at column 1`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := store.ApplyFilterTraces(tt.query, SORT_TYPE_LATENCY_DESC)
+			require.Error(t, err)
+			assert.Equal(t, tt.want, err.Error())
+
+			// the current filter is kept
+			assert.Equal(t, "service-2", store.filterSvc)
+			assert.Equal(t, SORT_TYPE_NONE, store.sortTrace)
+			assert.Equal(t, 1, len(store.svcspansFiltered))
+		})
+	}
+}
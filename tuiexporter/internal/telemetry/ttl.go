@@ -0,0 +1,143 @@
+package telemetry
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ttlSweepInterval is the interval to check expired data in the store
+const ttlSweepInterval = 1 * time.Second
+
+// WithTTL sets the time to live of the retained telemetry. Data received before
+// the TTL is deleted by the sweeper started with StartTTLSweeper.
+// Non-positive values disable the TTL.
+func WithTTL(ttl time.Duration) StoreOption {
+	return func(s *Store) {
+		if ttl > 0 {
+			s.ttl = ttl
+		}
+	}
+}
+
+// StartTTLSweeper starts a background goroutine which periodically deletes
+// expired data from the store. It does nothing when the TTL is not set.
+// The returned function stops the sweeper.
+func (s *Store) StartTTLSweeper() (stop func()) {
+	if s.ttl <= 0 {
+		return func() {}
+	}
+
+	ticker := s.clockwork.NewTicker(ttlSweepInterval)
+	done := make(chan struct{})
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.Chan():
+				s.deleteExpired()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+		})
+	}
+}
+
+// deleteExpired deletes the spans, metrics and logs received before the TTL
+// from the store and the caches
+func (s *Store) deleteExpired() {
+	s.mut.Lock()
+	callbacks := s.deleteExpiredLocked()
+	s.mut.Unlock()
+
+	// the callbacks can read the store after the lock is released
+	for _, f := range callbacks {
+		notify(f)
+	}
+}
+
+// deleteExpiredLocked deletes the expired data and returns the callbacks of the signals
+// which have been changed. The caller must hold s.mut.
+func (s *Store) deleteExpiredLocked() []func() {
+	var callbacks []func()
+
+	now := s.clockwork.Now()
+	expiredAt := now.Add(-s.ttl)
+
+	// Service spans are not ordered by received time because the service root span
+	// can be replaced by a later one. The latest span in the service decides the expiration.
+	// Pinned traces never expire.
+	var (
+		keepSpans    = SvcSpans{}
+		expiredSpans = SvcSpans{}
+	)
+	for _, ss := range s.svcspans {
+		if !s.isPinned(ss) && s.svcSpanReceivedAt(ss).Before(expiredAt) {
+			expiredSpans = append(expiredSpans, ss)
+		} else {
+			keepSpans = append(keepSpans, ss)
+		}
+	}
+
+	// metrics and logs are appended in received order
+	nmetrics := sort.Search(len(s.metrics), func(i int) bool {
+		return !s.metrics[i].ReceivedAt.Before(expiredAt)
+	})
+	nlogs := sort.Search(len(s.logs), func(i int) bool {
+		return !s.logs[i].ReceivedAt.Before(expiredAt)
+	})
+
+	if len(expiredSpans) == 0 && nmetrics == 0 && nlogs == 0 {
+		return nil
+	}
+
+	if len(expiredSpans) > 0 {
+		for _, ss := range expiredSpans {
+			s.memoryBytes -= s.svcSpanSize(ss)
+		}
+		s.tracecache.DeleteCache(expiredSpans)
+		s.svcspans = keepSpans
+		s.svcspansFiltered = removeItems(s.svcspansFiltered, toSet(expiredSpans))
+		callbacks = append(callbacks, s.onSpanAdded)
+	}
+	if nmetrics > 0 {
+		s.evictMetrics(nmetrics)
+		callbacks = append(callbacks, s.onMetricAdded)
+	}
+	if nlogs > 0 {
+		s.evictLogs(nlogs)
+		callbacks = append(callbacks, s.onLogAdded)
+	}
+
+	s.markUpdatedLocked()
+
+	// Clear the detail views as well when all data is gone
+	if len(s.svcspans) == 0 && len(s.metrics) == 0 && len(s.logs) == 0 {
+		callbacks = append(callbacks, s.onFlushed...)
+	}
+
+	return callbacks
+}
+
+// svcSpanReceivedAt returns the latest received time of the spans belonging to the service span
+func (s *Store) svcSpanReceivedAt(ss *SpanData) time.Time {
+	spans, ok := s.tracecache.GetSpansByTraceIDAndSvc(ss.Span.TraceID().String(), ss.GetServiceName())
+	if !ok {
+		return ss.ReceivedAt
+	}
+	receivedAt := ss.ReceivedAt
+	for _, span := range spans {
+		if span.ReceivedAt.After(receivedAt) {
+			receivedAt = span.ReceivedAt
+		}
+	}
+	return receivedAt
+}
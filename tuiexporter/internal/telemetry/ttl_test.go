@@ -0,0 +1,140 @@
+package telemetry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/test"
+)
+
+func TestStoreDeleteExpired(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	store := NewStore(clock, WithTTL(45*time.Second))
+
+	var spanUpdated, metricUpdated, logUpdated, flushed int
+	// the callbacks read the store as the tables do, which must not deadlock
+	store.SetOnSpanAdded(func() { spanUpdated++; store.SnapshotSvcSpans() })
+	store.SetOnMetricAdded(func() { metricUpdated++; store.SnapshotMetrics() })
+	store.SetOnLogAdded(func() { logUpdated++; store.SnapshotLogs() })
+	store.RegisterOnFlushed(func() { flushed++ })
+
+	tp, _ := test.GenerateOTLPTracesPayload(t, 1, 2, []int{2, 1}, [][]int{{2, 1}, {1}})
+	mp, gm := test.GenerateOTLPGaugeMetricsPayload(t, 2, []int{2, 1}, [][]int{{2, 1}, {1}})
+	lp, gl := test.GenerateOTLPLogsPayload(t, 1, 2, []int{2, 1}, [][]int{{2, 1}, {1}})
+	// a point per data point
+	wantMetrics := 0
+	for _, m := range gm.Metrics {
+		wantMetrics += m.Gauge().DataPoints().Len()
+	}
+
+	// spans: 0s, metrics: 20s, logs: 40s
+	store.AddSpan(&tp)
+	clock.Advance(20 * time.Second)
+	store.AddMetric(&mp)
+	clock.Advance(20 * time.Second)
+	store.AddLog(&lp)
+	spanUpdated, metricUpdated, logUpdated = 0, 0, 0
+
+	t.Run("nothing expired", func(t *testing.T) {
+		before := store.updatedAt
+		store.deleteExpired()
+
+		assert.Equal(t, 2, len(store.svcspans))
+		assert.Equal(t, wantMetrics, len(store.metrics))
+		assert.Equal(t, len(gl.Logs), len(store.logs))
+		assert.Equal(t, before, store.updatedAt)
+		assert.Equal(t, 0, spanUpdated+metricUpdated+logUpdated+flushed)
+	})
+
+	t.Run("spans and metrics expired", func(t *testing.T) {
+		// spans: 70s, metrics: 50s, logs: 30s
+		clock.Advance(30 * time.Second)
+		store.deleteExpired()
+
+		assert.Equal(t, 0, len(store.svcspans))
+		assert.Equal(t, 0, len(store.svcspansFiltered))
+		assert.Equal(t, 0, len(store.tracecache.spanid2span))
+		assert.Equal(t, 0, len(store.tracecache.tracesvc2spans))
+		assert.Equal(t, 0, len(store.metrics))
+		assert.Equal(t, 0, len(store.series))
+		assert.Equal(t, 0, len(store.metricsFiltered))
+		assert.Equal(t, 0, len(store.metriccache.svcmetric2metrics))
+		assert.Equal(t, len(gl.Logs), len(store.logs))
+		assert.Equal(t, len(gl.Logs), len(store.logsFiltered))
+		assert.Equal(t, clock.Now(), store.updatedAt)
+		assert.Equal(t, 1, spanUpdated)
+		assert.Equal(t, 1, metricUpdated)
+		assert.Equal(t, 0, logUpdated)
+		assert.Equal(t, 0, flushed)
+	})
+
+	t.Run("all expired", func(t *testing.T) {
+		clock.Advance(30 * time.Second)
+		store.deleteExpired()
+
+		assert.Equal(t, 0, len(store.logs))
+		assert.Equal(t, 0, len(store.logsFiltered))
+		assert.Equal(t, int64(0), store.memoryBytes)
+		assert.Equal(t, 1, logUpdated)
+		assert.Equal(t, 1, flushed)
+	})
+}
+
+func TestStoreDeleteExpiredKeepsUpdatedService(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	store := NewStore(clock, WithTTL(time.Minute))
+
+	// the spans in the same trace and service arrive at different times
+	tp1, _ := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{1}})
+	tp2, _ := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{2}})
+	store.AddSpan(&tp1)
+	clock.Advance(50 * time.Second)
+	store.AddSpan(&tp2)
+	clock.Advance(20 * time.Second)
+
+	store.deleteExpired()
+
+	assert.Equal(t, 1, len(store.svcspans))
+	spans, ok := store.tracecache.GetSpansByTraceIDAndSvc(store.svcspans[0].Span.TraceID().String(), "test-service-1")
+	assert.True(t, ok)
+	assert.Equal(t, 3, len(spans))
+}
+
+func TestStoreStartTTLSweeper(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		clock := clockwork.NewFakeClock()
+		store := NewStore(clock)
+		stop := store.StartTTLSweeper()
+		defer stop()
+
+		lp, gl := test.GenerateOTLPLogsPayload(t, 1, 1, []int{1}, [][]int{{1}})
+		store.AddLog(&lp)
+		clock.Advance(time.Hour)
+
+		assert.Equal(t, len(gl.Logs), len(store.logs))
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		clock := clockwork.NewFakeClock()
+		store := NewStore(clock, WithTTL(time.Minute))
+		stop := store.StartTTLSweeper()
+		defer stop()
+
+		lp, _ := test.GenerateOTLPLogsPayload(t, 1, 1, []int{1}, [][]int{{1}})
+		store.AddLog(&lp)
+
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute + ttlSweepInterval)
+
+		assert.Eventually(t, func() bool {
+			store.mut.Lock()
+			defer store.mut.Unlock()
+			return len(store.logs) == 0
+		}, time.Second, 10*time.Millisecond)
+
+		// stopping twice is safe
+		stop()
+	})
+}
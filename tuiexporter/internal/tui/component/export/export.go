@@ -0,0 +1,51 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/rivo/tview"
+)
+
+// SaveFunc writes telemetry to the writer and returns the number of written items
+type SaveFunc func(w io.Writer) (int, error)
+
+// SaveToFile writes telemetry to a new OTLP JSON file in the current directory, which can be
+// opened again with `otel-tui --from-json-file`. It returns an empty path if there is nothing to save.
+func SaveToFile(signal string, now time.Time, save SaveFunc) (string, int, error) {
+	var buf bytes.Buffer
+	count, err := save(&buf)
+	if err != nil {
+		return "", 0, err
+	}
+	if count == 0 {
+		return "", 0, nil
+	}
+
+	path := fmt.Sprintf("otel-tui-%s-%s.json", signal, now.Format("20060102-150405"))
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return "", 0, err
+	}
+
+	return path, count, nil
+}
+
+// SaveAndNotify writes telemetry to a new file and shows the result in the command list.
+// The message disappears when the focus moves.
+func SaveAndNotify(commands *tview.TextView, signal, unit string, save SaveFunc) {
+	path, count, err := SaveToFile(signal, time.Now(), save)
+	switch {
+	case err != nil:
+		log.Printf("Failed to save %s: %v", signal, err)
+		commands.SetText(fmt.Sprintf(" [red]Failed to save %s: %v", signal, err))
+	case count == 0:
+		commands.SetText(fmt.Sprintf(" [yellow]No %s to save", signal))
+	default:
+		log.Printf("Saved %d %s to %s", count, unit, path)
+		commands.SetText(fmt.Sprintf(" [green]Saved %d %s to %s", count, unit, path))
+	}
+}
@@ -0,0 +1,81 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/layout"
+)
+
+func TestSaveToFile(t *testing.T) {
+	now := time.Date(2025, 11, 9, 12, 15, 0, 0, time.UTC)
+
+	t.Run("saved", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+
+		path, count, err := SaveToFile("traces", now, func(w io.Writer) (int, error) {
+			_, err := fmt.Fprintln(w, `{"resourceSpans":[]}`)
+			return 2, err
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "otel-tui-traces-20251109-121500.json", path)
+		assert.Equal(t, 2, count)
+
+		got, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "{\"resourceSpans\":[]}\n", string(got))
+	})
+
+	t.Run("nothing to save", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Chdir(dir)
+
+		path, count, err := SaveToFile("logs", now, func(_ io.Writer) (int, error) {
+			return 0, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "", path)
+		assert.Equal(t, 0, count)
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Equal(t, 0, len(entries))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Chdir(t.TempDir())
+
+		_, _, err := SaveToFile("metrics", now, func(_ io.Writer) (int, error) {
+			return 0, errors.New("failed")
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestSaveAndNotify(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	commands := layout.NewCommandList()
+
+	SaveAndNotify(commands, "logs", "logs", func(_ io.Writer) (int, error) {
+		return 0, nil
+	})
+	assert.Equal(t, " No logs to save", commands.GetText(true))
+
+	SaveAndNotify(commands, "logs", "logs", func(_ io.Writer) (int, error) {
+		return 0, errors.New("failed")
+	})
+	assert.Equal(t, " Failed to save logs: failed", commands.GetText(true))
+
+	SaveAndNotify(commands, "traces", "spans", func(w io.Writer) (int, error) {
+		_, err := fmt.Fprintln(w, `{"resourceSpans":[]}`)
+		return 3, err
+	})
+	assert.Contains(t, commands.GetText(true), " Saved 3 spans to otel-tui-traces-")
+}
@@ -7,13 +7,15 @@ import (
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/layout"
 )
 
-type onInputEnterFn func(inputConfirmed string, sortType telemetry.SortType)
+type onInputEnterFn func(inputConfirmed string, sortType telemetry.SortType) error
 type onInputDoneFn func()
 type onInputChangedFn func(text string)
-type onSortTypeChangedFn func(inputConfirmed string, sortType telemetry.SortType)
+type onSortTypeChangedFn func(inputConfirmed string, sortType telemetry.SortType) error
 
 type Filter struct {
 	view                  *tview.InputField
+	errView               *tview.TextView
+	container             *tview.Flex
 	sortType              telemetry.SortType
 	input, inputConfirmed string
 	onInputEnterFn        onInputEnterFn
@@ -36,6 +38,7 @@ func NewFilter(
 
 	filter := &Filter{
 		view:                field,
+		errView:             tview.NewTextView().SetDynamicColors(true),
 		sortType:            telemetry.SORT_TYPE_NONE,
 		onInputEnterFn:      onInputEnterFn,
 		onInputDoneFn:       onInputDoneFn,
@@ -64,12 +67,18 @@ func (f *Filter) onDoneFunc() func(key tcell.Key) {
 	return func(key tcell.Key) {
 		switch key {
 		case tcell.KeyEnter:
-			f.inputConfirmed = f.input
 			if f.onInputEnterFn != nil {
-				f.onInputEnterFn(f.inputConfirmed, f.sortType)
+				if err := f.onInputEnterFn(f.input, f.sortType); err != nil {
+					// keep the focus to fix the input
+					f.SetError(err)
+					return
+				}
 			}
+			f.inputConfirmed = f.input
+			f.SetError(nil)
 		case tcell.KeyEsc:
 			f.view.SetText(f.inputConfirmed)
+			f.SetError(nil)
 		}
 		if f.onInputDoneFn != nil {
 			f.onInputDoneFn()
@@ -100,8 +109,38 @@ func (f *Filter) RotateSortType() {
 		f.sortType = telemetry.SORT_TYPE_NONE
 	}
 	if f.onSortTypeChangedFn != nil {
-		f.onSortTypeChangedFn(f.inputConfirmed, f.sortType)
+		f.SetError(f.onSortTypeChangedFn(f.inputConfirmed, f.sortType))
+	}
+}
+
+// SetError shows the error under the input field. A nil error hides it.
+func (f *Filter) SetError(err error) {
+	height := 0
+	if err == nil {
+		f.errView.Clear()
+	} else {
+		height = 1
+		f.errView.SetText("[red]" + tview.Escape(err.Error()))
 	}
+	if f.container != nil {
+		f.container.ResizeItem(f.errView, height, 0)
+	}
+}
+
+// SetPlaceholder shows the example of the input in the empty field. The field is widened
+// to show the whole example.
+func (f *Filter) SetPlaceholder(placeholder string) *Filter {
+	f.view.SetPlaceholder(placeholder).
+		SetFieldWidth(max(f.view.GetFieldWidth(), len(placeholder)+1))
+	return f
+}
+
+// AddTo adds the input field and the error line to the container
+func (f *Filter) AddTo(container *tview.Flex) *tview.Flex {
+	f.container = container
+	return container.
+		AddItem(f.view, 1, 0, false).
+		AddItem(f.errView, 0, 0, false)
 }
 
 func (f *Filter) InputConfirmed() string {
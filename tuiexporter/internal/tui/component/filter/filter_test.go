@@ -1,9 +1,11 @@
 package filter
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
 	"github.com/stretchr/testify/mock"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/test"
@@ -15,8 +17,9 @@ type filterCallbackMock struct {
 	mock.Mock
 }
 
-func (m *filterCallbackMock) OnInputEnter(inputConfirmed string, sortType telemetry.SortType) {
-	m.Called(inputConfirmed, sortType)
+func (m *filterCallbackMock) OnInputEnter(inputConfirmed string, sortType telemetry.SortType) error {
+	args := m.Called(inputConfirmed, sortType)
+	return args.Error(0)
 }
 func (m *filterCallbackMock) OnInputDone() {
 	m.Called()
@@ -24,8 +27,9 @@ func (m *filterCallbackMock) OnInputDone() {
 func (m *filterCallbackMock) OnInputChanged(text string) {
 	m.Called(text)
 }
-func (m *filterCallbackMock) OnSortTypeChanged(inputConfirmed string, sortType telemetry.SortType) {
-	m.Called(inputConfirmed, sortType)
+func (m *filterCallbackMock) OnSortTypeChanged(inputConfirmed string, sortType telemetry.SortType) error {
+	args := m.Called(inputConfirmed, sortType)
+	return args.Error(0)
 }
 
 func TestDrawFilter(t *testing.T) {
@@ -92,7 +96,7 @@ func TestDrawFilter(t *testing.T) {
 			handler := filter.view.InputHandler()
 
 			mockcb := &filterCallbackMock{}
-			mockcb.On("OnInputEnter", "a-", telemetry.SORT_TYPE_NONE).Once()
+			mockcb.On("OnInputEnter", "a-", telemetry.SORT_TYPE_NONE).Return(nil).Once()
 			mockcb.On("OnInputDone").Once()
 
 			filter.onInputEnterFn = mockcb.OnInputEnter
@@ -130,6 +134,47 @@ func TestDrawFilter(t *testing.T) {
 			mockcb.AssertExpectations(t)
 			mockcb.AssertNotCalled(t, "OnInputEnter")
 		})
+
+		t.Run("enter with error", func(t *testing.T) {
+			filter := setup()
+			filter.inputConfirmed = "a"
+			container := filter.AddTo(tview.NewFlex().SetDirection(tview.FlexRow))
+			handler := filter.view.InputHandler()
+
+			mockcb := &filterCallbackMock{}
+			mockcb.On("OnInputEnter", "a-", telemetry.SORT_TYPE_NONE).Return(errors.New("invalid [query]")).Once()
+
+			filter.onInputEnterFn = mockcb.OnInputEnter
+			filter.onInputDoneFn = mockcb.OnInputDone
+
+			filter.view.Focus(nil)
+
+			handler(tcell.NewEventKey(tcell.KeyEnter, ' ', tcell.ModNone), nil)
+
+			assert.Equal(t, "a-", filter.input)
+			assert.Equal(t, "a", filter.inputConfirmed)
+			assert.Equal(t, "invalid [query]", filter.errView.GetText(true))
+
+			container.SetRect(0, 0, sw, 3)
+			container.Draw(screen)
+			_, _, _, h := filter.errView.GetRect()
+			assert.Equal(t, 1, h)
+
+			mockcb.AssertExpectations(t)
+			mockcb.AssertNotCalled(t, "OnInputDone")
+
+			// the error is cleared when the input is canceled
+			mockcb.On("OnInputDone").Once()
+			handler(tcell.NewEventKey(tcell.KeyEsc, ' ', tcell.ModNone), nil)
+
+			assert.Equal(t, "a", filter.view.GetText())
+			assert.Equal(t, "", filter.errView.GetText(true))
+
+			container.Draw(screen)
+			_, _, _, h = filter.errView.GetRect()
+			assert.Equal(t, 0, h)
+			mockcb.AssertExpectations(t)
+		})
 	})
 
 	t.Run("rotate sort type", func(t *testing.T) {
@@ -161,7 +206,7 @@ func TestDrawFilter(t *testing.T) {
 				filter.sortType = tt.input
 
 				mockcb := &filterCallbackMock{}
-				mockcb.On("OnSortTypeChanged", "", tt.want).Once()
+				mockcb.On("OnSortTypeChanged", "", tt.want).Return(nil).Once()
 
 				filter.onSortTypeChangedFn = mockcb.OnSortTypeChanged
 				filter.RotateSortType()
@@ -172,3 +217,13 @@ func TestDrawFilter(t *testing.T) {
 		}
 	})
 }
+
+func TestSetPlaceholder(t *testing.T) {
+	filter := NewFilter(layout.NewCommandList(), "Filter: ", nil, nil, nil, nil)
+
+	filter.SetPlaceholder("short")
+	assert.Equal(t, 20, filter.View().GetFieldWidth())
+
+	filter.SetPlaceholder("status=error duration>250ms")
+	assert.Equal(t, 28, filter.View().GetFieldWidth())
+}
@@ -8,6 +8,7 @@ const (
 	PageIDLogs          = "Logs"
 	PageIDTraceTopology = "TraceTopology"
 	PageIDTimeline      = "Timeline"
+	PageIDCompare       = "Compare"
 	PageIDModal         = "Modal"
 )
 
@@ -5,6 +5,7 @@ import (
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/layout"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/navigation"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/page/compare"
 	clog "github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/page/log"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/page/metric"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/page/modal"
@@ -19,6 +20,7 @@ type TUIPages struct {
 	traces   tview.Primitive
 	timeline *timeline.TimelinePage
 	topology *topology.TopologyPage
+	compare  *compare.ComparePage
 	metrics  tview.Primitive
 	logs     tview.Primitive
 	modal    tview.Primitive
@@ -96,8 +98,11 @@ func (p *TUIPages) registerPages(store *telemetry.Store, setFocusFn func(tview.P
 	navigation.Init(setFocusFn, showModalFn, hideModalFn)
 
 	traces := trace.NewTracePage(
-		func(row, _ int) {
-			p.timeline.ShowTimelineByRow(row - 1)
+		func(traceID string) {
+			p.timeline.DrawTimeline(traceID)
+		},
+		func(baseTraceID, targetTraceID string) {
+			p.compare.DrawComparison(baseTraceID, targetTraceID)
 		},
 		store,
 	)
@@ -121,6 +126,18 @@ func (p *TUIPages) registerPages(store *telemetry.Store, setFocusFn func(tview.P
 	p.topology = topology
 	p.pages.AddPage(layout.PageIDTraceTopology, topology.GetPrimitive(), true, false)
 
+	compare := compare.NewComparePage(
+		func() {
+			p.switchToPage(layout.PageIDCompare)
+		},
+		store.GetTraceCache(),
+		func() {
+			p.switchToPage(layout.PageIDTraces)
+		},
+	)
+	p.compare = compare
+	p.pages.AddPage(layout.PageIDCompare, compare.GetPrimitive(), true, false)
+
 	metrics := metric.NewMetricPage(
 		store,
 	)
@@ -0,0 +1,270 @@
+package compare
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/layout"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/navigation"
+)
+
+const (
+	defaultSpansProportion      = 30
+	defaultAttributesProportion = 20
+)
+
+// ComparePage shows the spans of two traces side by side, aligned by the path of the service
+// and span names. The first trace (A) is the base of the comparison and the second one (B)
+// is the target.
+type ComparePage struct {
+	switchToPageFn func()
+	onEscape       func()
+	view           *tview.Flex
+	container      *tview.Flex
+	spans          *tview.Table
+	attributes     *tview.Table
+	cache          *telemetry.TraceCache
+	baseTraceID    string
+	targetTraceID  string
+	diff           *telemetry.TraceDiff
+}
+
+func NewComparePage(
+	switchToPageFn func(),
+	cache *telemetry.TraceCache,
+	onEscape func(),
+) *ComparePage {
+	commands := layout.NewCommandList()
+	container := tview.NewFlex().SetDirection(tview.FlexColumn)
+
+	spans := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	spans.SetTitle("Compare Traces (t)").SetBorder(true)
+
+	attributes := tview.NewTable().
+		SetBorders(false).
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	attributes.SetTitle("Attribute Differences (d)").SetBorder(true)
+
+	resizeManager := layout.NewResizeManager(layout.ResizeDirectionHorizontal)
+	resizeManager.Register(
+		container,
+		spans,
+		attributes,
+		defaultSpansProportion,
+		defaultAttributesProportion,
+		commands,
+	)
+
+	container.AddItem(spans, 0, defaultSpansProportion, true).
+		AddItem(attributes, 0, defaultAttributesProportion, false)
+
+	page := &ComparePage{
+		switchToPageFn: switchToPageFn,
+		onEscape:       onEscape,
+		container:      container,
+		spans:          spans,
+		attributes:     attributes,
+		cache:          cache,
+	}
+
+	spans.SetSelectionChangedFunc(func(row, _ int) {
+		page.updateAttributes(row)
+	})
+
+	page.registerCommands(commands, resizeManager)
+	page.view = layout.AttachCommandList(commands, container)
+
+	return page
+}
+
+func (p *ComparePage) GetPrimitive() tview.Primitive {
+	return p.view
+}
+
+// DrawComparison shows the comparison of the base trace (A) and the target trace (B)
+func (p *ComparePage) DrawComparison(baseTraceID, targetTraceID string) {
+	p.baseTraceID, p.targetTraceID = baseTraceID, targetTraceID
+	p.spans.Clear()
+	p.attributes.Clear()
+
+	diff, ok := p.cache.DiffTraces(baseTraceID, targetTraceID)
+	p.diff = diff
+	if !ok {
+		p.spans.SetTitle("Compare Traces (t)")
+		p.spans.SetCell(0, 0, tview.NewTableCell("The traces are not found").SetSelectable(false))
+	} else {
+		p.drawSpans()
+	}
+
+	p.switchToPageFn()
+	navigation.Focus(p.spans)
+}
+
+func (p *ComparePage) drawSpans() {
+	p.spans.SetTitle(fmt.Sprintf(
+		"Compare Traces (t) -- A: %s (%s), B: %s (%s)",
+		p.baseTraceID, p.diff.BaseDuration.String(),
+		p.targetTraceID, p.diff.TargetDuration.String(),
+	))
+
+	for col, h := range []string{"Span", "A", "B", "Delta", "Attributes"} {
+		p.spans.SetCell(0, col, tview.NewTableCell(h).
+			SetSelectable(false).
+			SetTextColor(tcell.ColorYellow))
+	}
+
+	for i, d := range p.diff.Spans {
+		row := i + 1
+		span := d.Span()
+		name := strings.Repeat("  ", d.Depth) + span.GetServiceName() + ": " + span.Span.Name()
+		p.spans.SetCell(row, 0, tview.NewTableCell(tview.Escape(name)))
+		p.spans.SetCell(row, 1, newDurationCell(d.Base))
+		p.spans.SetCell(row, 2, newDurationCell(d.Target))
+		p.spans.SetCell(row, 3, newDeltaCell(d))
+		attrs := ""
+		if len(d.Attributes) > 0 {
+			attrs = fmt.Sprintf("%d changed", len(d.Attributes))
+		}
+		p.spans.SetCell(row, 4, tview.NewTableCell(attrs).SetTextColor(tcell.ColorYellow))
+	}
+
+	if len(p.diff.Spans) > 0 {
+		p.spans.Select(1, 0)
+	}
+}
+
+func newDurationCell(span *telemetry.SpanData) *tview.TableCell {
+	if span == nil {
+		return tview.NewTableCell("-")
+	}
+	return tview.NewTableCell(span.GetDurationText())
+}
+
+// newDeltaCell returns the cell of the duration delta. Slower spans are red and faster ones
+// are green, and so are the spans only in A (removed) and only in B (added).
+func newDeltaCell(d *telemetry.SpanDiff) *tview.TableCell {
+	switch {
+	case d.Target == nil:
+		return tview.NewTableCell("only in A").SetTextColor(tcell.ColorRed)
+	case d.Base == nil:
+		return tview.NewTableCell("only in B").SetTextColor(tcell.ColorGreen)
+	}
+
+	delta := d.DurationDelta()
+	cell := tview.NewTableCell(formatDelta(delta))
+	if delta > 0 {
+		cell.SetTextColor(tcell.ColorRed)
+	} else if delta < 0 {
+		cell.SetTextColor(tcell.ColorGreen)
+	}
+	return cell
+}
+
+func formatDelta(delta time.Duration) string {
+	if delta > 0 {
+		return "+" + delta.String()
+	}
+	return delta.String()
+}
+
+// updateAttributes shows the attribute differences of the span in the row
+func (p *ComparePage) updateAttributes(row int) {
+	p.attributes.Clear()
+	if p.diff == nil || row < 1 || row > len(p.diff.Spans) {
+		return
+	}
+	d := p.diff.Spans[row-1]
+
+	for col, h := range []string{"Attribute", "A", "B"} {
+		p.attributes.SetCell(0, col, tview.NewTableCell(h).
+			SetSelectable(false).
+			SetTextColor(tcell.ColorYellow))
+	}
+
+	message := ""
+	switch {
+	case d.Target == nil:
+		message = "The span is only in A"
+	case d.Base == nil:
+		message = "The span is only in B"
+	case len(d.Attributes) == 0:
+		message = "No differences"
+	}
+	if message != "" {
+		p.attributes.SetCell(1, 0, tview.NewTableCell(message))
+		return
+	}
+
+	for i, a := range d.Attributes {
+		p.attributes.SetCell(i+1, 0, tview.NewTableCell(tview.Escape(a.Key)))
+		p.attributes.SetCell(i+1, 1, newAttributeValueCell(a.BaseValue, a.InBase))
+		p.attributes.SetCell(i+1, 2, newAttributeValueCell(a.TargetValue, a.InTarget))
+	}
+}
+
+func newAttributeValueCell(value string, ok bool) *tview.TableCell {
+	if !ok {
+		return tview.NewTableCell("(missing)").SetTextColor(tcell.ColorGray)
+	}
+	return tview.NewTableCell(tview.Escape(value))
+}
+
+func (p *ComparePage) registerCommands(commands *tview.TextView, resizeManager *layout.ResizeManager) {
+	layout.RegisterCommandList(commands, p.container, nil, layout.KeyMaps{
+		{
+			Key: tcell.NewEventKey(tcell.KeyRune, 'd', tcell.ModNone),
+			Handler: func(event *tcell.EventKey) *tcell.EventKey {
+				navigation.Focus(p.attributes)
+				return nil
+			},
+		},
+		{
+			Key: tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
+			Handler: func(event *tcell.EventKey) *tcell.EventKey {
+				navigation.Focus(p.spans)
+				return nil
+			},
+		},
+		{
+			Key: tcell.NewEventKey(tcell.KeyEsc, ' ', tcell.ModNone),
+			Handler: func(event *tcell.EventKey) *tcell.EventKey {
+				p.onEscape()
+				return nil
+			},
+		},
+	})
+
+	spansKeyMaps := layout.KeyMaps{
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, 'w', tcell.ModNone),
+			Description: "Swap A and B",
+			Handler: func(event *tcell.EventKey) *tcell.EventKey {
+				p.DrawComparison(p.targetTraceID, p.baseTraceID)
+				return nil
+			},
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyEsc, ' ', tcell.ModNone),
+			Description: "Back to Traces",
+		},
+	}
+	spansKeyMaps.Merge(resizeManager.KeyMaps())
+	layout.RegisterCommandList(commands, p.spans, nil, spansKeyMaps)
+
+	attributesKeyMaps := layout.KeyMaps{
+		{
+			Key:         tcell.NewEventKey(tcell.KeyEsc, ' ', tcell.ModNone),
+			Description: "Back to Traces",
+		},
+	}
+	attributesKeyMaps.Merge(resizeManager.KeyMaps())
+	layout.RegisterCommandList(commands, p.attributes, nil, attributesKeyMaps)
+}
@@ -0,0 +1,88 @@
+package compare
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/test"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"gotest.tools/v3/assert"
+)
+
+func TestComparePage(t *testing.T) {
+	// traceid: 1
+	//  └- resource: test-service-1
+	//    └- scope: test-scope-1-1
+	//      ├- span: span-0-0-0
+	//      └- span: span-0-0-1
+	// traceid: 2
+	//  └- resource: test-service-1
+	//    └- scope: test-scope-1-1
+	//      ├- span: span-0-0-0 (100ms slower)
+	//      ├- span: span-0-0-1 (http.route added)
+	//      └- span: span-0-0-2
+	store := telemetry.NewStore(clockwork.NewRealClock())
+	basePayload, _ := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{2}})
+	store.AddSpan(&basePayload)
+	targetPayload, targetSpans := test.GenerateOTLPTracesPayload(t, 2, 1, []int{1}, [][]int{{3}})
+	slower := targetSpans.Spans[0]
+	slower.SetEndTimestamp(pcommon.NewTimestampFromTime(slower.EndTimestamp().AsTime().Add(100 * time.Millisecond)))
+	targetSpans.Spans[1].Attributes().PutStr("http.route", "/users")
+	store.AddSpan(&targetPayload)
+
+	baseTraceID := basePayload.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).TraceID().String()
+	targetTraceID := targetPayload.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).TraceID().String()
+
+	switched := false
+	page := NewComparePage(func() { switched = true }, store.GetTraceCache(), func() {})
+
+	t.Run("spans", func(t *testing.T) {
+		page.DrawComparison(baseTraceID, targetTraceID)
+
+		assert.Assert(t, switched)
+		assert.Equal(t, 4, page.spans.GetRowCount())
+		tests := []struct {
+			row                       int
+			name, base, target, delta string
+			attributes                string
+		}{
+			{1, "test-service-1: span-0-0-0", "200ms", "300ms", "+100ms", ""},
+			{2, "test-service-1: span-0-0-1", "200ms", "200ms", "0s", "1 changed"},
+			{3, "test-service-1: span-0-0-2", "-", "200ms", "only in B", ""},
+		}
+		for _, tt := range tests {
+			assert.Equal(t, tt.name, page.spans.GetCell(tt.row, 0).Text)
+			assert.Equal(t, tt.base, page.spans.GetCell(tt.row, 1).Text)
+			assert.Equal(t, tt.target, page.spans.GetCell(tt.row, 2).Text)
+			assert.Equal(t, tt.delta, page.spans.GetCell(tt.row, 3).Text)
+			assert.Equal(t, tt.attributes, page.spans.GetCell(tt.row, 4).Text)
+		}
+	})
+
+	t.Run("attributes", func(t *testing.T) {
+		page.DrawComparison(baseTraceID, targetTraceID)
+
+		page.spans.Select(2, 0)
+		assert.Equal(t, "http.route", page.attributes.GetCell(1, 0).Text)
+		assert.Equal(t, "(missing)", page.attributes.GetCell(1, 1).Text)
+		assert.Equal(t, "/users", page.attributes.GetCell(1, 2).Text)
+
+		page.spans.Select(3, 0)
+		assert.Equal(t, "The span is only in B", page.attributes.GetCell(1, 0).Text)
+	})
+
+	t.Run("swap", func(t *testing.T) {
+		page.DrawComparison(targetTraceID, baseTraceID)
+
+		assert.Equal(t, "-100ms", page.spans.GetCell(1, 3).Text)
+		assert.Equal(t, "only in A", page.spans.GetCell(3, 3).Text)
+	})
+
+	t.Run("trace not found", func(t *testing.T) {
+		page.DrawComparison(baseTraceID, "unknown")
+
+		assert.Equal(t, "The traces are not found", page.spans.GetCell(0, 0).Text)
+	})
+}
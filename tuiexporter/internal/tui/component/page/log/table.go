@@ -8,6 +8,7 @@ import (
 	"github.com/rivo/tview"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/json"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/export"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/filter"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/layout"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/navigation"
@@ -42,9 +43,9 @@ func newTable(
 
 	filter := filter.NewFilter(
 		commands,
-		"Filter by service or body (/): ",
-		func(inputConfirmed string, _ telemetry.SortType) {
-			store.ApplyFilterLogs(inputConfirmed)
+		"Filter by text or query (/): ",
+		func(inputConfirmed string, _ telemetry.SortType) error {
+			return store.ApplyFilterLogs(inputConfirmed)
 		},
 		func() {
 			navigation.Focus(t)
@@ -52,11 +53,14 @@ func newTable(
 		nil,
 		nil,
 	)
+	filter.SetPlaceholder(`e.g. severity>=WARN body~"timeout.*db"`)
 
-	logData := ctable.NewLogDataForTable(store.GetFilteredLogs())
+	logData := ctable.NewLogDataForTable(store.SnapshotLogs)
 	t.SetContent(&logData)
+	ctable.RefreshOnDraw(t, &logData)
 	store.SetOnLogAdded(func() {
 		if detail.tree.GetRoot() == nil {
+			logData.Refresh()
 			// Select the first data row (row 1), not the header (row 0)
 			t.Select(1, 0)
 		}
@@ -74,11 +78,11 @@ func newTable(
 
 	t.SetSelectionChangedFunc(stable.onSelectionChangedFunc())
 
-	container.
-		AddItem(filter.View(), 1, 0, false).
+	filter.AddTo(container).
 		AddItem(t, 0, 1, true)
 
 	stable.registerCommands(commands, resizeManagers)
+	ctable.RefreshOnInput(t, &logData)
 
 	return stable
 }
@@ -115,6 +119,14 @@ func (t *table) registerCommands(commands *tview.TextView, resizeManagers []*lay
 				return nil
 			},
 		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, 'S', tcell.ModNone),
+			Description: "Save",
+			Handler: func(_ *tcell.EventKey) *tcell.EventKey {
+				export.SaveAndNotify(commands, "logs", "logs", t.store.ExportFilteredLogs)
+				return nil
+			},
+		},
 		{
 			Key:         tcell.NewEventKey(tcell.KeyCtrlX, ' ', tcell.ModNone),
 			Description: "Clear all data",
@@ -136,7 +148,9 @@ func (t *table) onSelectionChangedFunc() func(row, col int) {
 		if row == 0 {
 			return
 		}
-		selected := t.store.GetFilteredLogByIdx(row - 1)
+		// the table may not be drawn since the store was updated
+		t.logData.Refresh()
+		selected := t.logData.At(row - 1)
 		if selected == nil {
 			return
 		}
@@ -3,7 +3,9 @@ package metric
 import (
 	"fmt"
 	"math"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/rivo/tview"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/layout"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/navigation"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 )
@@ -25,6 +28,9 @@ type chart struct {
 	focusTargets   []layout.FocusableBox
 	store          *telemetry.Store
 	resizeManagers []*layout.ResizeManager
+	sumMode        sumMode
+	// histogramHeatmap is true if the histograms are drawn as the heatmap
+	histogramHeatmap bool
 }
 
 func newChart(
@@ -56,9 +62,11 @@ func (c *chart) flush() {
 	c.ch.Clear()
 }
 
-func (c *chart) update(m *telemetry.MetricData) {
+// update draws the chart of the series, or clears it if the series is nil
+func (c *chart) update(ms *telemetry.MetricSeries) {
 	c.ch.Clear()
-	keyMaps := c.drawMetricChartByRow(m)
+	c.view.SetTitle("Chart (c)")
+	keyMaps := c.drawMetricChartByRow(ms)
 	c.updateCommands(keyMaps)
 }
 
@@ -70,27 +78,35 @@ func (a ByTimestamp) Less(i, j int) bool {
 	return a[i].Timestamp().AsTime().Before(a[j].Timestamp().AsTime())
 }
 
-func (c *chart) drawMetricChartByRow(m *telemetry.MetricData) layout.KeyMaps {
+func (c *chart) drawMetricChartByRow(ms *telemetry.MetricSeries) layout.KeyMaps {
+	if ms == nil {
+		return layout.KeyMaps{}
+	}
+	m := ms.Latest()
 	if m == nil {
 		return layout.KeyMaps{}
 	}
 
 	switch m.Metric.Type() {
 	case pmetric.MetricTypeGauge:
-		return c.drawMetricNumberChart(m)
+		return c.drawMetricNumberChart(ms, m)
 	case pmetric.MetricTypeSum:
-		return c.drawMetricNumberChart(m)
+		return c.drawMetricNumberChart(ms, m)
 	case pmetric.MetricTypeHistogram:
-		return c.drawMetricHistogramChart(m)
+		return c.drawMetricHistogramChart(ms, m)
 	case pmetric.MetricTypeExponentialHistogram:
-		return c.drawMetricNumberChart(m)
+		return c.drawMetricExponentialHistogramChart(m)
 	case pmetric.MetricTypeSummary:
-		return c.drawMetricNumberChart(m)
+		return c.drawMetricSummaryChart(ms, m)
 	}
 	return layout.KeyMaps{}
 }
 
-func (c *chart) drawMetricHistogramChart(m *telemetry.MetricData) layout.KeyMaps {
+func (c *chart) drawMetricHistogramChart(ms *telemetry.MetricSeries, m *telemetry.MetricData) layout.KeyMaps {
+	if c.histogramHeatmap {
+		return c.drawMetricHistogramHeatmap(ms, m)
+	}
+
 	dpcount := m.Metric.Histogram().DataPoints().Len()
 	chs := make([]*tvxwidgets.BarChart, dpcount)
 	sides := make([]*tview.Flex, dpcount)
@@ -134,6 +150,200 @@ func (c *chart) drawMetricHistogramChart(m *telemetry.MetricData) layout.KeyMaps
 		sides[dpi] = side
 	}
 
+	return append(c.drawDataPointPages(chs, sides), c.histogramViewKeyMaps(ms)...)
+}
+
+// drawMetricHistogramHeatmap draws the heatmap of the points of the series of the histogram, which
+// share the resource and the scope with the given series, with the lines of the quantiles estimated
+// at each point
+func (c *chart) drawMetricHistogramHeatmap(ms *telemetry.MetricSeries, m *telemetry.MetricData) layout.KeyMaps {
+	c.view.SetTitle("Chart (c) -- Heatmap")
+	keyMaps := c.histogramViewKeyMaps(ms)
+
+	points := []*telemetry.MetricData{}
+	for _, series := range c.store.GetMetricSeries(ms) {
+		points = append(points, series.Points()...)
+	}
+
+	// attribute set and data points map
+	dataMap := make(map[string][]pmetric.HistogramDataPoint, 1)
+	attrsets := []string{}
+	for _, m := range points {
+		if m.Metric.Type() != pmetric.MetricTypeHistogram {
+			continue
+		}
+		dps := m.Metric.Histogram().DataPoints()
+		for dpi := 0; dpi < dps.Len(); dpi++ {
+			dp := dps.At(dpi)
+			attrset := telemetry.AttributesText(dp.Attributes())
+			if _, ok := dataMap[attrset]; !ok {
+				attrsets = append(attrsets, attrset)
+			}
+			dataMap[attrset] = append(dataMap[attrset], dp)
+		}
+	}
+
+	if len(attrsets) == 0 {
+		return keyMaps
+	}
+
+	// Draw a heatmap of the attribute set of the selected metric first
+	attrsetidx := 0
+	if m.Metric.Histogram().DataPoints().Len() > 0 {
+		attrsetidx = max(slices.Index(attrsets, telemetry.AttributesText(m.Metric.Histogram().DataPoints().At(0).Attributes())), 0)
+	}
+
+	temporality := m.Metric.Histogram().AggregationTemporality()
+	hm := newHeatmap()
+	side := tview.NewFlex().SetDirection(tview.FlexRow)
+
+	draw := func() {
+		attrset := attrsets[attrsetidx]
+		title := attrset
+		if title == "" {
+			title = "N/A"
+		}
+		hm.view.SetTitle(fmt.Sprintf("%s [%d / %d] ( <- | -> )", title, attrsetidx+1, len(attrsets)))
+		bounds, columns := histogramDeltas(dataMap[attrset], temporality)
+		hm.update(bounds, columns)
+
+		// the quantiles of the latest point with the colors of the lines
+		var buckets []bucket
+		if len(columns) > 0 {
+			buckets = explicitBuckets(bounds, columns[len(columns)-1])
+		}
+		stats := []string{fmt.Sprintf("● points: %d", len(columns))}
+		for _, hq := range heatmapQuantiles {
+			v, ok := bucketQuantile(buckets, hq.q)
+			stats = append(stats, fmt.Sprintf("[%s]━[-] %s: %s", hq.color.String(), quantileLabel(hq.q), optionalStat(ok, v)))
+		}
+		sts := tview.NewTextView().SetDynamicColors(true).SetText(strings.Join(stats, "\n"))
+		sts.SetBorder(true).SetTitle("Statistics")
+		side.Clear().AddItem(sts, len(stats)+2, 1, false)
+	}
+	draw()
+
+	c.ch.AddItem(hm.view, 0, 7, true).AddItem(side, 0, 3, false)
+	c.focusTargets = []layout.FocusableBox{hm.view}
+
+	return append(keyMaps, layout.KeyMaps{
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRight, ' ', tcell.ModNone),
+			Hidden:      true,
+			Description: "",
+			Handler: func(_ *tcell.EventKey) *tcell.EventKey {
+				if attrsetidx < len(attrsets)-1 {
+					attrsetidx++
+				} else {
+					attrsetidx = 0
+				}
+				draw()
+				return nil
+			},
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyLeft, ' ', tcell.ModNone),
+			Hidden:      true,
+			Description: "",
+			Handler: func(_ *tcell.EventKey) *tcell.EventKey {
+				if attrsetidx > 0 {
+					attrsetidx--
+				} else {
+					attrsetidx = len(attrsets) - 1
+				}
+				draw()
+				return nil
+			},
+		},
+	}...)
+}
+
+// histogramViewKeyMaps returns the key map switching the view of the histogram
+func (c *chart) histogramViewKeyMaps(ms *telemetry.MetricSeries) layout.KeyMaps {
+	return layout.KeyMaps{
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
+			Description: "Switch view (Buckets / Heatmap)",
+			Handler: func(_ *tcell.EventKey) *tcell.EventKey {
+				c.histogramHeatmap = !c.histogramHeatmap
+				c.update(ms)
+				navigation.Focus(c.view)
+				return nil
+			},
+		},
+	}
+}
+
+func (c *chart) drawMetricExponentialHistogramChart(m *telemetry.MetricData) layout.KeyMaps {
+	dpcount := m.Metric.ExponentialHistogram().DataPoints().Len()
+	chs := make([]*tvxwidgets.BarChart, dpcount)
+	sides := make([]*tview.Flex, dpcount)
+	for dpi := range dpcount {
+		dp := m.Metric.ExponentialHistogram().DataPoints().At(dpi)
+		ch := tvxwidgets.NewBarChart()
+		ch.SetBorder(true)
+		ch.SetTitle(fmt.Sprintf("Data point [%d / %d] (scale: %d) ( <- | -> )", dpi+1, dpcount, dp.Scale()))
+		side := tview.NewFlex().SetDirection(tview.FlexRow)
+		sts := tview.NewFlex().SetDirection(tview.FlexRow)
+		sts.SetBorder(true).SetTitle("Statistics")
+		txt := tview.NewFlex().SetDirection(tview.FlexRow)
+		txt.SetBorder(true).SetTitle("Attributes")
+
+		buckets := exponentialBuckets(dp)
+		for _, b := range buckets {
+			// the bars are labeled with the upper bound of the bucket as the explicit buckets
+			label := formatBound(b.upper)
+			if b.lower == -b.upper {
+				label = "0"
+			}
+			ch.AddBar(label, uint64ToInt(b.count), tcell.ColorYellow)
+		}
+
+		stats := []string{
+			fmt.Sprintf("● count: %d", dp.Count()),
+			fmt.Sprintf("● sum: %s", formatBound(dp.Sum())),
+			fmt.Sprintf("● min: %s", optionalStat(dp.HasMin(), dp.Min())),
+			fmt.Sprintf("● max: %s", optionalStat(dp.HasMax(), dp.Max())),
+			fmt.Sprintf("● zero count: %d", dp.ZeroCount()),
+		}
+		for _, q := range []float64{0.5, 0.9, 0.99} {
+			v, ok := bucketQuantile(buckets, q)
+			// the estimation never goes beyond the observed values
+			if ok && dp.HasMin() {
+				v = math.Max(v, dp.Min())
+			}
+			if ok && dp.HasMax() {
+				v = math.Min(v, dp.Max())
+			}
+			stats = append(stats, fmt.Sprintf("● p%s: %s", strconv.FormatFloat(q*100, 'f', -1, 64), optionalStat(ok, v)))
+		}
+		for _, stat := range stats {
+			sts.AddItem(tview.NewTextView().SetText(stat), 1, 1, false)
+		}
+		dp.Attributes().Range(func(k string, v pcommon.Value) bool {
+			txt.AddItem(tview.NewTextView().SetText(fmt.Sprintf("● %s: %s", k, v.AsString())), 2, 1, false)
+			return true
+		})
+		side.AddItem(sts, len(stats)+2, 1, false).AddItem(txt, 0, 1, false)
+		chs[dpi] = ch
+		sides[dpi] = side
+	}
+
+	return c.drawDataPointPages(chs, sides)
+}
+
+// optionalStat returns the statistic value, or "-" if it is not recorded
+func optionalStat(ok bool, v float64) string {
+	if !ok {
+		return "-"
+	}
+	return formatBound(v)
+}
+
+// drawDataPointPages draws the chart and the side pane of the first data point. The left and right
+// keys switch them to the other data points.
+func (c *chart) drawDataPointPages(chs []*tvxwidgets.BarChart, sides []*tview.Flex) layout.KeyMaps {
+	dpcount := len(chs)
 	if dpcount == 0 {
 		return layout.KeyMaps{}
 	}
@@ -176,14 +386,9 @@ func (c *chart) drawMetricHistogramChart(m *telemetry.MetricData) layout.KeyMaps
 	}
 }
 
-func (c *chart) drawMetricNumberChart(m *telemetry.MetricData) layout.KeyMaps {
-	sname := telemetry.GetServiceNameFromResource(m.ResourceMetric.Resource())
-	mcache := c.store.GetMetricCache()
-	ms, ok := mcache.GetMetricsBySvcAndMetricName(sname, m.Metric.Name())
-	if !ok {
-		return layout.KeyMaps{}
-	}
-
+// drawMetricNumberChart draws the points of the series of the metric, which share the resource
+// and the scope with the given series and differ only in the data point attributes
+func (c *chart) drawMetricNumberChart(ms *telemetry.MetricSeries, m *telemetry.MetricData) layout.KeyMaps {
 	// attribute name and value map
 	dataMap := make(map[string]map[string][]*pmetric.NumberDataPoint, 1)
 	attrkeys := []string{}
@@ -201,53 +406,73 @@ func (c *chart) drawMetricNumberChart(m *telemetry.MetricData) layout.KeyMaps {
 		dataMap[k][vstr] = append(dataMap[k][vstr], dp)
 	}
 
-	for _, m := range ms {
-		var dps pmetric.NumberDataPointSlice
+	points := []*pmetric.NumberDataPoint{}
+	for _, series := range c.store.GetMetricSeries(ms) {
+		seriesPoints := []*pmetric.NumberDataPoint{}
+		for _, m := range series.Points() {
+			var dps pmetric.NumberDataPointSlice
+
+			switch m.Metric.Type() {
+			case pmetric.MetricTypeGauge:
+				dps = m.Metric.Gauge().DataPoints()
+			case pmetric.MetricTypeSum:
+				dps = m.Metric.Sum().DataPoints()
+			default:
+				support = false
+			}
+			if !support {
+				break
+			}
 
-		switch m.Metric.Type() {
-		case pmetric.MetricTypeGauge:
-			dps = m.Metric.Gauge().DataPoints()
-		case pmetric.MetricTypeSum:
-			dps = m.Metric.Sum().DataPoints()
-		default:
-			support = false
+			// Every datapoint must be registered here: a single metric commonly carries
+			// one datapoint per attribute value (e.g. one per dotnet.gc.heap.generation),
+			// and each of those is a separate series on the chart.
+			for dpi := 0; dpi < dps.Len(); dpi++ {
+				// Bind a fresh variable per iteration so the pointers kept in dataMap
+				// don't all alias the same datapoint.
+				dp := dps.At(dpi)
+				seriesPoints = append(seriesPoints, &dp)
+			}
 		}
-		if !support {
-			break
+		// the sum is derived within the series so that the points of the other series
+		// aren't taken as its resets
+		if support && m.Metric.Type() == pmetric.MetricTypeSum {
+			sum := m.Metric.Sum()
+			seriesPoints = deriveSumPoints(seriesPoints, sum.AggregationTemporality(), sum.IsMonotonic(), c.sumMode)
 		}
+		points = append(points, seriesPoints...)
+	}
 
-		// Every datapoint must be registered here: a single metric commonly carries
-		// one datapoint per attribute value (e.g. one per dotnet.gc.heap.generation),
-		// and each of those is a separate series on the chart.
-		for dpi := 0; dpi < dps.Len(); dpi++ {
-			// Bind a fresh variable per iteration so the pointers kept in dataMap
-			// don't all alias the same datapoint.
-			dp := dps.At(dpi)
+	keyMaps := layout.KeyMaps{}
+	if support && m.Metric.Type() == pmetric.MetricTypeSum {
+		c.view.SetTitle(fmt.Sprintf("Chart (c) -- %s", c.sumMode))
+		keyMaps = c.sumModeKeyMaps(ms)
+	}
 
-			dpts := dp.Timestamp().AsTime()
-			if dpts.Before(start) {
-				start = dpts
-			}
-			if dpts.After(end) {
-				end = dpts
-			}
+	for _, dp := range points {
+		dpts := dp.Timestamp().AsTime()
+		if dpts.Before(start) {
+			start = dpts
+		}
+		if dpts.After(end) {
+			end = dpts
+		}
 
-			attrs := dp.Attributes().AsRaw()
-			if len(attrs) == 0 {
-				addToDataMap("N/A", "N/A", &dp)
-				continue
-			}
+		attrs := dp.Attributes().AsRaw()
+		if len(attrs) == 0 {
+			addToDataMap("N/A", "N/A", dp)
+			continue
+		}
 
-			// sort keys
-			keys := make([]string, 0, len(attrs))
-			for k := range attrs {
-				keys = append(keys, k)
-			}
-			sort.Strings(keys)
-			for _, k := range keys {
-				v := attrs[k]
-				addToDataMap(k, fmt.Sprintf("%s", v), &dp)
-			}
+		// sort keys
+		keys := make([]string, 0, len(attrs))
+		for k := range attrs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := attrs[k]
+			addToDataMap(k, fmt.Sprintf("%s", v), dp)
 		}
 	}
 
@@ -259,7 +484,12 @@ func (c *chart) drawMetricNumberChart(m *telemetry.MetricData) layout.KeyMaps {
 	}
 
 	if len(attrkeys) == 0 {
-		return layout.KeyMaps{}
+		if len(keyMaps) > 0 {
+			// e.g. the rate needs two points at least
+			txt := tview.NewTextView().SetText(fmt.Sprintf("No points to draw in the %s view", c.sumMode))
+			c.ch.AddItem(txt, 0, 1, false)
+		}
+		return keyMaps
 	}
 
 	for k := range dataMap {
@@ -289,7 +519,7 @@ func (c *chart) drawMetricNumberChart(m *telemetry.MetricData) layout.KeyMaps {
 	c.ch.AddItem(ch, 0, 7, true).AddItem(legend, 0, 3, false)
 	c.focusTargets = []layout.FocusableBox{ch}
 
-	return layout.KeyMaps{
+	return append(keyMaps, layout.KeyMaps{
 		{
 			Key:         tcell.NewEventKey(tcell.KeyRight, ' ', tcell.ModNone),
 			Hidden:      true,
@@ -326,9 +556,178 @@ func (c *chart) drawMetricNumberChart(m *telemetry.MetricData) layout.KeyMaps {
 				return nil
 			},
 		},
+	}...)
+}
+
+// sumModeKeyMaps returns the key map switching the view of the sum metric
+func (c *chart) sumModeKeyMaps(ms *telemetry.MetricSeries) layout.KeyMaps {
+	return layout.KeyMaps{
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone),
+			Description: "Switch view (Raw / Rate / Delta / Cumulative)",
+			Handler: func(_ *tcell.EventKey) *tcell.EventKey {
+				c.sumMode = c.sumMode.next()
+				c.update(ms)
+				navigation.Focus(c.view)
+				return nil
+			},
+		},
 	}
 }
 
+// drawMetricSummaryChart draws the quantiles of the series of the metric, which share the resource
+// and the scope with the given series and differ only in the data point attributes
+func (c *chart) drawMetricSummaryChart(ms *telemetry.MetricSeries, m *telemetry.MetricData) layout.KeyMaps {
+	points := []*telemetry.MetricData{}
+	for _, series := range c.store.GetMetricSeries(ms) {
+		points = append(points, series.Points()...)
+	}
+
+	// attribute set and quantile label map
+	dataMap := make(map[string]map[string][]*pmetric.NumberDataPoint, 1)
+	latestMap := make(map[string]pmetric.SummaryDataPoint, 1)
+	attrsets := []string{}
+
+	start := time.Unix(1<<63-62135596801, 999999999)
+	end := time.Unix(0, 0)
+	for _, m := range points {
+		if m.Metric.Type() != pmetric.MetricTypeSummary {
+			continue
+		}
+		dps := m.Metric.Summary().DataPoints()
+		for dpi := 0; dpi < dps.Len(); dpi++ {
+			dp := dps.At(dpi)
+			dpts := dp.Timestamp().AsTime()
+			if dpts.Before(start) {
+				start = dpts
+			}
+			if dpts.After(end) {
+				end = dpts
+			}
+
+			attrset := telemetry.AttributesText(dp.Attributes())
+			if _, ok := dataMap[attrset]; !ok {
+				attrsets = append(attrsets, attrset)
+				dataMap[attrset] = map[string][]*pmetric.NumberDataPoint{}
+			}
+			if latest, ok := latestMap[attrset]; !ok || !dpts.Before(latest.Timestamp().AsTime()) {
+				latestMap[attrset] = dp
+			}
+
+			// Each quantile is drawn as a line in the same way as the number data points
+			qvs := dp.QuantileValues()
+			for qi := 0; qi < qvs.Len(); qi++ {
+				qdp := pmetric.NewNumberDataPoint()
+				qdp.SetTimestamp(dp.Timestamp())
+				qdp.SetDoubleValue(qvs.At(qi).Value())
+				label := quantileLabel(qvs.At(qi).Quantile())
+				dataMap[attrset][label] = append(dataMap[attrset][label], &qdp)
+			}
+		}
+	}
+
+	if len(attrsets) == 0 {
+		return layout.KeyMaps{}
+	}
+
+	for k := range dataMap {
+		for kk := range dataMap[k] {
+			sort.Sort(ByTimestamp(dataMap[k][kk]))
+		}
+	}
+
+	// Draw a chart of the attribute set of the selected metric first
+	attrsetidx := 0
+	if m.Metric.Summary().DataPoints().Len() > 0 {
+		attrsetidx = max(slices.Index(attrsets, telemetry.AttributesText(m.Metric.Summary().DataPoints().At(0).Attributes())), 0)
+	}
+
+	ch := tvxwidgets.NewPlot()
+	ch.SetMarker(tvxwidgets.PlotMarkerBraille)
+	ch.SetBorder(true)
+	ch.SetDrawXAxisLabel(false)
+	side := tview.NewFlex().SetDirection(tview.FlexRow)
+
+	draw := func() {
+		attrset := attrsets[attrsetidx]
+		title := attrset
+		if title == "" {
+			title = "N/A"
+		}
+		ch.SetTitle(fmt.Sprintf("%s [%d / %d] ( <- | -> )", title, attrsetidx+1, len(attrsets)))
+		data, txts := c.getDataToDraw(map[string]map[string][]*pmetric.NumberDataPoint{
+			"quantile": dataMap[attrset],
+		}, "quantile", start, end)
+		ch.SetData(data)
+		ch.SetLineColor(lineColors(len(data)))
+
+		stats := summaryStats(latestMap[attrset])
+		sts := tview.NewTextView().SetText(strings.Join(stats, "\n"))
+		sts.SetBorder(true).SetTitle("Statistics")
+		side.Clear().AddItem(txts, 0, 1, false).AddItem(sts, len(stats)+2, 1, false)
+	}
+	draw()
+
+	c.ch.AddItem(ch, 0, 7, true).AddItem(side, 0, 3, false)
+	c.focusTargets = []layout.FocusableBox{ch}
+
+	return layout.KeyMaps{
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRight, ' ', tcell.ModNone),
+			Hidden:      true,
+			Description: "",
+			Handler: func(_ *tcell.EventKey) *tcell.EventKey {
+				if attrsetidx < len(attrsets)-1 {
+					attrsetidx++
+				} else {
+					attrsetidx = 0
+				}
+				draw()
+				return nil
+			},
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyLeft, ' ', tcell.ModNone),
+			Hidden:      true,
+			Description: "",
+			Handler: func(_ *tcell.EventKey) *tcell.EventKey {
+				if attrsetidx > 0 {
+					attrsetidx--
+				} else {
+					attrsetidx = len(attrsets) - 1
+				}
+				draw()
+				return nil
+			},
+		},
+	}
+}
+
+// summaryStats returns the count, the sum and the quantile values of the summary data point
+func summaryStats(dp pmetric.SummaryDataPoint) []string {
+	stats := []string{
+		fmt.Sprintf("● count: %d", dp.Count()),
+		fmt.Sprintf("● sum: %s", strconv.FormatFloat(dp.Sum(), 'f', -1, 64)),
+	}
+	qvs := make([]pmetric.SummaryDataPointValueAtQuantile, 0, dp.QuantileValues().Len())
+	for i := 0; i < dp.QuantileValues().Len(); i++ {
+		qvs = append(qvs, dp.QuantileValues().At(i))
+	}
+	sort.Slice(qvs, func(i, j int) bool {
+		return qvs[i].Quantile() < qvs[j].Quantile()
+	})
+	for _, qv := range qvs {
+		stats = append(stats, fmt.Sprintf("● %s: %s", quantileLabel(qv.Quantile()), strconv.FormatFloat(qv.Value(), 'f', -1, 64)))
+	}
+	return stats
+}
+
+// quantileLabel returns the percentile notation of the quantile (e.g. 0.99 -> p99)
+func quantileLabel(q float64) string {
+	// the precision absorbs the floating point error such as 0.29*100 = 28.999999999999996
+	return "p" + strconv.FormatFloat(q*100, 'g', 6, 64)
+}
+
 func (c *chart) getDataToDraw(dataMap map[string]map[string][]*pmetric.NumberDataPoint, attrkey string, start, end time.Time) ([][]float64, *tview.TextView) {
 	// Sort keys
 	keys := make([]string, 0, len(dataMap[attrkey]))
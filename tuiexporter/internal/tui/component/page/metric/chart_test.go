@@ -7,6 +7,7 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/jonboulle/clockwork"
+	"github.com/navidys/tvxwidgets"
 	"github.com/rivo/tview"
 	"github.com/stretchr/testify/assert"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
@@ -18,31 +19,27 @@ import (
 
 func TestDrawMetricHistogramChart(t *testing.T) {
 	tests := []struct {
-		name         string
-		metricDataFn func() *telemetry.MetricData
-		want         string
+		name      string
+		payloadFn func() pmetric.Metrics
+		want      string
 	}{
 		{
 			name: "with bounds",
-			metricDataFn: func() *telemetry.MetricData {
-				_, m := test.GenerateOTLPHistogramMetricsPayload(t, 1, []int{1}, [][]int{{1}})
+			payloadFn: func() pmetric.Metrics {
+				payload, _ := test.GenerateOTLPHistogramMetricsPayload(t, 1, []int{1}, [][]int{{1}})
 
-				return &telemetry.MetricData{
-					Metric: m.Metrics[0],
-				}
+				return payload
 			},
 			want: test.LoadTestdata(t, "tui/component/page/metric/chart/with_bounds.txt"),
 		},
 		{
 			name: "without bounds",
-			metricDataFn: func() *telemetry.MetricData {
-				_, m := test.GenerateOTLPHistogramMetricsPayload(t, 1, []int{1}, [][]int{{1}})
+			payloadFn: func() pmetric.Metrics {
+				payload, m := test.GenerateOTLPHistogramMetricsPayload(t, 1, []int{1}, [][]int{{1}})
 				m.Metrics[0].Histogram().DataPoints().At(0).BucketCounts().FromRaw([]uint64{10})
 				m.Metrics[0].Histogram().DataPoints().At(0).ExplicitBounds().FromRaw([]float64{})
 
-				return &telemetry.MetricData{
-					Metric: m.Metrics[0],
-				}
+				return payload
 			},
 			want: test.LoadTestdata(t, "tui/component/page/metric/chart/without_bounds.txt"),
 		},
@@ -57,8 +54,12 @@ func TestDrawMetricHistogramChart(t *testing.T) {
 			}
 			screen.SetSize(sw, sh)
 
-			chart := newChart(layout.NewCommandList(), nil, []*layout.ResizeManager{})
-			chart.update(tt.metricDataFn())
+			store := telemetry.NewStore(clockwork.NewRealClock())
+			payload := tt.payloadFn()
+			store.AddMetric(&payload)
+
+			chart := newChart(layout.NewCommandList(), store, []*layout.ResizeManager{})
+			chart.update(store.GetFilteredMetricByIdx(0))
 
 			chart.view.SetRect(0, 0, sw, sh)
 			chart.view.Draw(screen)
@@ -72,17 +73,17 @@ func TestDrawMetricHistogramChart(t *testing.T) {
 }
 
 func TestChartInputCaptureAfterFlush(t *testing.T) {
-	_, m := test.GenerateOTLPHistogramMetricsPayload(t, 1, []int{1}, [][]int{{1}})
-	metric := &telemetry.MetricData{
-		Metric: m.Metrics[0],
-	}
+	store := telemetry.NewStore(clockwork.NewRealClock())
+	payload, _ := test.GenerateOTLPHistogramMetricsPayload(t, 1, []int{1}, [][]int{{1}})
+	store.AddMetric(&payload)
+	series := store.GetFilteredMetricByIdx(0)
 
-	chart := newChart(layout.NewCommandList(), nil, []*layout.ResizeManager{})
-	chart.update(metric)
+	chart := newChart(layout.NewCommandList(), store, []*layout.ResizeManager{})
+	chart.update(series)
 
 	chart.flush()
 
-	chart.update(metric)
+	chart.update(series)
 
 	gotInputCapture := chart.ch.GetInputCapture()
 	assert.NotNil(t, gotInputCapture)
@@ -131,23 +132,18 @@ func TestDrawMetricNumberChartWithManyDataPoints(t *testing.T) {
 	// Add 11 separate metrics with unique attribute values (> 10 colors)
 	// Each metric has one data point with a unique "dp index" attribute
 	dpCount := 11
-	var lastMetric *telemetry.MetricData
 	for i := 0; i < dpCount; i++ {
-		payload, m := test.GenerateOTLPGaugeMetricsPayload(t, 1, []int{1}, [][]int{{1}})
+		payload, _ := test.GenerateOTLPGaugeMetricsPayload(t, 1, []int{1}, [][]int{{1}})
 		// Clear default attributes and set unique attribute value
 		dp := payload.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0)
 		dp.Attributes().Clear()
 		dp.Attributes().PutInt("series", int64(i))
 		dp.SetTimestamp(pcommon.NewTimestampFromTime(mockClock.Now().Add(time.Duration(i) * time.Second)))
 		store.AddMetric(&payload)
-		lastMetric = &telemetry.MetricData{
-			Metric:         m.Metrics[0],
-			ResourceMetric: m.RMetrics[0],
-		}
 	}
 
 	chart := newChart(layout.NewCommandList(), store, []*layout.ResizeManager{})
-	chart.update(lastMetric)
+	chart.update(store.GetFilteredMetricByIdx(dpCount - 1))
 
 	// Legend is second item, contains TextView with one line per data series
 	legend := chart.ch.GetItem(1).(*tview.Flex)
@@ -159,10 +155,10 @@ func TestDrawMetricNumberChartWithManyDataPoints(t *testing.T) {
 // generationMetricPayload builds a single metric carrying one data point per given
 // attribute value, mirroring how the .NET runtime reports metrics such as
 // dotnet.gc.collections (one data point per dotnet.gc.heap.generation).
-func generationMetricPayload(t *testing.T, ts time.Time, isSum bool, gens []string) (pmetric.Metrics, *telemetry.MetricData) {
+func generationMetricPayload(t *testing.T, ts time.Time, isSum bool, gens []string) pmetric.Metrics {
 	t.Helper()
 
-	payload, m := test.GenerateOTLPGaugeMetricsPayload(t, 1, []int{1}, [][]int{{1}})
+	payload, _ := test.GenerateOTLPGaugeMetricsPayload(t, 1, []int{1}, [][]int{{1}})
 	metric := payload.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
 
 	var dps pmetric.NumberDataPointSlice
@@ -178,10 +174,7 @@ func generationMetricPayload(t *testing.T, ts time.Time, isSum bool, gens []stri
 		dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	}
 
-	return payload, &telemetry.MetricData{
-		Metric:         m.Metrics[0],
-		ResourceMetric: m.RMetrics[0],
-	}
+	return payload
 }
 
 // A single metric carrying several data points that differ only by attribute value
@@ -213,17 +206,15 @@ func TestDrawMetricNumberChartWithMultipleDataPointsInOneMetric(t *testing.T) {
 
 			// Two exports of the same metric, so the series must accumulate over time
 			// rather than overwrite each other.
-			var selected *telemetry.MetricData
 			for i := range 2 {
-				payload, md := generationMetricPayload(
+				payload := generationMetricPayload(
 					t, mockClock.Now().Add(time.Duration(i)*time.Second), tt.isSum, tt.gens,
 				)
 				store.AddMetric(&payload)
-				selected = md
 			}
 
 			chart := newChart(layout.NewCommandList(), store, []*layout.ResizeManager{})
-			chart.update(selected)
+			chart.update(store.GetFilteredMetricByIdx(0))
 
 			legend := chart.ch.GetItem(1).(*tview.Flex)
 			tv := legend.GetItem(0).(*tview.TextView)
@@ -252,7 +243,7 @@ func TestDrawMetricNumberChartWithoutDataPoints(t *testing.T) {
 			mockClock := clockwork.NewFakeClockAt(time.Date(2025, 11, 9, 12, 15, 0, 0, time.UTC))
 			store := telemetry.NewStore(mockClock)
 
-			payload, m := test.GenerateOTLPGaugeMetricsPayload(t, 1, []int{1}, [][]int{{1}})
+			payload, _ := test.GenerateOTLPGaugeMetricsPayload(t, 1, []int{1}, [][]int{{1}})
 			metric := payload.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
 			if tt.isSum {
 				metric.SetEmptySum()
@@ -261,10 +252,8 @@ func TestDrawMetricNumberChartWithoutDataPoints(t *testing.T) {
 			}
 			store.AddMetric(&payload)
 
-			selected := &telemetry.MetricData{
-				Metric:         m.Metrics[0],
-				ResourceMetric: m.RMetrics[0],
-			}
+			// the metric without data points has no series to select
+			selected := store.GetFilteredMetricByIdx(0)
 
 			chart := newChart(layout.NewCommandList(), store, []*layout.ResizeManager{})
 
@@ -275,3 +264,202 @@ func TestDrawMetricNumberChartWithoutDataPoints(t *testing.T) {
 		})
 	}
 }
+
+// summaryMetricPayload builds a summary metric carrying one data point per given route, whose
+// quantile values are multiplied by the given factor.
+func summaryMetricPayload(t *testing.T, ts time.Time, factor float64, routes []string) pmetric.Metrics {
+	t.Helper()
+
+	payload, _ := test.GenerateOTLPGaugeMetricsPayload(t, 1, []int{1}, [][]int{{1}})
+	metric := payload.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+
+	dps := metric.SetEmptySummary().DataPoints()
+	for _, route := range routes {
+		dp := dps.AppendEmpty()
+		dp.SetCount(uint64(factor * 10))
+		dp.SetSum(factor * 100)
+		for _, q := range []float64{0.99, 0.5, 0.9} {
+			qv := dp.QuantileValues().AppendEmpty()
+			qv.SetQuantile(q)
+			qv.SetValue(factor * q * 10)
+		}
+		dp.Attributes().PutStr("http.route", route)
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	}
+
+	return payload
+}
+
+func TestDrawMetricSummaryChart(t *testing.T) {
+	mockClock := clockwork.NewFakeClockAt(time.Date(2025, 11, 9, 12, 15, 0, 0, time.UTC))
+	store := telemetry.NewStore(mockClock)
+
+	for i := range 2 {
+		payload := summaryMetricPayload(
+			t, mockClock.Now().Add(time.Duration(i)*time.Second), float64(i+1), []string{"/users", "/orders"},
+		)
+		store.AddMetric(&payload)
+	}
+
+	chart := newChart(layout.NewCommandList(), store, []*layout.ResizeManager{})
+	chart.update(store.GetFilteredMetricByIdx(0))
+
+	ch := chart.ch.GetItem(0).(*tvxwidgets.Plot)
+	assert.Equal(t, "http.route=/users [1 / 2] ( <- | -> )", ch.GetTitle())
+
+	side := chart.ch.GetItem(1).(*tview.Flex)
+	legend := side.GetItem(0).(*tview.TextView).GetText(true)
+	assert.Equal(t, "● quantile: p50\n● quantile: p90\n● quantile: p99", legend)
+
+	stats := side.GetItem(1).(*tview.TextView).GetText(true)
+	assert.Equal(t, "● count: 20\n● sum: 200\n● p50: 10\n● p90: 18\n● p99: 19.8", stats)
+
+	got := chart.ch.GetInputCapture()(tcell.NewEventKey(tcell.KeyRight, ' ', tcell.ModNone))
+	assert.Nil(t, got)
+	assert.Equal(t, "http.route=/orders [2 / 2] ( <- | -> )", ch.GetTitle())
+}
+
+func TestDrawMetricSummaryChartWithResourcesOfSameService(t *testing.T) {
+	mockClock := clockwork.NewFakeClockAt(time.Date(2025, 11, 9, 12, 15, 0, 0, time.UTC))
+	store := telemetry.NewStore(mockClock)
+
+	// two instances of the service report the summary of different values at the same time
+	payload := summaryMetricPayload(t, mockClock.Now(), 1, []string{"/users"})
+	payload.ResourceMetrics().At(0).Resource().Attributes().PutStr("service.instance.id", "1")
+	other := summaryMetricPayload(t, mockClock.Now(), 2, []string{"/users"})
+	other.ResourceMetrics().At(0).Resource().Attributes().PutStr("service.instance.id", "2")
+	other.ResourceMetrics().MoveAndAppendTo(payload.ResourceMetrics())
+	store.AddMetric(&payload)
+
+	chart := newChart(layout.NewCommandList(), store, []*layout.ResizeManager{})
+	chart.update(store.GetFilteredMetricByIdx(0))
+
+	ch := chart.ch.GetItem(0).(*tvxwidgets.Plot)
+	assert.Equal(t, "http.route=/users [1 / 1] ( <- | -> )", ch.GetTitle())
+
+	// the statistics are of the selected instance only
+	side := chart.ch.GetItem(1).(*tview.Flex)
+	stats := side.GetItem(1).(*tview.TextView).GetText(true)
+	assert.Equal(t, "● count: 10\n● sum: 100\n● p50: 5\n● p90: 9\n● p99: 9.9", stats)
+}
+
+func TestDrawMetricSumChartSwitchView(t *testing.T) {
+	mockClock := clockwork.NewFakeClockAt(time.Date(2025, 11, 9, 12, 15, 0, 0, time.UTC))
+	store := telemetry.NewStore(mockClock)
+
+	gens := []string{"gen0", "gen1"}
+	for i := range 2 {
+		payload := generationMetricPayload(t, mockClock.Now().Add(time.Duration(i)*time.Second), true, gens)
+		store.AddMetric(&payload)
+	}
+
+	chart := newChart(layout.NewCommandList(), store, []*layout.ResizeManager{})
+	chart.update(store.GetFilteredMetricByIdx(0))
+	assert.Equal(t, "Chart (c) -- Raw", chart.view.GetTitle())
+
+	got := chart.ch.GetInputCapture()(tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone))
+	assert.Nil(t, got)
+	assert.Equal(t, sumModeRate, chart.sumMode)
+	assert.Equal(t, "Chart (c) -- Rate (/s)", chart.view.GetTitle())
+
+	legend := chart.ch.GetItem(1).(*tview.Flex)
+	text := legend.GetItem(0).(*tview.TextView).GetText(false)
+	for _, gen := range gens {
+		assert.Contains(t, text, "dotnet.gc.heap.generation: "+gen)
+	}
+
+	// the view is kept while the title is reset for the other metrics
+	chart.update(nil)
+	assert.Equal(t, "Chart (c)", chart.view.GetTitle())
+	assert.Equal(t, sumModeRate, chart.sumMode)
+}
+
+func TestDrawMetricSumChartWithResourcesOfSameService(t *testing.T) {
+	mockClock := clockwork.NewFakeClockAt(time.Date(2025, 11, 9, 12, 15, 0, 0, time.UTC))
+	store := telemetry.NewStore(mockClock)
+
+	// two instances of the service report the counter once
+	payload := generationMetricPayload(t, mockClock.Now(), true, []string{"gen0"})
+	payload.ResourceMetrics().At(0).Resource().Attributes().PutStr("service.instance.id", "1")
+	other := payload.ResourceMetrics().AppendEmpty()
+	payload.ResourceMetrics().At(0).CopyTo(other)
+	other.Resource().Attributes().PutStr("service.instance.id", "2")
+	store.AddMetric(&payload)
+
+	chart := newChart(layout.NewCommandList(), store, []*layout.ResizeManager{})
+	chart.sumMode = sumModeDelta
+	chart.update(store.GetFilteredMetricByIdx(0))
+
+	// the point of the other instance isn't taken as the previous point of the counter
+	txt := chart.ch.GetItem(0).(*tview.TextView)
+	assert.Equal(t, "No points to draw in the Delta view", txt.GetText(true))
+}
+
+func TestDrawMetricHistogramHeatmap(t *testing.T) {
+	mockClock := clockwork.NewFakeClockAt(time.Date(2025, 11, 9, 12, 15, 0, 0, time.UTC))
+	store := telemetry.NewStore(mockClock)
+
+	for i := range 2 {
+		payload, _ := test.GenerateOTLPHistogramMetricsPayload(t, 1, []int{1}, [][]int{{1}})
+		dp := payload.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Histogram().DataPoints().At(0)
+		dp.BucketCounts().FromRaw([]uint64{0, uint64(10 * (i + 1)), uint64(20 * (i + 1)), uint64(5 * (i + 1)), 0})
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(mockClock.Now().Add(time.Duration(i) * time.Second)))
+		store.AddMetric(&payload)
+	}
+
+	chart := newChart(layout.NewCommandList(), store, []*layout.ResizeManager{})
+	chart.update(store.GetFilteredMetricByIdx(0))
+	assert.Equal(t, "Chart (c)", chart.view.GetTitle())
+
+	got := chart.ch.GetInputCapture()(tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone))
+	assert.Nil(t, got)
+	assert.Equal(t, "Chart (c) -- Heatmap", chart.view.GetTitle())
+
+	hm := chart.ch.GetItem(0).(*tview.Box)
+	assert.Equal(t, "dp index=0 [1 / 1] ( <- | -> )", hm.GetTitle())
+
+	// the increase from the first point is drawn
+	side := chart.ch.GetItem(1).(*tview.Flex)
+	stats := side.GetItem(0).(*tview.TextView).GetText(true)
+	assert.Equal(t, "● points: 1\n━ p50: 13.8\n━ p95: 26.5\n━ p99: 29.3", stats)
+
+	got = chart.ch.GetInputCapture()(tcell.NewEventKey(tcell.KeyRune, 'v', tcell.ModNone))
+	assert.Nil(t, got)
+	assert.Equal(t, "Chart (c)", chart.view.GetTitle())
+	assert.IsType(t, &tvxwidgets.BarChart{}, chart.ch.GetItem(0))
+}
+
+func TestDrawMetricHistogramHeatmapWithResourcesOfSameService(t *testing.T) {
+	mockClock := clockwork.NewFakeClockAt(time.Date(2025, 11, 9, 12, 15, 0, 0, time.UTC))
+	store := telemetry.NewStore(mockClock)
+
+	// two instances of the service report the histogram of different counts
+	for i := range 2 {
+		payload, _ := test.GenerateOTLPHistogramMetricsPayload(t, 1, []int{1}, [][]int{{1}})
+		rm := payload.ResourceMetrics().At(0)
+		rm.Resource().Attributes().PutStr("service.instance.id", "1")
+		dp := rm.ScopeMetrics().At(0).Metrics().At(0).Histogram().DataPoints().At(0)
+		dp.BucketCounts().FromRaw([]uint64{0, uint64(10 * (i + 1)), uint64(20 * (i + 1)), uint64(5 * (i + 1)), 0})
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(mockClock.Now().Add(time.Duration(i) * time.Second)))
+
+		other := payload.ResourceMetrics().AppendEmpty()
+		rm.CopyTo(other)
+		other.Resource().Attributes().PutStr("service.instance.id", "2")
+		other.ScopeMetrics().At(0).Metrics().At(0).Histogram().DataPoints().At(0).BucketCounts().FromRaw(
+			[]uint64{0, 0, 0, uint64(100 * (i + 1)), uint64(100 * (i + 1))},
+		)
+		store.AddMetric(&payload)
+	}
+
+	chart := newChart(layout.NewCommandList(), store, []*layout.ResizeManager{})
+	chart.histogramHeatmap = true
+	chart.update(store.GetFilteredMetricByIdx(0))
+
+	hm := chart.ch.GetItem(0).(*tview.Box)
+	assert.Equal(t, "dp index=0 [1 / 1] ( <- | -> )", hm.GetTitle())
+
+	// the increase between the points of the selected instance is drawn
+	side := chart.ch.GetItem(1).(*tview.Flex)
+	stats := side.GetItem(0).(*tview.TextView).GetText(true)
+	assert.Equal(t, "● points: 1\n━ p50: 13.8\n━ p95: 26.5\n━ p99: 29.3", stats)
+}
@@ -0,0 +1,199 @@
+package metric
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// heatmapQuantile is a quantile drawn over the heatmap as a line
+type heatmapQuantile struct {
+	q     float64
+	color tcell.Color
+}
+
+var heatmapQuantiles = []heatmapQuantile{
+	{q: 0.5, color: tcell.ColorWhite},
+	{q: 0.95, color: tcell.ColorAqua},
+	{q: 0.99, color: tcell.ColorFuchsia},
+}
+
+// heatmap draws how the distribution of the histogram shifts over time. Each column is the
+// increase of the bucket counts at a point in the timestamp order, and each row is a bucket
+// from the lowest (bottom) to the highest (top). The cell is brighter as the count is larger.
+type heatmap struct {
+	view   *tview.Box
+	bounds []float64
+	// columns is the bucket counts of each point
+	columns [][]uint64
+	// lines is the bucket index of each quantile in heatmapQuantiles at each point, or -1 if
+	// the point has no counts
+	lines    [][]int
+	maxCount uint64
+}
+
+func newHeatmap() *heatmap {
+	h := &heatmap{}
+	h.view = tview.NewBox().SetBorder(true)
+	h.view.SetDrawFunc(h.draw)
+	return h
+}
+
+// update replaces the bucket counts drawn in the heatmap
+func (h *heatmap) update(bounds []float64, columns [][]uint64) {
+	h.bounds = bounds
+	h.columns = columns
+	h.lines = make([][]int, len(columns))
+	h.maxCount = 0
+	for i, counts := range columns {
+		for _, c := range counts {
+			h.maxCount = max(h.maxCount, c)
+		}
+		buckets := explicitBuckets(bounds, counts)
+		h.lines[i] = make([]int, len(heatmapQuantiles))
+		for qi, hq := range heatmapQuantiles {
+			h.lines[i][qi] = -1
+			if _, idx, ok := bucketQuantileIndex(buckets, hq.q); ok {
+				h.lines[i][qi] = idx
+			}
+		}
+	}
+}
+
+func (h *heatmap) draw(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+	// inside of the border
+	x, y, width, height = x+1, y+1, width-2, height-2
+	rows := len(h.bounds) + 1
+	labelWidth := 0
+	for i := range rows {
+		labelWidth = max(labelWidth, len(h.label(i)))
+	}
+	plotX, plotWidth := x+labelWidth+1, width-labelWidth-1
+	if plotWidth <= 0 || height <= 0 || len(h.columns) == 0 {
+		return x, y, width, height
+	}
+
+	// the latest points are shown if the columns do not fit in the width
+	columns, lines := h.columns, h.lines
+	if len(columns) > plotWidth {
+		columns, lines = columns[len(columns)-plotWidth:], lines[len(lines)-plotWidth:]
+	}
+	cellWidth := plotWidth / len(columns)
+	// the highest buckets are cut off if the rows do not fit in the height
+	cellHeight := max(height/rows, 1)
+
+	for row := range rows {
+		top := y + height - (row+1)*cellHeight
+		if top < y {
+			break
+		}
+		tview.Print(screen, h.label(row), x, top+cellHeight/2, labelWidth, tview.AlignRight, tview.Styles.PrimaryTextColor)
+		for col, counts := range columns {
+			style := tcell.StyleDefault
+			if row < len(counts) && counts[row] > 0 {
+				style = style.Background(heatColor(float64(counts[row]) / float64(h.maxCount)))
+			}
+			r := ' '
+			// the line of the highest quantile is drawn on top
+			for qi, hq := range heatmapQuantiles {
+				if lines[col][qi] == row {
+					r = tview.BoxDrawingsHeavyHorizontal
+					style = style.Foreground(hq.color)
+				}
+			}
+			for cy := top; cy < top+cellHeight; cy++ {
+				for cx := plotX + col*cellWidth; cx < plotX+(col+1)*cellWidth; cx++ {
+					if cy == top+cellHeight/2 {
+						screen.SetContent(cx, cy, r, nil, style)
+					} else {
+						screen.SetContent(cx, cy, ' ', nil, style)
+					}
+				}
+			}
+		}
+	}
+
+	return x, y, width, height
+}
+
+// label returns the upper bound of the bucket at the row
+func (h *heatmap) label(row int) string {
+	if row >= len(h.bounds) {
+		return "+Inf"
+	}
+	return formatBound(h.bounds[row])
+}
+
+// heatColor returns the color of the cell from dark blue (few) to red (many) by the ratio
+// of the count to the max count
+func heatColor(ratio float64) tcell.Color {
+	ratio = min(max(ratio, 0), 1)
+	return tcell.NewRGBColor(
+		int32(48+207*ratio),
+		int32(32*ratio),
+		int32(128*(1-ratio)),
+	)
+}
+
+// histogramDeltas returns the explicit bounds and the increase of the bucket counts at each
+// point in the timestamp order. The bounds are those of the latest point, and the points with
+// other bounds are skipped. In the cumulative temporality, the first point is skipped as its
+// increase is unknown, and a change of the start timestamp or a decrease of any count is a
+// reset where the whole counts are the increase since the new start.
+func histogramDeltas(
+	dps []pmetric.HistogramDataPoint,
+	temporality pmetric.AggregationTemporality,
+) ([]float64, [][]uint64) {
+	if len(dps) == 0 {
+		return []float64{}, [][]uint64{}
+	}
+	dps = append([]pmetric.HistogramDataPoint{}, dps...)
+	sort.SliceStable(dps, func(i, j int) bool {
+		return dps[i].Timestamp() < dps[j].Timestamp()
+	})
+
+	bounds := dps[len(dps)-1].ExplicitBounds().AsRaw()
+	columns := [][]uint64{}
+	var prev []uint64
+	var prevStart uint64
+	for _, dp := range dps {
+		counts := dp.BucketCounts().AsRaw()
+		if len(counts) != len(bounds)+1 || !slices.Equal(dp.ExplicitBounds().AsRaw(), bounds) {
+			prev = nil
+			continue
+		}
+		if temporality == pmetric.AggregationTemporalityDelta {
+			columns = append(columns, counts)
+			continue
+		}
+
+		start := uint64(dp.StartTimestamp())
+		switch {
+		case prev == nil:
+			// the first point
+		case start != prevStart || isCountsReset(prev, counts):
+			columns = append(columns, counts)
+		default:
+			deltas := make([]uint64, len(counts))
+			for i := range counts {
+				deltas[i] = counts[i] - prev[i]
+			}
+			columns = append(columns, deltas)
+		}
+		prev, prevStart = counts, start
+	}
+
+	return bounds, columns
+}
+
+func isCountsReset(prev, counts []uint64) bool {
+	for i := range counts {
+		if counts[i] < prev[i] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package metric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestHistogramDeltas(t *testing.T) {
+	base := time.Date(2025, 11, 9, 12, 15, 0, 0, time.UTC)
+	newPoint := func(start, ts time.Duration, bounds []float64, counts []uint64) pmetric.HistogramDataPoint {
+		dp := pmetric.NewHistogramDataPoint()
+		dp.SetStartTimestamp(pcommon.NewTimestampFromTime(base.Add(start)))
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(base.Add(ts)))
+		dp.ExplicitBounds().FromRaw(bounds)
+		dp.BucketCounts().FromRaw(counts)
+		return dp
+	}
+	bounds := []float64{10, 20}
+
+	tests := []struct {
+		name        string
+		dps         []pmetric.HistogramDataPoint
+		temporality pmetric.AggregationTemporality
+		want        [][]uint64
+	}{
+		{
+			name: "cumulative with resets",
+			dps: []pmetric.HistogramDataPoint{
+				newPoint(0, 2*time.Second, bounds, []uint64{3, 5, 1}),
+				newPoint(0, 1*time.Second, bounds, []uint64{1, 2, 0}),
+				// the start is changed
+				newPoint(3*time.Second, 4*time.Second, bounds, []uint64{1, 0, 0}),
+				// the count is decreased
+				newPoint(3*time.Second, 5*time.Second, bounds, []uint64{0, 1, 0}),
+				newPoint(3*time.Second, 6*time.Second, bounds, []uint64{2, 1, 0}),
+			},
+			temporality: pmetric.AggregationTemporalityCumulative,
+			want: [][]uint64{
+				{2, 3, 1},
+				{1, 0, 0},
+				{0, 1, 0},
+				{2, 0, 0},
+			},
+		},
+		{
+			name: "cumulative with other bounds",
+			dps: []pmetric.HistogramDataPoint{
+				newPoint(0, 1*time.Second, []float64{5}, []uint64{1, 1}),
+				newPoint(0, 2*time.Second, bounds, []uint64{1, 2, 0}),
+				newPoint(0, 3*time.Second, bounds, []uint64{2, 2, 0}),
+			},
+			temporality: pmetric.AggregationTemporalityCumulative,
+			want: [][]uint64{
+				{1, 0, 0},
+			},
+		},
+		{
+			name: "delta",
+			dps: []pmetric.HistogramDataPoint{
+				newPoint(1*time.Second, 2*time.Second, bounds, []uint64{0, 4, 0}),
+				newPoint(0, 1*time.Second, bounds, []uint64{1, 2, 0}),
+			},
+			temporality: pmetric.AggregationTemporalityDelta,
+			want: [][]uint64{
+				{1, 2, 0},
+				{0, 4, 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotBounds, got := histogramDeltas(tt.dps, tt.temporality)
+
+			assert.Equal(t, bounds, gotBounds)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	hm := newHeatmap()
+	hm.update([]float64{10, 20}, [][]uint64{
+		{98, 1, 1},
+		{0, 4, 0},
+		{0, 0, 0},
+	})
+
+	assert.Equal(t, uint64(98), hm.maxCount)
+	assert.Equal(t, [][]int{
+		{0, 0, 1},
+		{1, 1, 1},
+		{-1, -1, -1},
+	}, hm.lines)
+
+	// the quantiles in the overflow bucket are on the +Inf row
+	overflow := newHeatmap()
+	overflow.update([]float64{10, 20}, [][]uint64{{0, 1, 9}})
+	assert.Equal(t, [][]int{{2, 2, 2}}, overflow.lines)
+
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to initialize screen: %v", err)
+	}
+	screen.SetSize(22, 5)
+	// the inner rect is 20x3 and the plot is 15x3 next to the labels, where each column is
+	// 5 cells wide and each bucket is 1 cell high
+	hm.view.SetRect(0, 0, 22, 5)
+	hm.view.Draw(screen)
+
+	tests := []struct {
+		name   string
+		x, y   int
+		r      rune
+		fg, bg tcell.Color
+	}{
+		{name: "label of the lowest bucket", x: 4, y: 3, r: '0'},
+		{name: "label of the highest bucket", x: 1, y: 1, r: '+'},
+		{name: "p95 over p50", x: 6, y: 3, r: '━', fg: tcell.ColorAqua, bg: heatColor(1)},
+		{name: "p99", x: 6, y: 2, r: '━', fg: tcell.ColorFuchsia, bg: heatColor(1.0 / 98)},
+		{name: "empty bucket", x: 11, y: 3, r: ' ', fg: tcell.ColorDefault, bg: tcell.ColorDefault},
+		{name: "point without counts", x: 16, y: 2, r: ' ', fg: tcell.ColorDefault, bg: tcell.ColorDefault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _, style, _ := screen.GetContent(tt.x, tt.y)
+			assert.Equal(t, tt.r, r)
+			if tt.r == '0' || tt.r == '+' {
+				return
+			}
+			fg, bg, _ := style.Decompose()
+			assert.Equal(t, tt.fg, fg)
+			assert.Equal(t, tt.bg, bg)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package metric
+
+import (
+	"math"
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// bucket is a histogram bucket counting the values from lower to upper
+type bucket struct {
+	lower float64
+	upper float64
+	count uint64
+}
+
+// exponentialBuckets decodes the buckets of the exponential histogram data point in the ascending
+// order of the values, which are the negative buckets, the zero bucket and the positive buckets.
+// The empty buckets at both ends are trimmed.
+func exponentialBuckets(dp pmetric.ExponentialHistogramDataPoint) []bucket {
+	buckets := []bucket{}
+
+	neg := dp.Negative()
+	for i := neg.BucketCounts().Len() - 1; i >= 0; i-- {
+		index := int(neg.Offset()) + i
+		buckets = append(buckets, bucket{
+			lower: -exponentialBound(index+1, dp.Scale()),
+			upper: -exponentialBound(index, dp.Scale()),
+			count: neg.BucketCounts().At(i),
+		})
+	}
+
+	zero := len(buckets)
+	buckets = append(buckets, bucket{
+		lower: -dp.ZeroThreshold(),
+		upper: dp.ZeroThreshold(),
+		count: dp.ZeroCount(),
+	})
+
+	pos := dp.Positive()
+	for i := 0; i < pos.BucketCounts().Len(); i++ {
+		index := int(pos.Offset()) + i
+		buckets = append(buckets, bucket{
+			lower: exponentialBound(index, dp.Scale()),
+			upper: exponentialBound(index+1, dp.Scale()),
+			count: pos.BucketCounts().At(i),
+		})
+	}
+
+	start, end := 0, len(buckets)
+	for start < end && buckets[start].count == 0 {
+		start++
+	}
+	for end > start && buckets[end-1].count == 0 {
+		end--
+	}
+	if start == end {
+		// no observations, only the zero bucket is shown
+		return buckets[zero : zero+1]
+	}
+	return buckets[start:end]
+}
+
+// explicitBuckets returns the buckets of the explicit bounds. The first bucket starts from zero
+// (or its upper bound if negative) and the last bucket is treated as its lower bound, because
+// the values beyond the bounds are unknown.
+func explicitBuckets(bounds []float64, counts []uint64) []bucket {
+	buckets := make([]bucket, len(counts))
+	for i, count := range counts {
+		b := bucket{count: count}
+		switch {
+		case len(bounds) == 0:
+		case i == 0:
+			b.lower, b.upper = math.Min(0, bounds[0]), bounds[0]
+		case i >= len(bounds):
+			b.lower, b.upper = bounds[len(bounds)-1], bounds[len(bounds)-1]
+		default:
+			b.lower, b.upper = bounds[i-1], bounds[i]
+		}
+		buckets[i] = b
+	}
+	return buckets
+}
+
+// exponentialBound returns the lower bound of the bucket at the index, which is base^index
+// where base = 2^(2^-scale)
+func exponentialBound(index int, scale int32) float64 {
+	return math.Exp2(float64(index) * math.Exp2(-float64(scale)))
+}
+
+// bucketQuantile estimates the q-quantile of the values counted in the buckets by the linear
+// interpolation within the bucket. It returns false if the buckets have no counts.
+func bucketQuantile(buckets []bucket, q float64) (float64, bool) {
+	v, _, ok := bucketQuantileIndex(buckets, q)
+	return v, ok
+}
+
+// bucketQuantileIndex is bucketQuantile that also returns the index of the bucket the quantile
+// falls in. The index tells the overflow bucket apart from the one below it, whose upper bound
+// is the same value.
+func bucketQuantileIndex(buckets []bucket, q float64) (float64, int, bool) {
+	var total uint64
+	for _, b := range buckets {
+		total += b.count
+	}
+	if total == 0 {
+		return 0, 0, false
+	}
+
+	rank := q * float64(total)
+	var cumulative float64
+	for i, b := range buckets {
+		if b.count == 0 {
+			continue
+		}
+		next := cumulative + float64(b.count)
+		if rank <= next {
+			return b.lower + (b.upper-b.lower)*(rank-cumulative)/float64(b.count), i, true
+		}
+		cumulative = next
+	}
+	return buckets[len(buckets)-1].upper, len(buckets) - 1, true
+}
+
+// formatBound returns the bucket bound in three significant digits
+func formatBound(v float64) string {
+	return strconv.FormatFloat(v, 'g', 3, 64)
+}
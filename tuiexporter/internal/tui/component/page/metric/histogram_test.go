@@ -0,0 +1,177 @@
+package metric
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/assert"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/layout"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestExponentialBound(t *testing.T) {
+	assert.Equal(t, 1.0, exponentialBound(0, 0))
+	assert.Equal(t, 8.0, exponentialBound(3, 0))
+	assert.Equal(t, 0.25, exponentialBound(-2, 0))
+	assert.InDelta(t, math.Sqrt2, exponentialBound(1, 1), 1e-9)
+	assert.Equal(t, 16.0, exponentialBound(1, -2))
+}
+
+func TestExponentialBuckets(t *testing.T) {
+	tests := []struct {
+		name string
+		fn   func(dp pmetric.ExponentialHistogramDataPoint)
+		want []bucket
+	}{
+		{
+			name: "positive buckets with offset",
+			fn: func(dp pmetric.ExponentialHistogramDataPoint) {
+				dp.Positive().SetOffset(1)
+				dp.Positive().BucketCounts().FromRaw([]uint64{1, 2})
+			},
+			want: []bucket{
+				{lower: 2, upper: 4, count: 1},
+				{lower: 4, upper: 8, count: 2},
+			},
+		},
+		{
+			name: "negative, zero and positive buckets",
+			fn: func(dp pmetric.ExponentialHistogramDataPoint) {
+				dp.SetZeroThreshold(0.5)
+				dp.SetZeroCount(4)
+				dp.Negative().BucketCounts().FromRaw([]uint64{3, 1})
+				dp.Positive().BucketCounts().FromRaw([]uint64{2})
+			},
+			want: []bucket{
+				{lower: -4, upper: -2, count: 1},
+				{lower: -2, upper: -1, count: 3},
+				{lower: -0.5, upper: 0.5, count: 4},
+				{lower: 1, upper: 2, count: 2},
+			},
+		},
+		{
+			name: "empty buckets at both ends are trimmed",
+			fn: func(dp pmetric.ExponentialHistogramDataPoint) {
+				dp.Negative().BucketCounts().FromRaw([]uint64{0})
+				dp.Positive().BucketCounts().FromRaw([]uint64{0, 5, 0, 1, 0})
+			},
+			want: []bucket{
+				{lower: 2, upper: 4, count: 5},
+				{lower: 4, upper: 8, count: 0},
+				{lower: 8, upper: 16, count: 1},
+			},
+		},
+		{
+			name: "no observations",
+			fn: func(dp pmetric.ExponentialHistogramDataPoint) {
+				dp.Positive().BucketCounts().FromRaw([]uint64{0, 0})
+			},
+			want: []bucket{
+				{lower: 0, upper: 0, count: 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dp := pmetric.NewExponentialHistogramDataPoint()
+			tt.fn(dp)
+
+			assert.Equal(t, tt.want, exponentialBuckets(dp))
+		})
+	}
+}
+
+func TestBucketQuantile(t *testing.T) {
+	buckets := []bucket{
+		{lower: 0, upper: 10, count: 5},
+		{lower: 10, upper: 20, count: 0},
+		{lower: 20, upper: 30, count: 5},
+	}
+
+	got, ok := bucketQuantile(buckets, 0.5)
+	assert.True(t, ok)
+	assert.Equal(t, 10.0, got)
+
+	got, ok = bucketQuantile(buckets, 0.9)
+	assert.True(t, ok)
+	assert.Equal(t, 28.0, got)
+
+	_, ok = bucketQuantile([]bucket{{lower: 0, upper: 10}}, 0.5)
+	assert.False(t, ok)
+}
+
+func TestDrawMetricExponentialHistogramChart(t *testing.T) {
+	payload := pmetric.NewMetrics()
+	m := payload.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	m.SetName("latency")
+	dp := m.SetEmptyExponentialHistogram().DataPoints().AppendEmpty()
+	dp.SetScale(0)
+	dp.SetCount(5)
+	dp.SetSum(9)
+	dp.SetMin(0)
+	dp.SetMax(3.5)
+	dp.SetZeroCount(1)
+	dp.Positive().BucketCounts().FromRaw([]uint64{2, 2})
+	dp.Attributes().PutStr("http.route", "/users")
+
+	store := telemetry.NewStore(clockwork.NewRealClock())
+	store.AddMetric(&payload)
+
+	chart := newChart(layout.NewCommandList(), store, []*layout.ResizeManager{})
+	chart.update(store.GetFilteredMetricByIdx(0))
+
+	assert.Equal(t, 2, chart.ch.GetItemCount())
+	assert.Len(t, chart.focusTargets, 1)
+
+	stats := chart.ch.GetItem(1).(*tview.Flex).GetItem(0).(*tview.Flex)
+	got := []string{}
+	for i := range stats.GetItemCount() {
+		got = append(got, stats.GetItem(i).(*tview.TextView).GetText(true))
+	}
+	assert.Equal(t, []string{
+		"● count: 5",
+		"● sum: 9",
+		"● min: 0",
+		"● max: 3.5",
+		"● zero count: 1",
+		"● p50: 1.75",
+		"● p90: 3.5",
+		"● p99: 3.5",
+	}, got)
+}
+
+func TestExplicitBuckets(t *testing.T) {
+	assert.Equal(t, []bucket{
+		{lower: 0, upper: 10, count: 1},
+		{lower: 10, upper: 20, count: 2},
+		{lower: 20, upper: 20, count: 3},
+	}, explicitBuckets([]float64{10, 20}, []uint64{1, 2, 3}))
+	assert.Equal(t, []bucket{
+		{lower: -5, upper: -5, count: 1},
+		{lower: -5, upper: -5, count: 2},
+	}, explicitBuckets([]float64{-5}, []uint64{1, 2}))
+	assert.Equal(t, []bucket{{count: 5}}, explicitBuckets([]float64{}, []uint64{5}))
+}
+
+func TestBucketQuantileIndex(t *testing.T) {
+	buckets := explicitBuckets([]float64{10, 20}, []uint64{1, 0, 9})
+
+	got, idx, ok := bucketQuantileIndex(buckets, 0.05)
+	assert.True(t, ok)
+	assert.Equal(t, 5.0, got)
+	assert.Equal(t, 0, idx)
+
+	// the quantile in the overflow bucket is its lower bound, but the index is not the one
+	// of the bucket below
+	got, idx, ok = bucketQuantileIndex(buckets, 0.5)
+	assert.True(t, ok)
+	assert.Equal(t, 20.0, got)
+	assert.Equal(t, 2, idx)
+
+	_, _, ok = bucketQuantileIndex(explicitBuckets([]float64{10}, []uint64{0, 0}), 0.5)
+	assert.False(t, ok)
+}
@@ -0,0 +1,158 @@
+package metric
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// sumMode is the view of the sum metric on the chart
+type sumMode int
+
+const (
+	// sumModeRaw shows the values as reported
+	sumModeRaw sumMode = iota
+	// sumModeRate shows the increase per second
+	sumModeRate
+	// sumModeDelta shows the increase from the previous point
+	sumModeDelta
+	// sumModeCumulative shows the running total
+	sumModeCumulative
+)
+
+func (m sumMode) String() string {
+	switch m {
+	case sumModeRate:
+		return "Rate (/s)"
+	case sumModeDelta:
+		return "Delta"
+	case sumModeCumulative:
+		return "Cumulative"
+	}
+	return "Raw"
+}
+
+func (m sumMode) next() sumMode {
+	return (m + 1) % (sumModeCumulative + 1)
+}
+
+// sumDelta is the increase of the sum at the point
+type sumDelta struct {
+	point    *pmetric.NumberDataPoint
+	value    float64
+	interval time.Duration
+	// first is true if the point is the first one of a cumulative series, whose increase
+	// is unknown as the value is accumulated since the start
+	first bool
+}
+
+// deriveSumPoints converts the points of the sum into the given view. The points are grouped into
+// the series by the attributes, and each series is derived in the timestamp order.
+func deriveSumPoints(
+	points []*pmetric.NumberDataPoint,
+	temporality pmetric.AggregationTemporality,
+	monotonic bool,
+	mode sumMode,
+) []*pmetric.NumberDataPoint {
+	if mode == sumModeRaw {
+		return points
+	}
+
+	keys := []string{}
+	groups := map[string][]*pmetric.NumberDataPoint{}
+	for _, dp := range points {
+		key := telemetry.AttributesText(dp.Attributes())
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], dp)
+	}
+
+	derived := make([]*pmetric.NumberDataPoint, 0, len(points))
+	for _, key := range keys {
+		dps := groups[key]
+		sort.Sort(ByTimestamp(dps))
+
+		var total float64
+		for _, d := range sumDeltas(dps, temporality, monotonic) {
+			var v float64
+			switch mode {
+			case sumModeRate:
+				if d.first || d.interval <= 0 {
+					continue
+				}
+				v = d.value / d.interval.Seconds()
+			case sumModeDelta:
+				if d.first {
+					continue
+				}
+				v = d.value
+			case sumModeCumulative:
+				total += d.value
+				v = total
+			}
+			dp := pmetric.NewNumberDataPoint()
+			d.point.CopyTo(dp)
+			dp.SetDoubleValue(v)
+			derived = append(derived, &dp)
+		}
+	}
+
+	return derived
+}
+
+// sumDeltas returns the increase at each point of the series sorted by the timestamp.
+// In the cumulative temporality, a change of the start timestamp is a reset of the counter,
+// and the whole value is the increase since the new start. A decrease of the value is also
+// a reset in the monotonic sum, while it is a negative increase in the non-monotonic one.
+func sumDeltas(
+	dps []*pmetric.NumberDataPoint,
+	temporality pmetric.AggregationTemporality,
+	monotonic bool,
+) []sumDelta {
+	deltas := make([]sumDelta, 0, len(dps))
+	for i, dp := range dps {
+		v := numberValue(dp)
+		ts := dp.Timestamp().AsTime()
+		var prev *pmetric.NumberDataPoint
+		if i > 0 {
+			prev = dps[i-1]
+		}
+
+		// the interval since the start, or since the previous point if the start is unknown
+		interval := time.Duration(0)
+		if dp.StartTimestamp() != 0 && dp.StartTimestamp() < dp.Timestamp() {
+			interval = ts.Sub(dp.StartTimestamp().AsTime())
+		} else if prev != nil {
+			interval = ts.Sub(prev.Timestamp().AsTime())
+		}
+
+		if temporality == pmetric.AggregationTemporalityDelta {
+			deltas = append(deltas, sumDelta{point: dp, value: v, interval: interval})
+			continue
+		}
+
+		switch {
+		case prev == nil:
+			deltas = append(deltas, sumDelta{point: dp, value: v, first: true})
+		case prev.StartTimestamp() != dp.StartTimestamp() || (monotonic && v < numberValue(prev)):
+			deltas = append(deltas, sumDelta{point: dp, value: v, interval: interval})
+		default:
+			deltas = append(deltas, sumDelta{
+				point:    dp,
+				value:    v - numberValue(prev),
+				interval: ts.Sub(prev.Timestamp().AsTime()),
+			})
+		}
+	}
+	return deltas
+}
+
+func numberValue(dp *pmetric.NumberDataPoint) float64 {
+	if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		return float64(dp.IntValue())
+	}
+	return dp.DoubleValue()
+}
@@ -0,0 +1,154 @@
+package metric
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestSumModeNext(t *testing.T) {
+	mode := sumModeRaw
+	got := []string{}
+	for range 5 {
+		mode = mode.next()
+		got = append(got, mode.String())
+	}
+	assert.Equal(t, []string{"Rate (/s)", "Delta", "Cumulative", "Raw", "Rate (/s)"}, got)
+}
+
+func TestDeriveSumPoints(t *testing.T) {
+	base := time.Date(2025, 11, 9, 12, 15, 0, 0, time.UTC)
+	newPoint := func(route string, start, ts time.Duration, v int64) *pmetric.NumberDataPoint {
+		dp := pmetric.NewNumberDataPoint()
+		dp.SetStartTimestamp(pcommon.NewTimestampFromTime(base.Add(start)))
+		dp.SetTimestamp(pcommon.NewTimestampFromTime(base.Add(ts)))
+		dp.SetIntValue(v)
+		dp.Attributes().PutStr("http.route", route)
+		return &dp
+	}
+	// the counter of /users is reset at 25s
+	cumulative := []*pmetric.NumberDataPoint{
+		newPoint("/users", 0, 10*time.Second, 10),
+		newPoint("/orders", 0, 10*time.Second, 100),
+		newPoint("/users", 25*time.Second, 30*time.Second, 5),
+		newPoint("/orders", 0, 20*time.Second, 150),
+		newPoint("/users", 0, 20*time.Second, 30),
+	}
+	// the value of the up-down counter decreases at 20s without a reset
+	upDown := []*pmetric.NumberDataPoint{
+		newPoint("/users", 0, 10*time.Second, 10),
+		newPoint("/users", 0, 30*time.Second, 15),
+		newPoint("/users", 0, 20*time.Second, 4),
+	}
+	delta := []*pmetric.NumberDataPoint{
+		newPoint("/users", 10*time.Second, 20*time.Second, 6),
+		newPoint("/users", 0, 10*time.Second, 4),
+	}
+
+	tests := []struct {
+		name        string
+		points      []*pmetric.NumberDataPoint
+		temporality pmetric.AggregationTemporality
+		monotonic   bool
+		mode        sumMode
+		want        map[string][]float64
+	}{
+		{
+			name:        "cumulative to rate",
+			points:      cumulative,
+			temporality: pmetric.AggregationTemporalityCumulative,
+			monotonic:   true,
+			mode:        sumModeRate,
+			want: map[string][]float64{
+				"http.route=/users":  {2, 1},
+				"http.route=/orders": {5},
+			},
+		},
+		{
+			name:        "cumulative to delta",
+			points:      cumulative,
+			temporality: pmetric.AggregationTemporalityCumulative,
+			monotonic:   true,
+			mode:        sumModeDelta,
+			want: map[string][]float64{
+				"http.route=/users":  {20, 5},
+				"http.route=/orders": {50},
+			},
+		},
+		{
+			name:        "cumulative continues over the reset",
+			points:      cumulative,
+			temporality: pmetric.AggregationTemporalityCumulative,
+			monotonic:   true,
+			mode:        sumModeCumulative,
+			want: map[string][]float64{
+				"http.route=/users":  {10, 30, 35},
+				"http.route=/orders": {100, 150},
+			},
+		},
+		{
+			name:        "non-monotonic cumulative to delta",
+			points:      upDown,
+			temporality: pmetric.AggregationTemporalityCumulative,
+			mode:        sumModeDelta,
+			want: map[string][]float64{
+				"http.route=/users": {-6, 11},
+			},
+		},
+		{
+			name:        "non-monotonic cumulative to rate",
+			points:      upDown,
+			temporality: pmetric.AggregationTemporalityCumulative,
+			mode:        sumModeRate,
+			want: map[string][]float64{
+				"http.route=/users": {-0.6, 1.1},
+			},
+		},
+		{
+			name:        "delta to cumulative",
+			points:      delta,
+			temporality: pmetric.AggregationTemporalityDelta,
+			monotonic:   true,
+			mode:        sumModeCumulative,
+			want: map[string][]float64{
+				"http.route=/users": {4, 10},
+			},
+		},
+		{
+			name:        "delta to rate",
+			points:      delta,
+			temporality: pmetric.AggregationTemporalityDelta,
+			monotonic:   true,
+			mode:        sumModeRate,
+			want: map[string][]float64{
+				"http.route=/users": {0.4, 0.6},
+			},
+		},
+		{
+			name:        "raw",
+			points:      delta,
+			temporality: pmetric.AggregationTemporalityDelta,
+			monotonic:   true,
+			mode:        sumModeRaw,
+			want: map[string][]float64{
+				"http.route=/users": {6, 4},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string][]float64{}
+			for _, dp := range deriveSumPoints(tt.points, tt.temporality, tt.monotonic, tt.mode) {
+				key := telemetry.AttributesText(dp.Attributes())
+				got[key] = append(got[key], numberValue(dp))
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
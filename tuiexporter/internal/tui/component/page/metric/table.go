@@ -6,6 +6,7 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/export"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/filter"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/layout"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/navigation"
@@ -40,22 +41,26 @@ func newTable(
 	filter := filter.NewFilter(
 		commands,
 		"Filter by service or metric name (/): ",
-		func(inputConfirmed string, _ telemetry.SortType) {
+		func(inputConfirmed string, _ telemetry.SortType) error {
 			store.ApplyFilterMetrics(inputConfirmed)
+			return nil
 		},
 		func() {
 			navigation.Focus(t)
 		},
 		nil,
-		func(inputConfirmed string, _ telemetry.SortType) {
+		func(inputConfirmed string, _ telemetry.SortType) error {
 			store.ApplyFilterMetrics(inputConfirmed)
+			return nil
 		},
 	)
 
-	metricData := ctable.NewMetricDataForTable(store.GetFilteredMetrics())
+	metricData := ctable.NewMetricDataForTable(store.SnapshotMetrics)
 	t.SetContent(&metricData)
+	ctable.RefreshOnDraw(t, &metricData)
 	store.SetOnMetricAdded(func() {
 		if detail.tree.GetRoot() == nil {
+			metricData.Refresh()
 			// Select the first data row (row 1), not the header (row 0)
 			t.Select(1, 0)
 		}
@@ -73,11 +78,11 @@ func newTable(
 
 	t.SetSelectionChangedFunc(stable.onSelectionChangedFunc())
 
-	container.
-		AddItem(filter.View(), 1, 0, false).
+	filter.AddTo(container).
 		AddItem(t, 0, 1, true)
 
 	stable.registerCommands(commands, resizeManagers)
+	ctable.RefreshOnInput(t, &metricData)
 
 	return stable
 }
@@ -94,6 +99,14 @@ func (t *table) registerCommands(commands *tview.TextView, resizeManagers []*lay
 				return nil
 			},
 		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, 'S', tcell.ModNone),
+			Description: "Save",
+			Handler: func(_ *tcell.EventKey) *tcell.EventKey {
+				export.SaveAndNotify(commands, "metrics", "metrics", t.store.ExportFilteredMetrics)
+				return nil
+			},
+		},
 		{
 			Key:         tcell.NewEventKey(tcell.KeyCtrlX, ' ', tcell.ModNone),
 			Description: "Clear all data",
@@ -115,11 +128,18 @@ func (t *table) onSelectionChangedFunc() func(row, col int) {
 		if row == 0 {
 			return
 		}
-		selected := t.store.GetFilteredMetricByIdx(row - 1)
+		// the table may not be drawn since the store was updated
+		t.metricData.Refresh()
+		selected := t.metricData.At(row - 1)
 		if selected == nil {
 			return
 		}
-		t.detail.update(selected)
+		// the details show the latest point and the chart shows the points of the series
+		latest := selected.Latest()
+		if latest == nil {
+			return
+		}
+		t.detail.update(latest)
 		t.chart.update(selected)
 		log.Printf("selected row(original): %d", row)
 	}
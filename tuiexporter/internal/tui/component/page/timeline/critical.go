@@ -0,0 +1,61 @@
+package timeline
+
+import (
+	"sort"
+	"time"
+)
+
+// markCriticalPath marks the spans on the critical path, the chain of spans which determined
+// the end-to-end latency of the trace, and sets how long each of them contributed to it.
+// The critical path goes back from the end of the trace taking the last finished span at
+// each point. Children which finished after their parent are clamped to the parent.
+func markCriticalPath(roots []*spanTreeNode, start, end time.Time) {
+	markCriticalChildren(roots, start, end)
+}
+
+// markCriticalChildren marks the critical spans among the sibling nodes within the window
+// and returns the time covered by them
+func markCriticalChildren(nodes []*spanTreeNode, start, end time.Time) (covered time.Duration) {
+	sorted := make([]*spanTreeNode, len(nodes))
+	copy(sorted, nodes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].span.Span.EndTimestamp().AsTime().After(
+			sorted[j].span.Span.EndTimestamp().AsTime(),
+		)
+	})
+
+	cursor := end
+	for _, n := range sorted {
+		if !cursor.After(start) {
+			break
+		}
+		nst, nen := n.span.Span.StartTimestamp().AsTime(), n.span.Span.EndTimestamp().AsTime()
+		if !nst.Before(cursor) {
+			continue
+		}
+		wst, wen := maxTime(nst, start), minTime(nen, cursor)
+		markCriticalNode(n, wst, wen)
+		covered += wen.Sub(wst)
+		cursor = wst
+	}
+	return covered
+}
+
+func markCriticalNode(node *spanTreeNode, start, end time.Time) {
+	node.critical = true
+	node.contribution = end.Sub(start) - markCriticalChildren(node.children, start, end)
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
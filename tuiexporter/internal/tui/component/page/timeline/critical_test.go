@@ -0,0 +1,70 @@
+package timeline
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"gotest.tools/v3/assert"
+)
+
+var testSpanTreeBase = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newTestSpanTreeNode(name string, startMs, endMs int, children ...*spanTreeNode) *spanTreeNode {
+	rs := ptrace.NewResourceSpans()
+	span := rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.SetName(name)
+	// the name is unique in the tests
+	var spanID pcommon.SpanID
+	copy(spanID[:], name)
+	span.SetSpanID(spanID)
+	span.SetStartTimestamp(pcommon.NewTimestampFromTime(testSpanTreeBase.Add(time.Duration(startMs) * time.Millisecond)))
+	span.SetEndTimestamp(pcommon.NewTimestampFromTime(testSpanTreeBase.Add(time.Duration(endMs) * time.Millisecond)))
+	return &spanTreeNode{
+		span:     &telemetry.SpanData{Span: &span, ResourceSpan: &rs},
+		children: children,
+		start:    time.Duration(startMs) * time.Millisecond,
+		end:      time.Duration(endMs) * time.Millisecond,
+	}
+}
+
+func TestMarkCriticalPath(t *testing.T) {
+	// root     [0, 100]
+	//  ├- a    [0, 40]
+	//  ├- b    [30, 90]
+	//  │  └- e [40, 70]
+	//  ├- c    [50, 60]
+	//  └- d    [95, 120] finished after the parent
+	// other    [10, 20] another root span
+	e := newTestSpanTreeNode("e", 40, 70)
+	a := newTestSpanTreeNode("a", 0, 40)
+	b := newTestSpanTreeNode("b", 30, 90, e)
+	c := newTestSpanTreeNode("c", 50, 60)
+	d := newTestSpanTreeNode("d", 95, 120)
+	root := newTestSpanTreeNode("root", 0, 100, a, b, c, d)
+	other := newTestSpanTreeNode("other", 10, 20)
+
+	markCriticalPath([]*spanTreeNode{root, other}, testSpanTreeBase, testSpanTreeBase.Add(120*time.Millisecond))
+
+	tests := []struct {
+		node             *spanTreeNode
+		wantCritical     bool
+		wantContribution time.Duration
+	}{
+		{node: root, wantCritical: true, wantContribution: 5 * time.Millisecond},
+		{node: a, wantCritical: true, wantContribution: 30 * time.Millisecond},
+		{node: b, wantCritical: true, wantContribution: 30 * time.Millisecond},
+		{node: c, wantCritical: false},
+		{node: d, wantCritical: true, wantContribution: 5 * time.Millisecond},
+		{node: e, wantCritical: true, wantContribution: 30 * time.Millisecond},
+		{node: other, wantCritical: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.node.span.Span.Name(), func(t *testing.T) {
+			assert.Equal(t, tt.wantCritical, tt.node.critical)
+			assert.Equal(t, tt.wantContribution, tt.node.contribution)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package timeline
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
@@ -15,12 +16,32 @@ type detail struct {
 	commands      *tview.TextView
 	view          *tview.Flex
 	tree          *tview.TreeView
+	eventNodes    []*tview.TreeNode
+	tcache        *telemetry.TraceCache
 	resizeManager *layout.ResizeManager
+	onSelectLink  func(traceID, spanID string)
+	onSelectSpan  func(spanID string)
+	selfTimes     *selfTimeCache
 }
 
+// selfTimeCache holds the self times of the spans in the shown trace so that moving
+// the cursor doesn't recompute them for the whole trace
+type selfTimeCache struct {
+	traceID   string
+	spanCount int
+	sorted    []telemetry.SpanSelfTime
+	bySpanID  map[string]time.Duration
+}
+
+// topSelfTimeCount is the number of spans listed in the top self time of the trace
+const topSelfTimeCount = 5
+
 func newDetail(
 	commands *tview.TextView,
+	tcache *telemetry.TraceCache,
 	resizeManager *layout.ResizeManager,
+	onSelectLink func(traceID, spanID string),
+	onSelectSpan func(spanID string),
 ) *detail {
 	container := tview.NewFlex().SetDirection(tview.FlexRow)
 	container.SetTitle("Details (d)").SetBorder(true)
@@ -28,7 +49,10 @@ func newDetail(
 	detail := &detail{
 		commands:      commands,
 		view:          container,
+		tcache:        tcache,
 		resizeManager: resizeManager,
+		onSelectLink:  onSelectLink,
+		onSelectSpan:  onSelectSpan,
 	}
 
 	detail.update(nil)
@@ -38,6 +62,7 @@ func newDetail(
 
 func (d *detail) update(span *telemetry.SpanData) {
 	d.view.Clear()
+	d.eventNodes = nil
 	d.tree = d.getSpanInfoTree(span)
 	d.updateCommands()
 	d.view.AddItem(d.tree, 0, 1, true)
@@ -95,6 +120,14 @@ func (d *detail) getSpanInfoTree(span *telemetry.SpanData) *tview.TreeView {
 	durationNode := tview.NewTreeNode(fmt.Sprintf("duration: %s", duration.String()))
 	root.AddChild(durationNode)
 
+	selfTime := duration
+	selfTimes := d.getSelfTimes(traceID)
+	if st, ok := selfTimes.bySpanID[spanID]; ok {
+		selfTime = st
+	}
+	selfTimeNode := tview.NewTreeNode(fmt.Sprintf("self time: %s", selfTime.String()))
+	root.AddChild(selfTimeNode)
+
 	startTime := datetime.GetFullTime(span.Span.StartTimestamp().AsTime())
 	startTimeNode := tview.NewTreeNode(fmt.Sprintf("start time: %s", startTime))
 	root.AddChild(startTimeNode)
@@ -117,6 +150,9 @@ func (d *detail) getSpanInfoTree(span *telemetry.SpanData) *tview.TreeView {
 		event := span.Span.Events().At(ei)
 		name := event.Name()
 		eventNode := tview.NewTreeNode(name)
+		if name == exceptionEventName {
+			eventNode.SetColor(tcell.ColorRed)
+		}
 
 		timestamp := datetime.GetFullTime(event.Timestamp().AsTime())
 		timestampNode := tview.NewTreeNode(fmt.Sprintf("timestamp: %s", timestamp))
@@ -131,6 +167,7 @@ func (d *detail) getSpanInfoTree(span *telemetry.SpanData) *tview.TreeView {
 		eventNode.AddChild(attrs)
 
 		events.AddChild(eventNode)
+		d.eventNodes = append(d.eventNodes, eventNode)
 	}
 	root.AddChild(events)
 
@@ -138,9 +175,9 @@ func (d *detail) getSpanInfoTree(span *telemetry.SpanData) *tview.TreeView {
 	links := tview.NewTreeNode("Links")
 	for li := 0; li < span.Span.Links().Len(); li++ {
 		link := span.Span.Links().At(li)
-		linkNode := tview.NewTreeNode(fmt.Sprintf("link %d", li))
-
 		linkTraceID := link.TraceID().String()
+		linkNode := d.newLinkNode(li, linkTraceID, link.SpanID().String())
+
 		linkTraceIDNode := tview.NewTreeNode(fmt.Sprintf("trace id: %s", linkTraceID))
 		linkNode.AddChild(linkTraceIDNode)
 
@@ -168,6 +205,13 @@ func (d *detail) getSpanInfoTree(span *telemetry.SpanData) *tview.TreeView {
 	}
 	root.AddChild(links)
 
+	// spans having the longest self time in the trace
+	topSelfTime := tview.NewTreeNode("Top Self Time")
+	for _, st := range selfTimes.sorted[:min(topSelfTimeCount, len(selfTimes.sorted))] {
+		topSelfTime.AddChild(d.newSelfTimeNode(st.Span, st.SelfTime))
+	}
+	root.AddChild(topSelfTime)
+
 	// resource info
 	rs := span.ResourceSpan
 	r := rs.Resource()
@@ -210,6 +254,80 @@ func (d *detail) getSpanInfoTree(span *telemetry.SpanData) *tview.TreeView {
 	return tree
 }
 
+// focusEvent moves the cursor in the tree to the event at the index of the span
+func (d *detail) focusEvent(idx int) {
+	if idx < 0 || idx >= len(d.eventNodes) {
+		return
+	}
+	d.tree.SetCurrentNode(d.eventNodes[idx])
+}
+
+// newLinkNode returns the node of the span link. The linked trace is opened by selecting
+// the node if it is in the cache.
+func (d *detail) newLinkNode(idx int, traceID, spanID string) *tview.TreeNode {
+	node := tview.NewTreeNode(fmt.Sprintf("link %d", idx))
+	if d.tcache == nil || d.onSelectLink == nil {
+		return node
+	}
+	if _, ok := d.tcache.GetSpansByTraceID(traceID); !ok {
+		return node
+	}
+
+	if span, ok := d.tcache.GetSpanByID(spanID); ok && span.Span.TraceID().String() == traceID {
+		node.SetText(fmt.Sprintf("link %d → %s (%s)", idx, span.Span.Name(), span.GetServiceName()))
+	} else {
+		node.SetText(fmt.Sprintf("link %d → trace", idx))
+	}
+	node.SetColor(tcell.ColorAqua).
+		SetSelectedFunc(func() {
+			d.onSelectLink(traceID, spanID)
+		})
+
+	return node
+}
+
+// getSelfTimes returns the self times of the trace. They are computed only when the trace
+// differs from the cached one or has received spans since.
+func (d *detail) getSelfTimes(traceID string) *selfTimeCache {
+	if d.tcache == nil {
+		return &selfTimeCache{}
+	}
+	spans, _ := d.tcache.GetSpansByTraceID(traceID)
+	if d.selfTimes != nil && d.selfTimes.traceID == traceID && d.selfTimes.spanCount == len(spans) {
+		return d.selfTimes
+	}
+
+	sorted, _ := d.tcache.GetSelfTimesByTraceID(traceID)
+	bySpanID := make(map[string]time.Duration, len(sorted))
+	for _, st := range sorted {
+		bySpanID[st.Span.Span.SpanID().String()] = st.SelfTime
+	}
+	d.selfTimes = &selfTimeCache{
+		traceID:   traceID,
+		spanCount: len(spans),
+		sorted:    sorted,
+		bySpanID:  bySpanID,
+	}
+
+	return d.selfTimes
+}
+
+// newSelfTimeNode returns the node of the span in the top self time. The cursor in the timeline
+// jumps to the span by selecting the node.
+func (d *detail) newSelfTimeNode(span *telemetry.SpanData, selfTime time.Duration) *tview.TreeNode {
+	node := tview.NewTreeNode(fmt.Sprintf("%s %s (%s)", span.Span.Name(), selfTime.String(), span.GetServiceName()))
+	if d.onSelectSpan == nil {
+		return node
+	}
+	spanID := span.Span.SpanID().String()
+	node.SetColor(tcell.ColorAqua).
+		SetSelectedFunc(func() {
+			d.onSelectSpan(spanID)
+		})
+
+	return node
+}
+
 func (d *detail) updateCommands() {
 	keyMaps := layout.KeyMaps{
 		{
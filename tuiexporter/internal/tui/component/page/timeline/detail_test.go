@@ -18,7 +18,7 @@ func TestDetailInputCaptureAfterModalClosed(t *testing.T) {
 		ScopeSpans:   testdata.SSpans[0],
 	}
 
-	detail := newDetail(layout.NewCommandList(), layout.NewResizeManager(layout.ResizeDirectionHorizontal))
+	detail := newDetail(layout.NewCommandList(), telemetry.NewTraceCache(), layout.NewResizeManager(layout.ResizeDirectionHorizontal), nil, nil)
 	detail.update(span)
 
 	sw, sh := 55, 10
@@ -43,3 +43,35 @@ func TestDetailInputCaptureAfterModalClosed(t *testing.T) {
 	// resize key should be captured
 	assert.Nil(t, got)
 }
+
+func TestDetailGetSelfTimesCachedPerTrace(t *testing.T) {
+	_, testdata := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{2}})
+	tcache := telemetry.NewTraceCache()
+	sd := &telemetry.SpanData{
+		Span:         testdata.Spans[0],
+		ResourceSpan: testdata.RSpans[0],
+		ScopeSpans:   testdata.SSpans[0],
+	}
+	tcache.UpdateCache("test-service-1", sd)
+	traceID := sd.Span.TraceID().String()
+
+	detail := newDetail(layout.NewCommandList(), tcache, layout.NewResizeManager(layout.ResizeDirectionHorizontal), nil, nil)
+
+	got := detail.getSelfTimes(traceID)
+	assert.Len(t, got.sorted, 1)
+	assert.Contains(t, got.bySpanID, sd.Span.SpanID().String())
+
+	// the same trace reuses the computed self times
+	assert.Same(t, got, detail.getSelfTimes(traceID))
+
+	// a new span in the trace recomputes them
+	tcache.UpdateCache("test-service-1", &telemetry.SpanData{
+		Span:         testdata.Spans[1],
+		ResourceSpan: testdata.RSpans[0],
+		ScopeSpans:   testdata.SSpans[0],
+	})
+	updated := detail.getSelfTimes(traceID)
+	assert.NotSame(t, got, updated)
+	assert.Len(t, updated.sorted, 2)
+	assert.Contains(t, updated.bySpanID, testdata.Spans[1].SpanID().String())
+}
@@ -0,0 +1,279 @@
+package timeline
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/layout"
+)
+
+// flame is the icicle graph of the span tree. Each row is a depth of the tree and the width
+// of the span is proportional to its duration, which is more readable than the timeline for
+// the traces fanning out widely.
+type flame struct {
+	commands *tview.TextView
+	view     *tview.Box
+	start    time.Time
+	duration time.Duration
+	// rows is the spans of each depth ordered by the start time
+	rows    [][]*spanTreeNode
+	parents map[*spanTreeNode]*spanTreeNode
+	// depth and idx is the position of the cursor in rows
+	depth         int
+	idx           int
+	rowOffset     int
+	detail        *detail
+	logPane       *logPane
+	resizeManager *layout.ResizeManager
+}
+
+func newFlame(
+	commands *tview.TextView,
+	resizeManager *layout.ResizeManager,
+	detail *detail,
+	logPane *logPane,
+) *flame {
+	f := &flame{
+		commands:      commands,
+		parents:       map[*spanTreeNode]*spanTreeNode{},
+		detail:        detail,
+		logPane:       logPane,
+		resizeManager: resizeManager,
+	}
+	f.view = tview.NewBox().SetBorder(true).SetTitle("Flame Graph (t)")
+	f.view.SetDrawFunc(f.draw)
+	f.updateCommands()
+
+	return f
+}
+
+// update replaces the span tree drawn in the graph and moves the cursor to the first root span
+func (f *flame) update(roots []*spanTreeNode) {
+	f.rows = nil
+	f.parents = map[*spanTreeNode]*spanTreeNode{}
+	f.depth, f.idx, f.rowOffset = 0, 0, 0
+
+	var end time.Time
+	for i, n := range roots {
+		if i == 0 || n.span.Span.StartTimestamp().AsTime().Before(f.start) {
+			f.start = n.span.Span.StartTimestamp().AsTime()
+		}
+	}
+	f.addRow(roots, 0, &end)
+	f.duration = end.Sub(f.start)
+	for _, row := range f.rows {
+		sort.SliceStable(row, func(i, j int) bool {
+			return row[i].span.Span.StartTimestamp().AsTime().Before(
+				row[j].span.Span.StartTimestamp().AsTime(),
+			)
+		})
+	}
+}
+
+func (f *flame) addRow(nodes []*spanTreeNode, depth int, end *time.Time) {
+	if len(nodes) == 0 {
+		return
+	}
+	if len(f.rows) <= depth {
+		f.rows = append(f.rows, []*spanTreeNode{})
+	}
+	for _, n := range nodes {
+		f.rows[depth] = append(f.rows[depth], n)
+		if en := n.span.Span.EndTimestamp().AsTime(); en.After(*end) {
+			*end = en
+		}
+		for _, c := range n.children {
+			f.parents[c] = n
+		}
+		f.addRow(n.children, depth+1, end)
+	}
+}
+
+func (f *flame) draw(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+	// inside of the border
+	x, y, width, height = x+1, y+1, width-2, height-2
+	if width <= 0 || height <= 0 {
+		return x, y, width, height
+	}
+
+	// scroll vertically to show the cursor
+	if f.depth < f.rowOffset {
+		f.rowOffset = f.depth
+	} else if f.depth >= f.rowOffset+height {
+		f.rowOffset = f.depth - height + 1
+	}
+
+	current := f.getCurrentNode()
+	for depth := f.rowOffset; depth < len(f.rows) && depth-f.rowOffset < height; depth++ {
+		cy := y + depth - f.rowOffset
+		for _, n := range f.rows[depth] {
+			style := tcell.StyleDefault.Background(n.color).Foreground(tcell.ColorBlack)
+			if n == current {
+				style = tcell.StyleDefault.Background(tcell.ColorWhite).Foreground(tcell.ColorBlack).Bold(true)
+			}
+			f.drawSpan(screen, n, x, cy, width, style)
+		}
+	}
+
+	return x, y, width, height
+}
+
+// drawSpan draws the span as a block with its name. The block is at least one cell wide
+// to be selectable however short the span is.
+func (f *flame) drawSpan(screen tcell.Screen, n *spanTreeNode, x, y, width int, style tcell.Style) {
+	sx, ex := x, x+width
+	if f.duration > 0 {
+		st := n.span.Span.StartTimestamp().AsTime().Sub(f.start)
+		en := n.span.Span.EndTimestamp().AsTime().Sub(f.start)
+		sx = x + getXByRatio(float64(st)/float64(f.duration), width)
+		ex = x + getXByRatio(float64(en)/float64(f.duration), width)
+	}
+	sx = min(sx, x+width-1)
+	ex = min(max(ex, sx+1), x+width)
+
+	label := []rune(n.span.Span.Name())
+	for cx := sx; cx < ex; cx++ {
+		r := ' '
+		if i := cx - sx; i < len(label) {
+			r = label[i]
+		}
+		screen.SetContent(cx, y, r, nil, style)
+	}
+}
+
+func (f *flame) getCurrentNode() *spanTreeNode {
+	if f.depth < 0 || f.depth >= len(f.rows) || f.idx < 0 || f.idx >= len(f.rows[f.depth]) {
+		return nil
+	}
+	return f.rows[f.depth][f.idx]
+}
+
+func (f *flame) getCurrentSpan() *telemetry.SpanData {
+	if n := f.getCurrentNode(); n != nil {
+		return n.span
+	}
+	return nil
+}
+
+// selectSpan moves the cursor to the span and returns it, or returns nil if the span
+// is not in the graph
+func (f *flame) selectSpan(spanID string) *telemetry.SpanData {
+	for depth, row := range f.rows {
+		for idx, n := range row {
+			if n.span.Span.SpanID().String() == spanID {
+				f.depth, f.idx = depth, idx
+				return n.span
+			}
+		}
+	}
+	return nil
+}
+
+func (f *flame) moveTo(node *spanTreeNode) {
+	for depth, row := range f.rows {
+		for idx, n := range row {
+			if n == node {
+				f.depth, f.idx = depth, idx
+				f.updateCurrentSpan()
+				return
+			}
+		}
+	}
+}
+
+// stepBy moves the cursor to the next (step > 0) or previous (step < 0) span in the same depth
+func (f *flame) stepBy(step int) func(_ *tcell.EventKey) *tcell.EventKey {
+	return func(_ *tcell.EventKey) *tcell.EventKey {
+		if f.getCurrentNode() == nil {
+			return nil
+		}
+		next := f.idx + step
+		if next < 0 || next >= len(f.rows[f.depth]) {
+			return nil
+		}
+		f.idx = next
+		f.updateCurrentSpan()
+
+		return nil
+	}
+}
+
+// goToParent moves the cursor to the parent span
+func (f *flame) goToParent(_ *tcell.EventKey) *tcell.EventKey {
+	if parent, ok := f.parents[f.getCurrentNode()]; ok {
+		f.moveTo(parent)
+	}
+	return nil
+}
+
+// goToChild moves the cursor to the first child span
+func (f *flame) goToChild(_ *tcell.EventKey) *tcell.EventKey {
+	current := f.getCurrentNode()
+	if current == nil || len(current.children) == 0 {
+		return nil
+	}
+	first := current.children[0]
+	for _, c := range current.children[1:] {
+		if c.span.Span.StartTimestamp().AsTime().Before(first.span.Span.StartTimestamp().AsTime()) {
+			first = c
+		}
+	}
+	f.moveTo(first)
+
+	return nil
+}
+
+func (f *flame) updateCurrentSpan() {
+	currentSpan := f.getCurrentSpan()
+	if currentSpan == nil {
+		return
+	}
+	if f.detail != nil {
+		f.detail.update(currentSpan)
+	}
+	if f.logPane != nil {
+		f.logPane.updateLog(
+			currentSpan.Span.TraceID().String(),
+			currentSpan.Span.SpanID().String(),
+		)
+	}
+}
+
+func (f *flame) updateCommands() {
+	keyMaps := layout.KeyMaps{
+		{
+			Key:     tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+			Handler: f.goToChild,
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyDown, ' ', tcell.ModNone),
+			Description: "Child span",
+			Handler:     f.goToChild,
+		},
+		{
+			Key:     tcell.NewEventKey(tcell.KeyRune, 'k', tcell.ModNone),
+			Handler: f.goToParent,
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyUp, ' ', tcell.ModNone),
+			Description: "Parent span",
+			Handler:     f.goToParent,
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRight, ' ', tcell.ModNone),
+			Description: "Next span",
+			Handler:     f.stepBy(1),
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyLeft, ' ', tcell.ModNone),
+			Description: "Previous span",
+			Handler:     f.stepBy(-1),
+		},
+	}
+	keyMaps.Merge(f.resizeManager.KeyMaps())
+
+	layout.RegisterCommandList(f.commands, f.view, nil, keyMaps)
+}
@@ -0,0 +1,113 @@
+package timeline
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/layout"
+	"gotest.tools/v3/assert"
+)
+
+func newTestFlame() (*flame, map[string]*spanTreeNode) {
+	// root    [0, 100]
+	//  ├- a   [0, 50]
+	//  └- b   [50, 100]
+	//     └- c [60, 80]
+	c := newTestSpanTreeNode("c", 60, 80)
+	b := newTestSpanTreeNode("b", 50, 100, c)
+	a := newTestSpanTreeNode("a", 0, 50)
+	root := newTestSpanTreeNode("root", 0, 100, b, a)
+
+	f := newFlame(nil, layout.NewResizeManager(layout.ResizeDirectionHorizontal), nil, nil)
+	f.update([]*spanTreeNode{root})
+
+	return f, map[string]*spanTreeNode{"root": root, "a": a, "b": b, "c": c}
+}
+
+func TestFlameUpdate(t *testing.T) {
+	f, nodes := newTestFlame()
+
+	rows := make([][]string, 0, len(f.rows))
+	for _, row := range f.rows {
+		names := []string{}
+		for _, n := range row {
+			names = append(names, n.span.Span.Name())
+		}
+		rows = append(rows, names)
+	}
+	// ordered by the start time in each depth
+	assert.DeepEqual(t, [][]string{{"root"}, {"a", "b"}, {"c"}}, rows)
+	assert.Equal(t, nodes["root"], f.getCurrentNode())
+	assert.Equal(t, nodes["b"], f.parents[nodes["c"]])
+}
+
+func TestFlameNavigation(t *testing.T) {
+	f, nodes := newTestFlame()
+
+	tests := []struct {
+		name    string
+		handler func(*tcell.EventKey) *tcell.EventKey
+		want    string
+	}{
+		{name: "parent of root", handler: f.goToParent, want: "root"},
+		{name: "first child", handler: f.goToChild, want: "a"},
+		{name: "child of leaf", handler: f.goToChild, want: "a"},
+		{name: "previous of first", handler: f.stepBy(-1), want: "a"},
+		{name: "next", handler: f.stepBy(1), want: "b"},
+		{name: "next of last", handler: f.stepBy(1), want: "b"},
+		{name: "child", handler: f.goToChild, want: "c"},
+		{name: "parent", handler: f.goToParent, want: "b"},
+		{name: "previous", handler: f.stepBy(-1), want: "a"},
+		{name: "root", handler: f.goToParent, want: "root"},
+	}
+
+	// the steps depend on the previous ones
+	for _, tt := range tests {
+		tt.handler(nil)
+		assert.Equal(t, nodes[tt.want], f.getCurrentNode(), tt.name)
+	}
+}
+
+func TestFlameResizeKeys(t *testing.T) {
+	f := newFlame(layout.NewCommandList(), layout.NewResizeManager(layout.ResizeDirectionHorizontal), nil, nil)
+
+	got := f.view.GetInputCapture()(tcell.NewEventKey(tcell.KeyCtrlH, ' ', tcell.ModNone))
+
+	// resize key should be captured
+	assert.Assert(t, got == nil)
+}
+
+func TestFlameDraw(t *testing.T) {
+	f, _ := newTestFlame()
+
+	sw, sh := 22, 5
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to initialize screen: %v", err)
+	}
+	screen.SetSize(sw, sh)
+	f.view.SetRect(0, 0, sw, sh)
+	f.view.Draw(screen)
+
+	// the inner width is 20, so 5 cells are 25ms
+	tests := []struct {
+		x, y int
+		want rune
+	}{
+		{x: 1, y: 1, want: 'r'},
+		{x: 20, y: 1, want: ' '},
+		{x: 1, y: 2, want: 'a'},
+		{x: 11, y: 2, want: 'b'},
+		{x: 12, y: 3, want: ' '}, // outside of the spans
+		{x: 13, y: 3, want: 'c'},
+	}
+	for _, tt := range tests {
+		got, _, _, _ := screen.GetContent(tt.x, tt.y)
+		assert.Equal(t, tt.want, got)
+	}
+
+	// the selected span is highlighted
+	_, _, style, _ := screen.GetContent(1, 1)
+	_, bg, _ := style.Decompose()
+	assert.Equal(t, tcell.ColorWhite, bg)
+}
@@ -2,45 +2,88 @@ package timeline
 
 import (
 	"fmt"
-	"log"
 	"sort"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/filter"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/layout"
-	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/navigation"
 	"go.opentelemetry.io/collector/pdata/ptrace"
 )
 
 const (
 	spanNameColumnWidthResizeUnit = 5
 	spanNameColumnWidthDefalt     = 30
+	// timelineZoomFactor is how many times the window is zoomed in or out at a time
+	timelineZoomFactor = 2
+	// timelinePanDivisor pans the window by its width divided by this
+	timelinePanDivisor = 4
+	timelineWindowMin  = time.Microsecond
+	// exceptionEventName is the event name of exceptions defined in the semantic conventions
+	exceptionEventName = "exception"
 )
 
+// eventMarker is a span event drawn on the span bar
+type eventMarker struct {
+	// idx is the index of the event in the span
+	idx       int
+	offset    time.Duration
+	exception bool
+}
+
 type spanTreeNode struct {
 	span     *telemetry.SpanData
 	label    string
-	box      *tview.Box
+	color    tcell.Color
 	children []*spanTreeNode
 	expand   bool
+	// markers is ordered by the timestamp
+	markers []eventMarker
+	// critical is true if the span is on the critical path of the trace
+	critical bool
+	// contribution is the time the span contributed to the critical path
+	contribution time.Duration
+	// start and end are the offsets from the start of the trace
+	start time.Duration
+	end   time.Duration
+}
+
+// selfTime returns the time of the span not covered by its children
+func (n *spanTreeNode) selfTime() time.Duration {
+	children := make([]*telemetry.SpanData, 0, len(n.children))
+	for _, c := range n.children {
+		children = append(children, c.span)
+	}
+	return telemetry.SelfTime(n.span, children)
 }
 
 type grid struct {
 	commands      *tview.TextView
-	gridView      *tview.Grid
+	gridView      *tview.Box
 	tcache        *telemetry.TraceCache
 	snameWidth    int
 	totalRow      int
 	currentRow    int
+	currentEvent  int
+	criticalOnly  bool
 	tree          []*spanTreeNode
 	duration      time.Duration
 	nodes         []*spanTreeNode
-	items         []*tview.TextView
+	texts         []string
+	rowOffset     int
 	resizeManager *layout.ResizeManager
 	detail        *detail
 	logPane       *logPane
+	// viewStart and viewEnd is the time window of the timeline as the offsets from the start
+	// of the trace
+	viewStart time.Duration
+	viewEnd   time.Duration
+	// view holds the search field above the timeline
+	view   *tview.Flex
+	search *filter.Filter
+	query  string
 }
 
 func newGrid(
@@ -51,10 +94,9 @@ func newGrid(
 	logPane *logPane,
 ) *grid {
 	snameWidth := spanNameColumnWidthDefalt
-	container := tview.NewGrid().
-		SetColumns(snameWidth, 0).
-		SetBorders(true)
-	container.SetTitle("Trace Timeline (t)").SetBorder(true)
+	container := tview.NewBox().
+		SetTitle("Trace Timeline (t)").
+		SetBorder(true)
 
 	grid := &grid{
 		commands:      commands,
@@ -63,12 +105,27 @@ func newGrid(
 		snameWidth:    snameWidth,
 		totalRow:      0,
 		currentRow:    0,
+		currentEvent:  -1,
 		nodes:         []*spanTreeNode{},
-		items:         []*tview.TextView{},
+		texts:         []string{},
 		resizeManager: resizeManager,
 		detail:        detail,
 		logPane:       logPane,
 	}
+	container.SetDrawFunc(grid.draw)
+
+	grid.search = filter.NewFilter(
+		commands,
+		"Find in trace (/): ",
+		grid.onSearchEnter,
+		grid.onSearchDone,
+		grid.onSearchChanged,
+		nil,
+	)
+	// the search field is hidden until the search starts
+	grid.view = grid.search.AddTo(tview.NewFlex().SetDirection(tview.FlexRow)).
+		AddItem(container, 0, 1, true)
+	grid.view.ResizeItem(grid.search.View(), 0, 0)
 
 	return grid
 }
@@ -76,12 +133,15 @@ func newGrid(
 func (g *grid) updateGrid(traceID string) *telemetry.SpanData {
 	g.totalRow = 0
 	g.currentRow = 0
+	g.currentEvent = -1
+	g.rowOffset = 0
 	g.nodes = []*spanTreeNode{}
-	g.items = []*tview.TextView{}
+	g.texts = []string{}
 
 	tree, duration := g.newSpanTree(traceID)
 	g.tree = tree
 	g.duration = duration
+	g.viewStart, g.viewEnd = 0, duration
 
 	g.placeSpans()
 
@@ -93,68 +153,29 @@ func (g *grid) updateGrid(traceID string) *telemetry.SpanData {
 	return g.nodes[0].span
 }
 
-func (g *grid) prepareTimeline(duration time.Duration) {
-	title := tview.NewTextView().SetTextAlign(tview.AlignCenter).SetText("Spans")
-	timeline := tview.NewBox().SetBorder(false).
-		SetDrawFunc(func(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
-			// Draw a horizontal line across the middle of the box.
-			centerY := y + height/2
-			for cx := x + 1; cx < x+width-1; cx++ {
-				screen.SetContent(cx, centerY, tview.BoxDrawingsLightHorizontal, nil, tcell.StyleDefault.Foreground(tcell.ColorWhite))
-			}
-
-			// Write some text along the horizontal line.
-			unit, count := calculateTimelineUnit(duration)
-			for i := range count {
-				ratio := float64(i) / float64(count)
-				label := roundDownDuration(unit * time.Duration(i)).String()
-				if i == 0 {
-					label = "0"
-				}
-				tview.Print(screen, label, x+getXByRatio(ratio, width), centerY, width-2, tview.AlignLeft, tcell.ColorYellow)
-			}
-
-			// Space for other content.
-			return x + 1, centerY + 1, width - 2, height - (centerY + 1 - y)
-		})
-	g.gridView.Clear().
-		AddItem(title, 0, 0, 1, 1, 0, 0, false).
-		AddItem(timeline, 0, 1, 1, 1, 0, 0, false)
-}
-
+// placeSpans flattens the expanded spans in the tree into the rows of the timeline.
+// Only the texts of the labels are kept and the rows are drawn on demand.
 func (g *grid) placeSpans() {
-	g.prepareTimeline(g.duration)
 	g.totalRow = 0
 
 	var (
-		tvs   []*tview.TextView
+		texts []string
 		nodes []*spanTreeNode
 	)
 	for _, n := range g.tree {
-		g.totalRow = g.placeSpan(n, g.totalRow, 0, &tvs, &nodes)
+		if g.criticalOnly && !n.critical {
+			continue
+		}
+		g.totalRow = g.placeSpan(n, g.totalRow, 0, &texts, &nodes)
 	}
 	g.nodes = nodes
-	g.items = tvs
-	if g.getCurrentSpan() != nil {
-		navigation.Focus(g.items[g.currentRow])
-	}
-
-	rows := make([]int, g.totalRow+2)
-	for i := 0; i < g.totalRow+1; i++ {
-		rows[i] = 1
-	}
-	g.gridView.SetRows(rows...)
-	log.Printf("totalRow: %d, tviews: %+v", g.totalRow, tvs)
-
-	if g.getCurrentSpan() != nil {
-		navigation.Focus(g.items[g.currentRow])
-	}
+	g.texts = texts
 }
 
 func (g *grid) placeSpan(
 	node *spanTreeNode,
 	row, depth int,
-	tvs *[]*tview.TextView,
+	texts *[]string,
 	nodes *[]*spanTreeNode,
 ) int {
 	row++
@@ -175,11 +196,8 @@ func (g *grid) placeSpan(
 			exp = "▶"
 		}
 	}
-	tv := g.newTextView(prefix + exp + label)
-	*tvs = append(*tvs, tv)
+	*texts = append(*texts, prefix+exp+label)
 	*nodes = append(*nodes, node)
-	g.gridView.AddItem(tv, row, 0, 1, 1, 0, 0, false)
-	g.gridView.AddItem(node.box, row, 1, 1, 1, 0, 0, false)
 	if !node.expand {
 		return row
 	}
@@ -189,7 +207,10 @@ func (g *grid) placeSpan(
 		)
 	})
 	for _, child := range node.children {
-		row = g.placeSpan(child, row, depth+1, tvs, nodes)
+		if g.criticalOnly && !child.critical {
+			continue
+		}
+		row = g.placeSpan(child, row, depth+1, texts, nodes)
 	}
 	return row
 }
@@ -228,15 +249,6 @@ func (g *grid) newSpanTree(traceID string) (rootNodes []*spanTreeNode, duration
 	for _, span := range spans {
 		current := span.Span.SpanID().String()
 		node := nodes[spanMemo[current]]
-		sname := telemetry.GetServiceNameFromResource(span.ResourceSpan.Resource())
-		st, en := span.Span.StartTimestamp().AsTime().Sub(start), span.Span.EndTimestamp().AsTime().Sub(start)
-		d := en - st
-		node.box = createSpan(colorMemo[sname], duration, st, en)
-		if span.Span.Status().Code() == ptrace.StatusCodeError {
-			node.label = fmt.Sprintf("[!] %s %s", span.Span.Name(), d.String())
-		} else {
-			node.label = fmt.Sprintf("%s %s", span.Span.Name(), d.String())
-		}
 
 		parent := span.Span.ParentSpanID().String()
 		_, parentExists := g.tcache.GetSpanByID(parent)
@@ -255,25 +267,32 @@ func (g *grid) newSpanTree(traceID string) (rootNodes []*spanTreeNode, duration
 		)
 	})
 
-	return rootNodes, duration
-}
-
-func (g *grid) newTextView(text string) *tview.TextView {
-	tv := tview.NewTextView().
-		SetTextAlign(tview.AlignLeft).
-		SetText(text).
-		SetWordWrap(false)
+	markCriticalPath(rootNodes, start, end)
 
-	tv.SetFocusFunc(func() {
-		tv.SetBackgroundColor(tcell.ColorWhite)
-		tv.SetTextColor(tcell.ColorBlack)
-	})
-	tv.SetBlurFunc(func() {
-		tv.SetBackgroundColor(tcell.ColorNone)
-		tv.SetTextColor(tcell.ColorDefault)
-	})
+	// render the spans
+	for _, node := range nodes {
+		span := node.span
+		sname := telemetry.GetServiceNameFromResource(span.ResourceSpan.Resource())
+		node.start = span.Span.StartTimestamp().AsTime().Sub(start)
+		node.end = span.Span.EndTimestamp().AsTime().Sub(start)
+		d := node.end - node.start
+		node.color = colorMemo[sname]
+		node.markers = newEventMarkers(span.Span, start)
+		if span.Span.Status().Code() == ptrace.StatusCodeError {
+			node.label = fmt.Sprintf("[!] %s %s", span.Span.Name(), d.String())
+		} else {
+			node.label = fmt.Sprintf("%s %s", span.Span.Name(), d.String())
+		}
+		// the self time is shown only when the span is waiting on the children
+		if self := node.selfTime(); self < d {
+			node.label = fmt.Sprintf("%s self %s", node.label, self.String())
+		}
+		if node.critical {
+			node.label = fmt.Sprintf("%s (cp %s)", node.label, node.contribution.String())
+		}
+	}
 
-	return tv
+	return rootNodes, duration
 }
 
 func (g *grid) updateCommands() {
@@ -313,13 +332,88 @@ func (g *grid) updateCommands() {
 				return nil
 			},
 		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, 'e', tcell.ModNone),
+			Description: "Next event",
+			Handler:     g.stepEvent(1),
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, 'E', tcell.ModNone),
+			Description: "Previous event",
+			Handler:     g.stepEvent(-1),
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone),
+			Description: "Toggle critical path only",
+			Handler: func(event *tcell.EventKey) *tcell.EventKey {
+				g.toggleCriticalOnly()
+				return nil
+			},
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, '+', tcell.ModNone),
+			Description: "Zoom in",
+			Handler:     g.zoom(1.0 / timelineZoomFactor),
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, '-', tcell.ModNone),
+			Description: "Zoom out",
+			Handler:     g.zoom(timelineZoomFactor),
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, 'z', tcell.ModNone),
+			Description: "Zoom to span",
+			Handler:     g.zoomToSpan,
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, '<', tcell.ModNone),
+			Description: "Pan left",
+			Handler:     g.pan(-1),
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, '>', tcell.ModNone),
+			Description: "Pan right",
+			Handler:     g.pan(1),
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, '0', tcell.ModNone),
+			Description: "Reset zoom",
+			Handler:     g.resetZoom,
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, '/', tcell.ModNone),
+			Description: "Find in trace",
+			Handler: func(event *tcell.EventKey) *tcell.EventKey {
+				g.showSearch()
+				return nil
+			},
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, 'n', tcell.ModNone),
+			Description: "Next match",
+			Handler:     g.stepMatch(1),
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, 'N', tcell.ModNone),
+			Description: "Previous match",
+			Handler:     g.stepMatch(-1),
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, '!', tcell.ModNone),
+			Description: "Next error",
+			Handler:     g.goToNextError,
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModNone),
+			Description: "Slowest span",
+			Handler:     g.goToSlowest,
+		},
 		{
 			Key:         tcell.NewEventKey(tcell.KeyRight, ' ', tcell.ModNone),
 			Description: "Widen span name column",
 			Handler: func(event *tcell.EventKey) *tcell.EventKey {
 				_, _, w, _ := g.gridView.GetInnerRect()
 				g.snameWidth = widenInLimit(spanNameColumnWidthResizeUnit, g.snameWidth, w)
-				g.gridView.SetColumns(g.snameWidth, 0)
 				return nil
 			},
 		},
@@ -328,7 +422,6 @@ func (g *grid) updateCommands() {
 			Description: "Narrow span name column",
 			Handler: func(event *tcell.EventKey) *tcell.EventKey {
 				g.snameWidth = narrowInLimit(spanNameColumnWidthResizeUnit, g.snameWidth, spanNameColumnWidthDefalt)
-				g.gridView.SetColumns(g.snameWidth, 0)
 				return nil
 			},
 		},
@@ -338,18 +431,37 @@ func (g *grid) updateCommands() {
 	// Remove default input capture to avoid conflict
 	g.gridView.SetInputCapture(nil)
 
-	layout.RegisterCommandList(g.commands, g.gridView, func() {
-		if g.getCurrentSpan() != nil {
-			navigation.Focus(g.items[g.currentRow])
-		}
-	}, keyMaps)
+	layout.RegisterCommandList(g.commands, g.gridView, nil, keyMaps)
 }
 
 func (g *grid) getCurrentSpan() *telemetry.SpanData {
+	if n := g.getCurrentNode(); n != nil {
+		return n.span
+	}
+	return nil
+}
+
+func (g *grid) getCurrentNode() *spanTreeNode {
 	if g.currentRow < 0 || g.currentRow >= len(g.nodes) {
 		return nil
 	}
-	return g.nodes[g.currentRow].span
+	return g.nodes[g.currentRow]
+}
+
+// selectSpan moves the cursor to the span and returns it, or returns nil if the span
+// is not shown in the timeline
+func (g *grid) selectSpan(spanID string) *telemetry.SpanData {
+	if spanID == "" {
+		return nil
+	}
+	for i, n := range g.nodes {
+		if n.span.Span.SpanID().String() == spanID {
+			g.currentRow = i
+			g.currentEvent = -1
+			return n.span
+		}
+	}
+	return nil
 }
 
 func (g *grid) stepBy(step int) func(_ *tcell.EventKey) *tcell.EventKey {
@@ -361,10 +473,7 @@ func (g *grid) stepBy(step int) func(_ *tcell.EventKey) *tcell.EventKey {
 		}
 
 		g.currentRow = nextRow
-		navigation.Focus(g.items[g.currentRow])
-		if g.currentRow == 0 {
-			g.gridView.SetOffset(g.currentRow, 0)
-		}
+		g.currentEvent = -1
 
 		g.updateCurrentSpan()
 
@@ -374,8 +483,8 @@ func (g *grid) stepBy(step int) func(_ *tcell.EventKey) *tcell.EventKey {
 
 func (g *grid) goToFirst(_ *tcell.EventKey) *tcell.EventKey {
 	g.currentRow = 0
-	navigation.Focus(g.items[g.currentRow])
-	g.gridView.SetOffset(g.currentRow, 0)
+	g.currentEvent = -1
+	g.rowOffset = 0
 	g.updateCurrentSpan()
 
 	return nil
@@ -383,13 +492,166 @@ func (g *grid) goToFirst(_ *tcell.EventKey) *tcell.EventKey {
 
 func (g *grid) goToLast(_ *tcell.EventKey) *tcell.EventKey {
 	g.currentRow = g.totalRow - 1
-	navigation.Focus(g.items[g.currentRow])
-	// g.gridView.SetOffset(g.currentRow, 0)
+	g.currentEvent = -1
+	g.updateCurrentSpan()
+
+	return nil
+}
+
+// zoom scales the time window by the factor around the current span, which stays at the
+// same position in the view
+func (g *grid) zoom(factor float64) func(_ *tcell.EventKey) *tcell.EventKey {
+	return func(_ *tcell.EventKey) *tcell.EventKey {
+		window := g.viewEnd - g.viewStart
+		anchor := g.viewStart + window/2
+		if n := g.getCurrentNode(); n != nil {
+			anchor = max(g.viewStart, min(n.start+(n.end-n.start)/2, g.viewEnd))
+		}
+		ratio := 0.0
+		if window > 0 {
+			ratio = float64(anchor-g.viewStart) / float64(window)
+		}
+		newWindow := time.Duration(float64(window) * factor)
+		g.setView(anchor-time.Duration(float64(newWindow)*ratio), newWindow)
+
+		return nil
+	}
+}
+
+// zoomToSpan fits the time window to the current span
+func (g *grid) zoomToSpan(_ *tcell.EventKey) *tcell.EventKey {
+	n := g.getCurrentNode()
+	if n == nil {
+		return nil
+	}
+	window := max(n.end-n.start, timelineWindowMin)
+	g.setView(n.start+(n.end-n.start)/2-window/2, window)
+
+	return nil
+}
+
+// pan moves the time window to the later (step > 0) or earlier (step < 0) time
+func (g *grid) pan(step int) func(_ *tcell.EventKey) *tcell.EventKey {
+	return func(_ *tcell.EventKey) *tcell.EventKey {
+		window := g.viewEnd - g.viewStart
+		g.setView(g.viewStart+window/timelinePanDivisor*time.Duration(step), window)
+
+		return nil
+	}
+}
+
+func (g *grid) resetZoom(_ *tcell.EventKey) *tcell.EventKey {
+	g.viewStart, g.viewEnd = 0, g.duration
+
+	return nil
+}
+
+// setView updates the time window keeping it inside of the trace
+func (g *grid) setView(start, window time.Duration) {
+	window = min(max(window, timelineWindowMin), g.duration)
+	start = max(0, min(start, g.duration-window))
+	g.viewStart, g.viewEnd = start, start+window
+}
+
+// jumpToSpan moves the cursor to the span. The ancestors of the span are unfolded, and all spans
+// are shown if the span is not on the critical path while showing only the critical path.
+func (g *grid) jumpToSpan(spanID string) {
+	path := findSpanPath(g.tree, spanID)
+	if len(path) == 0 {
+		return
+	}
+	if g.criticalOnly && !path[len(path)-1].critical {
+		g.criticalOnly = false
+	}
+	for _, n := range path[:len(path)-1] {
+		n.expand = true
+	}
+	g.placeSpans()
+	g.selectSpan(spanID)
 	g.updateCurrentSpan()
+}
 
+// findSpanPath returns the nodes from the root to the span, or nil if the span is not in the tree
+func findSpanPath(nodes []*spanTreeNode, spanID string) []*spanTreeNode {
+	for _, n := range nodes {
+		if n.span.Span.SpanID().String() == spanID {
+			return []*spanTreeNode{n}
+		}
+		if path := findSpanPath(n.children, spanID); path != nil {
+			return append([]*spanTreeNode{n}, path...)
+		}
+	}
 	return nil
 }
 
+// toggleCriticalOnly switches between showing all spans and only the spans on the
+// critical path. The cursor stays on the current span if it is still shown.
+func (g *grid) toggleCriticalOnly() {
+	current := g.getCurrentSpan()
+	g.criticalOnly = !g.criticalOnly
+	g.currentRow = 0
+	g.currentEvent = -1
+	g.placeSpans()
+	if current != nil {
+		g.selectSpan(current.Span.SpanID().String())
+	}
+	g.updateCurrentSpan()
+}
+
+// stepEvent moves the cursor to the next (step > 0) or previous (step < 0) event marker
+// in the timeline and focuses the event in the detail
+func (g *grid) stepEvent(step int) func(_ *tcell.EventKey) *tcell.EventKey {
+	return func(_ *tcell.EventKey) *tcell.EventKey {
+		type position struct{ row, marker int }
+
+		// the markers in the displayed order, and the index to insert the cursor
+		var (
+			positions []position
+			cursor    = -1
+		)
+		for row, n := range g.nodes {
+			if row == g.currentRow && g.currentEvent < 0 {
+				cursor = len(positions)
+			}
+			for mi, m := range n.markers {
+				if row == g.currentRow && m.idx == g.currentEvent {
+					cursor = len(positions)
+				}
+				positions = append(positions, position{row: row, marker: mi})
+			}
+		}
+		if cursor < 0 {
+			return nil
+		}
+
+		next := cursor + step
+		if g.currentEvent < 0 && step > 0 {
+			// the cursor is not on a marker but before the markers of the current span
+			next = cursor
+		}
+		if next < 0 || next >= len(positions) {
+			return nil
+		}
+
+		pos := positions[next]
+		g.currentRow = pos.row
+		g.currentEvent = g.nodes[pos.row].markers[pos.marker].idx
+		g.updateCurrentSpan()
+		if g.detail != nil {
+			g.detail.focusEvent(g.currentEvent)
+		}
+
+		return nil
+	}
+}
+
+// isCurrentEvent returns a function reporting whether the event of the node is under the cursor
+func (g *grid) isCurrentEvent(node *spanTreeNode) func(idx int) bool {
+	return func(idx int) bool {
+		return idx == g.currentEvent && g.getCurrentSpan() == node.span
+	}
+}
+
 func (g *grid) updateCurrentSpan() {
 	currentSpan := g.getCurrentSpan()
 	if g.detail != nil {
@@ -403,26 +665,195 @@ func (g *grid) updateCurrentSpan() {
 	}
 }
 
-func createSpan(color tcell.Color, total, start, end time.Duration) (span *tview.Box) {
-	return tview.NewBox().SetBorder(false).
-		SetDrawFunc(func(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
-			// Draw a horizontal line across the middle of the box.
-			centerY := y + height/2
-			sRatio := float64(start) / float64(total)
-			eRatio := float64(end) / float64(total)
-			s := x + getXByRatio(sRatio, width)
-			e := x + getXByRatio(eRatio, width)
-			if s == e {
-				screen.SetContent(s, centerY, tview.BoxDrawingsHeavyVertical, nil, tcell.StyleDefault.Foreground(color))
-			} else {
-				for cx := s; cx < e; cx++ {
-					screen.SetContent(cx, centerY, tview.BlockMediumShade, nil, tcell.StyleDefault.Foreground(color))
-				}
-			}
-
-			// Space for other content.
-			return x + 1, centerY + 1, width - 2, height - (centerY + 1 - y)
+// newEventMarkers returns the markers of the span events ordered by the timestamp.
+// The offsets are from the start of the trace.
+func newEventMarkers(span *ptrace.Span, start time.Time) []eventMarker {
+	markers := make([]eventMarker, 0, span.Events().Len())
+	for ei := 0; ei < span.Events().Len(); ei++ {
+		event := span.Events().At(ei)
+		markers = append(markers, eventMarker{
+			idx:       ei,
+			offset:    event.Timestamp().AsTime().Sub(start),
+			exception: event.Name() == exceptionEventName,
 		})
+	}
+	sort.SliceStable(markers, func(i, j int) bool {
+		return markers[i].offset < markers[j].offset
+	})
+	return markers
+}
+
+// draw renders the timeline in the rows and columns separated by the borders. Only the rows
+// in the view are drawn, so the cost of drawing does not grow with the number of the spans.
+func (g *grid) draw(screen tcell.Screen, x, y, width, height int) (int, int, int, int) {
+	// inside of the border
+	x, y, width, height = x+1, y+1, width-2, height-2
+	if width <= 0 || height <= 0 || len(g.nodes) == 0 {
+		return x, y, width, height
+	}
+
+	// | span name | span bar |
+	sepX, rightX := x+1+g.snameWidth, x+width-1
+	barX, barWidth := sepX+1, rightX-sepX-1
+	bottom := y + height
+
+	// the header takes 3 lines and each row takes 2 lines including the border below it
+	g.scrollToCurrentRow(max((height-2)/2, 1))
+
+	cy := y
+	drawRowBorder(screen, x, sepX, rightX, cy, tview.BoxDrawingsLightDownAndRight, tview.BoxDrawingsLightDownAndHorizontal, tview.BoxDrawingsLightDownAndLeft)
+	if cy++; cy >= bottom {
+		return x, y, width, height
+	}
+	drawColumnBorders(screen, x, sepX, rightX, cy)
+	// centered with the extra space on the right as the table header used to be
+	tview.Print(screen, "Spans", x+1+(g.snameWidth-len("Spans"))/2, cy, g.snameWidth, tview.AlignLeft, tview.Styles.PrimaryTextColor)
+	drawTimelineAxis(screen, g.viewStart, g.viewEnd-g.viewStart, barX, cy, barWidth)
+	for row := g.rowOffset; row < len(g.nodes); row++ {
+		if cy++; cy >= bottom {
+			return x, y, width, height
+		}
+		drawRowBorder(screen, x, sepX, rightX, cy, tview.BoxDrawingsLightVerticalAndRight, tview.BoxDrawingsLightVerticalAndHorizontal, tview.BoxDrawingsLightVerticalAndLeft)
+		if cy++; cy >= bottom {
+			return x, y, width, height
+		}
+		drawColumnBorders(screen, x, sepX, rightX, cy)
+		g.drawLabel(screen, row, x+1, cy, g.snameWidth)
+		g.drawSpan(screen, g.nodes[row], barX, cy, barWidth)
+	}
+	if cy++; cy < bottom {
+		drawRowBorder(screen, x, sepX, rightX, cy, tview.BoxDrawingsLightUpAndRight, tview.BoxDrawingsLightUpAndHorizontal, tview.BoxDrawingsLightUpAndLeft)
+	}
+
+	return x, y, width, height
+}
+
+// scrollToCurrentRow updates the offset of the rows to show the cursor
+func (g *grid) scrollToCurrentRow(visible int) {
+	if g.currentRow < g.rowOffset {
+		g.rowOffset = g.currentRow
+	} else if g.currentRow >= g.rowOffset+visible {
+		g.rowOffset = g.currentRow - visible + 1
+	}
+	// avoid the blank rows at the bottom after folding the spans
+	g.rowOffset = max(min(g.rowOffset, len(g.nodes)-visible), 0)
+}
+
+func (g *grid) drawLabel(screen tcell.Screen, row, x, y, width int) {
+	color := tview.Styles.PrimaryTextColor
+	if g.isMatched(g.nodes[row]) {
+		color = tcell.ColorYellow
+	}
+	if row == g.currentRow && g.gridView.HasFocus() {
+		// tview.Print keeps the background filled here
+		color = tcell.ColorBlack
+		style := tcell.StyleDefault.Background(tcell.ColorWhite).Foreground(color)
+		for cx := x; cx < x+width; cx++ {
+			screen.SetContent(cx, y, ' ', nil, style)
+		}
+	}
+	tview.Print(screen, tview.Escape(g.texts[row]), x, y, width, tview.AlignLeft, color)
+}
+
+// drawSpan draws the bar of the span in the time window. The bar is clipped by the window
+// and the edge of the window is marked where the span continues beyond it.
+func (g *grid) drawSpan(screen tcell.Screen, node *spanTreeNode, x, y, width int) {
+	style := tcell.StyleDefault.Foreground(node.color)
+	if node.end < g.viewStart {
+		screen.SetContent(x, y, '◀', nil, style)
+		return
+	}
+	if node.start > g.viewEnd {
+		screen.SetContent(x+width-1, y, '▶', nil, style)
+		return
+	}
+
+	s := x + g.getXInView(max(node.start, g.viewStart), width)
+	e := x + g.getXInView(min(node.end, g.viewEnd), width)
+	// The spans on the critical path are drawn with the darker shade
+	bar := tview.BlockMediumShade
+	if node.critical {
+		bar = tview.BlockDarkShade
+	}
+	if s == e {
+		screen.SetContent(s, y, tview.BoxDrawingsHeavyVertical, nil, style)
+	} else {
+		for cx := s; cx < e; cx++ {
+			screen.SetContent(cx, y, bar, nil, style)
+		}
+	}
+	if node.start < g.viewStart {
+		screen.SetContent(x, y, '◀', nil, style)
+	}
+	if node.end > g.viewEnd {
+		screen.SetContent(x+width-1, y, '▶', nil, style)
+	}
+
+	// Draw the events on the bar
+	isCurrentEvent := g.isCurrentEvent(node)
+	for _, m := range node.markers {
+		if m.offset < g.viewStart || m.offset > g.viewEnd {
+			continue
+		}
+		mx := max(x, min(x+g.getXInView(m.offset, width), x+width-1))
+		style := tcell.StyleDefault.Foreground(tcell.ColorWhite)
+		if m.exception {
+			style = style.Foreground(tcell.ColorRed)
+		}
+		if isCurrentEvent(m.idx) {
+			style = style.Reverse(true)
+		}
+		screen.SetContent(mx, y, '◆', nil, style)
+	}
+}
+
+// getXInView returns the x position of the offset in the time window
+func (g *grid) getXInView(offset time.Duration, width int) int {
+	window := g.viewEnd - g.viewStart
+	if window <= 0 {
+		return 0
+	}
+	return getXByRatio(float64(offset-g.viewStart)/float64(window), width)
+}
+
+func drawTimelineAxis(screen tcell.Screen, start, duration time.Duration, x, y, width int) {
+	for cx := x + 1; cx < x+width-1; cx++ {
+		screen.SetContent(cx, y, tview.BoxDrawingsLightHorizontal, nil, tcell.StyleDefault.Foreground(tcell.ColorWhite))
+	}
+
+	unit, count := calculateTimelineUnit(duration)
+	for i := range count {
+		ratio := float64(i) / float64(count)
+		label := roundDownDuration(start + unit*time.Duration(i)).String()
+		if i == 0 && start == 0 {
+			label = "0"
+		}
+		tview.Print(screen, label, x+getXByRatio(ratio, width), y, width-2, tview.AlignLeft, tcell.ColorYellow)
+	}
+}
+
+// drawRowBorder draws the horizontal border with the junctions at the column borders
+func drawRowBorder(screen tcell.Screen, x, sepX, rightX, y int, left, middle, right rune) {
+	style := tcell.StyleDefault.Foreground(tview.Styles.GraphicsColor)
+	for cx := x; cx <= rightX; cx++ {
+		r := tview.BoxDrawingsLightHorizontal
+		switch cx {
+		case x:
+			r = left
+		case sepX:
+			r = middle
+		case rightX:
+			r = right
+		}
+		screen.SetContent(cx, y, r, nil, style)
+	}
+}
+
+func drawColumnBorders(screen tcell.Screen, x, sepX, rightX, y int) {
+	style := tcell.StyleDefault.Foreground(tview.Styles.GraphicsColor)
+	for _, cx := range []int{x, sepX, rightX} {
+		screen.SetContent(cx, y, tview.BoxDrawingsLightVertical, nil, style)
+	}
 }
 
 func getXByRatio(ratio float64, width int) int {
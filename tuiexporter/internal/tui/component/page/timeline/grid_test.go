@@ -1,13 +1,17 @@
 package timeline
 
 import (
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gdamore/tcell/v2"
 	"github.com/jonboulle/clockwork"
 	"github.com/rivo/tview"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/test"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 	"gotest.tools/v3/assert"
 )
 
@@ -229,7 +233,6 @@ func TestStepBy(t *testing.T) {
 		step           int
 		wantCurrentRow int
 		wantOffsetRow  int
-		wantOffsetCol  int
 	}{
 		{
 			name:           "Forward_In_Range",
@@ -238,7 +241,6 @@ func TestStepBy(t *testing.T) {
 			step:           1,
 			wantCurrentRow: 3,
 			wantOffsetRow:  0,
-			wantOffsetCol:  0,
 		},
 		{
 			name:           "Backward_In_Range",
@@ -247,7 +249,6 @@ func TestStepBy(t *testing.T) {
 			step:           -1,
 			wantCurrentRow: 4,
 			wantOffsetRow:  0,
-			wantOffsetCol:  0,
 		},
 		{
 			name:           "Forward_To_Last",
@@ -256,7 +257,6 @@ func TestStepBy(t *testing.T) {
 			step:           1,
 			wantCurrentRow: 9,
 			wantOffsetRow:  0,
-			wantOffsetCol:  0,
 		},
 		{
 			name:           "Backward_To_First",
@@ -265,7 +265,6 @@ func TestStepBy(t *testing.T) {
 			step:           -1,
 			wantCurrentRow: 0,
 			wantOffsetRow:  0,
-			wantOffsetCol:  0,
 		},
 		{
 			name:           "Beyond_Last",
@@ -274,7 +273,6 @@ func TestStepBy(t *testing.T) {
 			step:           1,
 			wantCurrentRow: 9,
 			wantOffsetRow:  0,
-			wantOffsetCol:  0,
 		},
 		{
 			name:           "Before_First",
@@ -283,7 +281,6 @@ func TestStepBy(t *testing.T) {
 			step:           -1,
 			wantCurrentRow: 0,
 			wantOffsetRow:  0,
-			wantOffsetCol:  0,
 		},
 	}
 
@@ -292,10 +289,9 @@ func TestStepBy(t *testing.T) {
 			g := newGrid(nil, store.GetTraceCache(), nil, nil, nil)
 			g.currentRow = tt.initialRow
 			g.totalRow = tt.totalRow
-			g.items = make([]*tview.TextView, tt.totalRow)
+			g.texts = make([]string, tt.totalRow)
 			g.nodes = make([]*spanTreeNode, tt.totalRow)
 			for i := 0; i < tt.totalRow; i++ {
-				g.items[i] = tview.NewTextView()
 				g.nodes[i] = &spanTreeNode{span: &telemetry.SpanData{
 					Span:         testdata.Spans[0],
 					ResourceSpan: testdata.RSpans[0],
@@ -307,9 +303,7 @@ func TestStepBy(t *testing.T) {
 			handler(nil)
 
 			assert.Equal(t, tt.wantCurrentRow, g.currentRow)
-			row, col := g.gridView.GetOffset()
-			assert.Equal(t, tt.wantOffsetRow, row)
-			assert.Equal(t, tt.wantOffsetCol, col)
+			assert.Equal(t, tt.wantOffsetRow, g.rowOffset)
 		})
 	}
 }
@@ -325,7 +319,6 @@ func TestGoToFirst(t *testing.T) {
 		totalRow       int
 		wantCurrentRow int
 		wantOffsetRow  int
-		wantOffsetCol  int
 	}{
 		{
 			name:           "From_Middle",
@@ -333,7 +326,6 @@ func TestGoToFirst(t *testing.T) {
 			totalRow:       10,
 			wantCurrentRow: 0,
 			wantOffsetRow:  0,
-			wantOffsetCol:  0,
 		},
 		{
 			name:           "From_Last",
@@ -341,7 +333,6 @@ func TestGoToFirst(t *testing.T) {
 			totalRow:       10,
 			wantCurrentRow: 0,
 			wantOffsetRow:  0,
-			wantOffsetCol:  0,
 		},
 		{
 			name:           "Already_First",
@@ -349,7 +340,6 @@ func TestGoToFirst(t *testing.T) {
 			totalRow:       10,
 			wantCurrentRow: 0,
 			wantOffsetRow:  0,
-			wantOffsetCol:  0,
 		},
 	}
 
@@ -358,10 +348,9 @@ func TestGoToFirst(t *testing.T) {
 			g := newGrid(nil, store.GetTraceCache(), nil, nil, nil)
 			g.currentRow = tt.initialRow
 			g.totalRow = tt.totalRow
-			g.items = make([]*tview.TextView, tt.totalRow)
+			g.texts = make([]string, tt.totalRow)
 			g.nodes = make([]*spanTreeNode, tt.totalRow)
 			for i := 0; i < tt.totalRow; i++ {
-				g.items[i] = tview.NewTextView()
 				g.nodes[i] = &spanTreeNode{span: &telemetry.SpanData{
 					Span:         testdata.Spans[0],
 					ResourceSpan: testdata.RSpans[0],
@@ -372,9 +361,7 @@ func TestGoToFirst(t *testing.T) {
 			g.goToFirst(nil)
 
 			assert.Equal(t, tt.wantCurrentRow, g.currentRow)
-			row, col := g.gridView.GetOffset()
-			assert.Equal(t, tt.wantOffsetRow, row)
-			assert.Equal(t, tt.wantOffsetCol, col)
+			assert.Equal(t, tt.wantOffsetRow, g.rowOffset)
 		})
 	}
 }
@@ -390,7 +377,6 @@ func TestGoToLast(t *testing.T) {
 		totalRow       int
 		wantCurrentRow int
 		wantOffsetRow  int
-		wantOffsetCol  int
 	}{
 		{
 			name:           "From_First",
@@ -398,7 +384,6 @@ func TestGoToLast(t *testing.T) {
 			totalRow:       10,
 			wantCurrentRow: 9,
 			wantOffsetRow:  0,
-			wantOffsetCol:  0,
 		},
 		{
 			name:           "From_Middle",
@@ -406,7 +391,6 @@ func TestGoToLast(t *testing.T) {
 			totalRow:       10,
 			wantCurrentRow: 9,
 			wantOffsetRow:  0,
-			wantOffsetCol:  0,
 		},
 		{
 			name:           "Already_Last",
@@ -414,7 +398,6 @@ func TestGoToLast(t *testing.T) {
 			totalRow:       10,
 			wantCurrentRow: 9,
 			wantOffsetRow:  0,
-			wantOffsetCol:  0,
 		},
 	}
 
@@ -423,10 +406,9 @@ func TestGoToLast(t *testing.T) {
 			g := newGrid(nil, store.GetTraceCache(), nil, nil, nil)
 			g.currentRow = tt.initialRow
 			g.totalRow = tt.totalRow
-			g.items = make([]*tview.TextView, tt.totalRow)
+			g.texts = make([]string, tt.totalRow)
 			g.nodes = make([]*spanTreeNode, tt.totalRow)
 			for i := 0; i < tt.totalRow; i++ {
-				g.items[i] = tview.NewTextView()
 				g.nodes[i] = &spanTreeNode{span: &telemetry.SpanData{
 					Span:         testdata.Spans[0],
 					ResourceSpan: testdata.RSpans[0],
@@ -437,9 +419,260 @@ func TestGoToLast(t *testing.T) {
 			g.goToLast(nil)
 
 			assert.Equal(t, tt.wantCurrentRow, g.currentRow)
-			row, col := g.gridView.GetOffset()
-			assert.Equal(t, tt.wantOffsetRow, row)
-			assert.Equal(t, tt.wantOffsetCol, col)
+			assert.Equal(t, tt.wantOffsetRow, g.rowOffset)
+		})
+	}
+}
+
+func TestGridDrawVisibleRows(t *testing.T) {
+	store := telemetry.NewStore(clockwork.NewRealClock())
+	payload, testdata := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{100}})
+	store.AddSpan(&payload)
+
+	g := newGrid(nil, store.GetTraceCache(), nil, nil, nil)
+	g.tree, g.duration = g.newSpanTree(testdata.Spans[0].TraceID().String())
+	g.placeSpans()
+
+	// 4 rows are visible in the inner height 10: the header takes 3 lines and each row 2 lines
+	sw, sh := 80, 12
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to initialize screen: %v", err)
+	}
+	screen.SetSize(sw, sh)
+	g.gridView.SetRect(0, 0, sw, sh)
+
+	draw := func() []string {
+		screen.Clear()
+		g.gridView.Draw(screen)
+		screen.Show()
+		got := test.GetScreenContent(t, screen)
+		return strings.Split(got.String(), "\n")
+	}
+
+	lines := draw()
+	assert.Equal(t, 0, g.rowOffset)
+	assert.Assert(t, strings.Contains(lines[4], g.texts[0]))
+	assert.Assert(t, strings.Contains(lines[10], g.texts[3]))
+
+	g.goToLast(nil)
+	lines = draw()
+	assert.Equal(t, 96, g.rowOffset)
+	assert.Assert(t, strings.Contains(lines[4], g.texts[96]))
+	assert.Assert(t, strings.Contains(lines[10], g.texts[99]))
+
+	g.stepBy(-3)(nil)
+	draw()
+	assert.Equal(t, 96, g.rowOffset)
+
+	g.stepBy(-1)(nil)
+	lines = draw()
+	assert.Equal(t, 95, g.rowOffset)
+	assert.Assert(t, strings.Contains(lines[4], g.texts[95]))
+
+	g.goToFirst(nil)
+	lines = draw()
+	assert.Equal(t, 0, g.rowOffset)
+	assert.Assert(t, strings.Contains(lines[4], g.texts[0]))
+}
+
+func TestZoomAndPan(t *testing.T) {
+	// trace [0, 100ms], the current span [40ms, 60ms]
+	g := newGrid(nil, nil, nil, nil, nil)
+	g.duration = 100 * time.Millisecond
+	g.viewStart, g.viewEnd = 0, g.duration
+	g.nodes = []*spanTreeNode{{start: 40 * time.Millisecond, end: 60 * time.Millisecond}}
+
+	ms := func(f float64) time.Duration {
+		return time.Duration(f * float64(time.Millisecond))
+	}
+	tests := []struct {
+		name      string
+		handler   func(*tcell.EventKey) *tcell.EventKey
+		wantStart time.Duration
+		wantEnd   time.Duration
+	}{
+		{name: "zoom in", handler: g.zoom(0.5), wantStart: ms(25), wantEnd: ms(75)},
+		{name: "zoom in again", handler: g.zoom(0.5), wantStart: ms(37.5), wantEnd: ms(62.5)},
+		{name: "pan right", handler: g.pan(1), wantStart: ms(43.75), wantEnd: ms(68.75)},
+		{name: "zoom out around the span", handler: g.zoom(2), wantStart: ms(37.5), wantEnd: ms(87.5)},
+		{name: "zoom out beyond the trace", handler: g.zoom(4), wantStart: 0, wantEnd: ms(100)},
+		{name: "pan left beyond the trace", handler: g.pan(-1), wantStart: 0, wantEnd: ms(100)},
+		{name: "zoom to span", handler: g.zoomToSpan, wantStart: ms(40), wantEnd: ms(60)},
+		{name: "reset zoom", handler: g.resetZoom, wantStart: 0, wantEnd: ms(100)},
+	}
+
+	// the steps depend on the previous ones
+	for _, tt := range tests {
+		tt.handler(nil)
+		assert.Equal(t, tt.wantStart, g.viewStart, tt.name)
+		assert.Equal(t, tt.wantEnd, g.viewEnd, tt.name)
+	}
+}
+
+func TestDrawSpanInView(t *testing.T) {
+	g := newGrid(nil, nil, nil, nil, nil)
+	g.duration = 100 * time.Millisecond
+	g.viewStart, g.viewEnd = 25*time.Millisecond, 75*time.Millisecond
+
+	screen := tcell.NewSimulationScreen("")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("failed to initialize screen: %v", err)
+	}
+	screen.SetSize(10, 2)
+
+	// clipped at the start of the window
+	g.drawSpan(screen, &spanTreeNode{start: 0, end: 50 * time.Millisecond}, 0, 0, 10)
+	// outside of the window
+	g.drawSpan(screen, &spanTreeNode{start: 80 * time.Millisecond, end: 90 * time.Millisecond}, 0, 1, 10)
+
+	tests := []struct {
+		x, y int
+		want rune
+	}{
+		{x: 0, y: 0, want: '◀'},
+		{x: 1, y: 0, want: tview.BlockMediumShade},
+		{x: 4, y: 0, want: tview.BlockMediumShade},
+		{x: 9, y: 1, want: '▶'},
+	}
+	for _, tt := range tests {
+		got, _, _, _ := screen.GetContent(tt.x, tt.y)
+		assert.Equal(t, tt.want, got)
+	}
+}
+
+func TestNewEventMarkers(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	span := ptrace.NewSpan()
+	for _, e := range []struct {
+		name   string
+		offset time.Duration
+	}{
+		{name: "retry", offset: 30 * time.Millisecond},
+		{name: "exception", offset: 10 * time.Millisecond},
+		{name: "done", offset: 30 * time.Millisecond},
+	} {
+		event := span.Events().AppendEmpty()
+		event.SetName(e.name)
+		event.SetTimestamp(pcommon.NewTimestampFromTime(start.Add(e.offset)))
+	}
+
+	got := newEventMarkers(&span, start)
+
+	want := []eventMarker{
+		{idx: 1, offset: 10 * time.Millisecond, exception: true},
+		{idx: 0, offset: 30 * time.Millisecond},
+		{idx: 2, offset: 30 * time.Millisecond},
+	}
+	assert.Equal(t, len(want), len(got))
+	for i := range want {
+		assert.Equal(t, want[i], got[i])
+	}
+}
+
+func TestStepEvent(t *testing.T) {
+	store := telemetry.NewStore(clockwork.NewRealClock())
+	payload, testdata := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{4}})
+	store.AddSpan(&payload)
+
+	// row 0: 2 events, row 1: no events, row 2: 1 event, row 3: no events
+	markers := [][]eventMarker{
+		{{idx: 0, offset: 10}, {idx: 1, offset: 20}},
+		{},
+		{{idx: 0, offset: 30}},
+		{},
+	}
+
+	tests := []struct {
+		name             string
+		initialRow       int
+		initialEvent     int
+		step             int
+		wantCurrentRow   int
+		wantCurrentEvent int
+	}{
+		{
+			name:             "Forward_From_Span",
+			initialRow:       0,
+			initialEvent:     -1,
+			step:             1,
+			wantCurrentRow:   0,
+			wantCurrentEvent: 0,
+		},
+		{
+			name:             "Forward_In_Span",
+			initialRow:       0,
+			initialEvent:     0,
+			step:             1,
+			wantCurrentRow:   0,
+			wantCurrentEvent: 1,
+		},
+		{
+			name:             "Forward_To_Next_Span",
+			initialRow:       0,
+			initialEvent:     1,
+			step:             1,
+			wantCurrentRow:   2,
+			wantCurrentEvent: 0,
+		},
+		{
+			name:             "Forward_From_Span_Without_Events",
+			initialRow:       1,
+			initialEvent:     -1,
+			step:             1,
+			wantCurrentRow:   2,
+			wantCurrentEvent: 0,
+		},
+		{
+			name:             "Backward_From_Span_Without_Events",
+			initialRow:       1,
+			initialEvent:     -1,
+			step:             -1,
+			wantCurrentRow:   0,
+			wantCurrentEvent: 1,
+		},
+		{
+			name:             "Beyond_Last",
+			initialRow:       2,
+			initialEvent:     0,
+			step:             1,
+			wantCurrentRow:   2,
+			wantCurrentEvent: 0,
+		},
+		{
+			name:             "Before_First",
+			initialRow:       0,
+			initialEvent:     0,
+			step:             -1,
+			wantCurrentRow:   0,
+			wantCurrentEvent: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newGrid(nil, store.GetTraceCache(), nil, nil, nil)
+			g.currentRow = tt.initialRow
+			g.currentEvent = tt.initialEvent
+			g.totalRow = len(markers)
+			g.texts = make([]string, len(markers))
+			g.nodes = make([]*spanTreeNode, len(markers))
+			for i := range markers {
+				g.nodes[i] = &spanTreeNode{
+					span: &telemetry.SpanData{
+						Span:         testdata.Spans[i],
+						ResourceSpan: testdata.RSpans[0],
+						ScopeSpans:   testdata.SSpans[0],
+					},
+					markers: markers[i],
+				}
+			}
+
+			handler := g.stepEvent(tt.step)
+			handler(nil)
+
+			assert.Equal(t, tt.wantCurrentRow, g.currentRow)
+			assert.Equal(t, tt.wantCurrentEvent, g.currentEvent)
 		})
 	}
 }
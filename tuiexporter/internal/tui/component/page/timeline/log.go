@@ -48,7 +48,7 @@ func (l *logPane) updateLog(traceID, spanID string) {
 		}
 		logCount = len(lds)
 		log.Printf("Log count(%s): %d", traceID, logCount)
-		logData := table.NewLogDataForTableForTimeline(&lds)
+		logData := table.NewLogDataForTableForTimeline(lds)
 		if l.logData != nil {
 			logData.SetFullDatetime(l.logData.IsFullDatetime())
 		}
@@ -0,0 +1,147 @@
+package timeline
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/navigation"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+var errNoMatch = errors.New("no span matches the query")
+
+// matchSpan reports whether the span name, the service name or any of the span attribute values
+// contains the query, ignoring the case
+func matchSpan(span *telemetry.SpanData, query string) bool {
+	query = strings.ToLower(query)
+	if strings.Contains(strings.ToLower(span.Span.Name()), query) {
+		return true
+	}
+	if strings.Contains(strings.ToLower(telemetry.GetServiceNameFromResource(span.ResourceSpan.Resource())), query) {
+		return true
+	}
+	matched := false
+	span.Span.Attributes().Range(func(_ string, v pcommon.Value) bool {
+		matched = strings.Contains(strings.ToLower(v.AsString()), query)
+		return !matched
+	})
+	return matched
+}
+
+// flattenSpans returns all spans in the tree in the order shown in the timeline when all of
+// them are unfolded
+func flattenSpans(nodes []*spanTreeNode) []*spanTreeNode {
+	flat := []*spanTreeNode{}
+	for _, n := range nodes {
+		flat = append(flat, n)
+		sort.SliceStable(n.children, func(i, j int) bool {
+			return n.children[i].span.Span.StartTimestamp().AsTime().Before(
+				n.children[j].span.Span.StartTimestamp().AsTime(),
+			)
+		})
+		flat = append(flat, flattenSpans(n.children)...)
+	}
+	return flat
+}
+
+// findSpan moves the cursor to the next (step > 0) or previous (step < 0) span satisfying
+// the condition in the tree, wrapping around at the end. The folded spans are searched too.
+// The current span is checked first if includeCurrent is true, otherwise last.
+func (g *grid) findSpan(cond func(n *spanTreeNode) bool, step int, includeCurrent bool) bool {
+	flat := flattenSpans(g.tree)
+	if len(flat) == 0 {
+		return false
+	}
+	pos := 0
+	if current := g.getCurrentNode(); current != nil {
+		for i, n := range flat {
+			if n == current {
+				pos = i
+				break
+			}
+		}
+	}
+	if includeCurrent {
+		pos -= step
+	}
+	for i := 1; i <= len(flat); i++ {
+		n := flat[((pos+step*i)%len(flat)+len(flat))%len(flat)]
+		if cond(n) {
+			g.jumpToSpan(n.span.Span.SpanID().String())
+			return true
+		}
+	}
+	return false
+}
+
+func (g *grid) isMatched(n *spanTreeNode) bool {
+	return g.query != "" && matchSpan(n.span, g.query)
+}
+
+// stepMatch moves the cursor to the next (step > 0) or previous (step < 0) span matching the query
+func (g *grid) stepMatch(step int) func(_ *tcell.EventKey) *tcell.EventKey {
+	return func(_ *tcell.EventKey) *tcell.EventKey {
+		g.findSpan(g.isMatched, step, false)
+		return nil
+	}
+}
+
+// goToNextError moves the cursor to the next span with the error status
+func (g *grid) goToNextError(_ *tcell.EventKey) *tcell.EventKey {
+	g.findSpan(func(n *spanTreeNode) bool {
+		return n.span.Span.Status().Code() == ptrace.StatusCodeError
+	}, 1, false)
+
+	return nil
+}
+
+// goToSlowest moves the cursor to the span having the longest duration in the trace
+func (g *grid) goToSlowest(_ *tcell.EventKey) *tcell.EventKey {
+	var slowest *spanTreeNode
+	for _, n := range flattenSpans(g.tree) {
+		if slowest == nil || n.end-n.start > slowest.end-slowest.start {
+			slowest = n
+		}
+	}
+	if slowest != nil {
+		g.jumpToSpan(slowest.span.Span.SpanID().String())
+	}
+
+	return nil
+}
+
+// showSearch shows the search field and focuses it
+func (g *grid) showSearch() {
+	g.view.ResizeItem(g.search.View(), 1, 0)
+	navigation.Focus(g.search.View())
+}
+
+// onSearchChanged moves the cursor to the first span matching the query as it is typed
+func (g *grid) onSearchChanged(text string) {
+	g.query = text
+	if text == "" || g.findSpan(g.isMatched, 1, true) {
+		g.search.SetError(nil)
+		return
+	}
+	g.search.SetError(errNoMatch)
+}
+
+func (g *grid) onSearchEnter(inputConfirmed string, _ telemetry.SortType) error {
+	g.query = inputConfirmed
+	if inputConfirmed != "" && !g.findSpan(g.isMatched, 1, true) {
+		return errNoMatch
+	}
+	return nil
+}
+
+// onSearchDone hides the search field unless the query is kept to cycle the matches
+func (g *grid) onSearchDone() {
+	if g.search.InputConfirmed() == "" {
+		g.view.ResizeItem(g.search.View(), 0, 0)
+	}
+	navigation.Focus(g.gridView)
+}
@@ -0,0 +1,115 @@
+package timeline
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"gotest.tools/v3/assert"
+)
+
+func TestMatchSpan(t *testing.T) {
+	n := newTestSpanTreeNode("GET /users", 0, 10)
+	n.span.ResourceSpan.Resource().Attributes().PutStr("service.name", "frontend")
+	n.span.Span.Attributes().PutStr("http.route", "/users/{id}")
+	n.span.Span.Attributes().PutInt("http.response.status_code", 404)
+
+	tests := []struct {
+		name  string
+		query string
+		want  bool
+	}{
+		{name: "span name", query: "get", want: true},
+		{name: "service name", query: "FRONT", want: true},
+		{name: "string attribute value", query: "{id}", want: true},
+		{name: "int attribute value", query: "404", want: true},
+		{name: "attribute key", query: "http.route", want: false},
+		{name: "no match", query: "backend", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, matchSpan(n.span, tt.query))
+		})
+	}
+}
+
+func newTestSearchGrid() (*grid, map[string]*spanTreeNode) {
+	// root          [0, 100]
+	//  ├- db-1      [10, 30] error
+	//  └- call      [40, 90] folded
+	//     └- db-2   [50, 80] error
+	// other         [0, 20]
+	db2 := newTestSpanTreeNode("db-2", 50, 80)
+	db2.span.Span.Status().SetCode(ptrace.StatusCodeError)
+	call := newTestSpanTreeNode("call", 40, 90, db2)
+	db1 := newTestSpanTreeNode("db-1", 10, 30)
+	db1.span.Span.Status().SetCode(ptrace.StatusCodeError)
+	root := newTestSpanTreeNode("root", 0, 100, call, db1)
+	other := newTestSpanTreeNode("other", 0, 20)
+	for _, n := range []*spanTreeNode{root, db1, db2, other} {
+		n.expand = true
+	}
+
+	g := newGrid(nil, nil, nil, nil, nil)
+	g.tree = []*spanTreeNode{root, other}
+	g.placeSpans()
+
+	return g, map[string]*spanTreeNode{"root": root, "db-1": db1, "call": call, "db-2": db2, "other": other}
+}
+
+func TestStepMatch(t *testing.T) {
+	g, nodes := newTestSearchGrid()
+	g.onSearchChanged("DB")
+
+	tests := []struct {
+		name    string
+		handler func(*tcell.EventKey) *tcell.EventKey
+		want    string
+	}{
+		{name: "next in the folded span", handler: g.stepMatch(1), want: "db-2"},
+		{name: "wrap around to the first", handler: g.stepMatch(1), want: "db-1"},
+		{name: "previous wraps around to the last", handler: g.stepMatch(-1), want: "db-2"},
+	}
+
+	// the incremental search moves to the first match
+	assert.Equal(t, nodes["db-1"], g.getCurrentNode())
+	// the steps depend on the previous ones
+	for _, tt := range tests {
+		tt.handler(nil)
+		assert.Equal(t, nodes[tt.want], g.getCurrentNode(), tt.name)
+	}
+	// the ancestors are unfolded
+	assert.Assert(t, nodes["call"].expand)
+}
+
+func TestSearchNoMatch(t *testing.T) {
+	g, nodes := newTestSearchGrid()
+
+	g.onSearchChanged("cache")
+
+	assert.Equal(t, nodes["root"], g.getCurrentNode())
+	assert.Equal(t, errNoMatch, g.onSearchEnter("cache", telemetry.SORT_TYPE_NONE))
+	assert.NilError(t, g.onSearchEnter("", telemetry.SORT_TYPE_NONE))
+}
+
+func TestGoToNextError(t *testing.T) {
+	g, nodes := newTestSearchGrid()
+
+	g.goToNextError(nil)
+	assert.Equal(t, nodes["db-1"], g.getCurrentNode())
+	g.goToNextError(nil)
+	assert.Equal(t, nodes["db-2"], g.getCurrentNode())
+	assert.Assert(t, nodes["call"].expand)
+	g.goToNextError(nil)
+	assert.Equal(t, nodes["db-1"], g.getCurrentNode())
+}
+
+func TestGoToSlowest(t *testing.T) {
+	g, nodes := newTestSearchGrid()
+	g.selectSpan(nodes["other"].span.Span.SpanID().String())
+
+	g.goToSlowest(nil)
+
+	assert.Equal(t, nodes["root"], g.getCurrentNode())
+}
@@ -23,9 +23,20 @@ type TimelinePage struct {
 	onEscape       func()
 	detail         *detail
 	grid           *grid
+	flame          *flame
 	logPane        *logPane
 	isLogCollapsed bool
 	traceID        string
+	history        []location
+	// timelineView shows either the grid or the flame graph
+	timelineView *tview.Flex
+	isFlame      bool
+}
+
+// location is a span in a trace shown in the timeline
+type location struct {
+	traceID string
+	spanID  string
 }
 
 func NewTimelinePage(
@@ -41,21 +52,29 @@ func NewTimelinePage(
 
 	base.AddItem(container, 0, 1, true)
 
+	var timeline *TimelinePage
+
 	resizeManager := layout.NewResizeManager(layout.ResizeDirectionHorizontal)
-	detail := newDetail(commands, resizeManager)
+	detail := newDetail(commands, store.GetTraceCache(), resizeManager, func(traceID, spanID string) {
+		timeline.openLink(traceID, spanID)
+	}, func(spanID string) {
+		timeline.jumpToSpan(spanID)
+	})
 	logPane := newLogPane(commands, store.GetLogCache())
 	grid := newGrid(commands, store.GetTraceCache(), resizeManager, detail, logPane)
+	flame := newFlame(commands, resizeManager, detail, logPane)
+	timelineView := tview.NewFlex().AddItem(grid.view, 0, 1, true)
 
 	resizeManager.Register(
 		mainContainer,
-		grid.gridView,
+		timelineView,
 		detail.view,
 		defaultGridProportion,
 		defaultDetailProportion,
 		commands,
 	)
 
-	timeline := &TimelinePage{
+	timeline = &TimelinePage{
 		switchToPageFn: switchToPageFn,
 		commands:       commands,
 		base:           base,
@@ -65,8 +84,10 @@ func NewTimelinePage(
 		onEscape:       onEscape,
 		detail:         detail,
 		grid:           grid,
+		flame:          flame,
 		logPane:        logPane,
 		isLogCollapsed: true,
+		timelineView:   timelineView,
 	}
 
 	timeline.updateContainer()
@@ -81,30 +102,117 @@ func (p *TimelinePage) GetPrimitive() tview.Primitive {
 	return p.base
 }
 
-func (p *TimelinePage) ShowTimelineByRow(row int) {
-	p.DrawTimeline(
-		p.store.GetTraceIDByFilteredIdx(row),
-	)
-}
-
+// DrawTimeline shows the trace in the timeline and clears the history of the linked traces
 func (p *TimelinePage) DrawTimeline(traceID string) {
 	if traceID == "" {
 		return
 	}
 
-	p.traceID = traceID
+	p.history = nil
+	p.showTrace(location{traceID: traceID})
+}
+
+// openLink shows the linked trace with the linked span selected if the trace is in the cache.
+// The current span is pushed to the history to go back to it.
+func (p *TimelinePage) openLink(traceID, spanID string) {
+	if _, ok := p.store.GetTraceCache().GetSpansByTraceID(traceID); !ok {
+		return
+	}
+
+	current := location{traceID: p.traceID}
+	if span := p.getCurrentSpan(); span != nil {
+		current.spanID = span.Span.SpanID().String()
+	}
+	p.history = append(p.history, current)
+
+	p.showTrace(location{traceID: traceID, spanID: spanID})
+}
+
+// back shows the trace the current one was opened from by the link. The traces which are
+// no longer in the cache are dropped from the history.
+func (p *TimelinePage) back() {
+	for len(p.history) > 0 {
+		prev := p.history[len(p.history)-1]
+		p.history = p.history[:len(p.history)-1]
+
+		if _, ok := p.store.GetTraceCache().GetSpansByTraceID(prev.traceID); ok {
+			p.showTrace(prev)
+			return
+		}
+	}
+}
+
+func (p *TimelinePage) showTrace(loc location) {
+	p.traceID = loc.traceID
 
 	p.container.Clear()
 	p.mainContainer.Clear()
 
-	span := p.grid.updateGrid(traceID)
+	span := p.grid.updateGrid(loc.traceID)
+	if selected := p.grid.selectSpan(loc.spanID); selected != nil {
+		span = selected
+	}
+	p.flame.update(p.grid.tree)
+	if span != nil {
+		p.flame.selectSpan(span.Span.SpanID().String())
+	}
+	p.updateTitle()
 	p.detail.update(span)
-	p.logPane.updateLog(traceID, span.Span.SpanID().String())
+	if span != nil {
+		p.logPane.updateLog(loc.traceID, span.Span.SpanID().String())
+	}
 
 	p.updateContainer()
 
 	p.switchToPageFn()
-	navigation.Focus(p.grid.gridView)
+	p.focusTimeline()
+}
+
+// getCurrentSpan returns the span selected in the grid or the flame graph shown
+func (p *TimelinePage) getCurrentSpan() *telemetry.SpanData {
+	if p.isFlame {
+		return p.flame.getCurrentSpan()
+	}
+	return p.grid.getCurrentSpan()
+}
+
+func (p *TimelinePage) focusTimeline() {
+	if p.isFlame {
+		navigation.Focus(p.flame.view)
+	} else {
+		navigation.Focus(p.grid.gridView)
+	}
+}
+
+// jumpToSpan moves the cursor of the grid or the flame graph shown to the span
+func (p *TimelinePage) jumpToSpan(spanID string) {
+	if p.isFlame {
+		if p.flame.selectSpan(spanID) != nil {
+			p.flame.updateCurrentSpan()
+		}
+	} else {
+		p.grid.jumpToSpan(spanID)
+	}
+	p.focusTimeline()
+}
+
+// toggleFlame switches between the grid and the flame graph keeping the selected span
+func (p *TimelinePage) toggleFlame() {
+	spanID := ""
+	if span := p.getCurrentSpan(); span != nil {
+		spanID = span.Span.SpanID().String()
+	}
+
+	p.isFlame = !p.isFlame
+	p.timelineView.Clear()
+	if p.isFlame {
+		p.flame.selectSpan(spanID)
+		p.timelineView.AddItem(p.flame.view, 0, 1, true)
+	} else {
+		p.grid.jumpToSpan(spanID)
+		p.timelineView.AddItem(p.grid.view, 0, 1, true)
+	}
+	p.focusTimeline()
 }
 
 func (p *TimelinePage) registerCommands() {
@@ -119,7 +227,14 @@ func (p *TimelinePage) registerCommands() {
 		{
 			Key: tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone),
 			Handler: func(event *tcell.EventKey) *tcell.EventKey {
-				navigation.Focus(p.grid.gridView)
+				p.focusTimeline()
+				return nil
+			},
+		},
+		{
+			Key: tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone),
+			Handler: func(event *tcell.EventKey) *tcell.EventKey {
+				p.toggleFlame()
 				return nil
 			},
 		},
@@ -147,7 +262,22 @@ func (p *TimelinePage) registerCommands() {
 		{
 			Key: tcell.NewEventKey(tcell.KeyRune, 'A', tcell.ModNone),
 			Handler: func(event *tcell.EventKey) *tcell.EventKey {
-				p.logPane.toggleAllLogs(p.traceID, p.grid.getCurrentSpan())
+				p.logPane.toggleAllLogs(p.traceID, p.getCurrentSpan())
+				return nil
+			},
+		},
+		{
+			Key: tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone),
+			Handler: func(event *tcell.EventKey) *tcell.EventKey {
+				p.store.TogglePinTrace(p.traceID)
+				p.updateTitle()
+				return nil
+			},
+		},
+		{
+			Key: tcell.NewEventKey(tcell.KeyBackspace2, ' ', tcell.ModNone),
+			Handler: func(event *tcell.EventKey) *tcell.EventKey {
+				p.back()
 				return nil
 			},
 		},
@@ -160,10 +290,30 @@ func (p *TimelinePage) registerCommands() {
 		},
 	}
 	layout.RegisterCommandList(p.commands, p.container, nil, keyMaps)
+
+	// pass all keys to the search field while typing the query
+	capture := p.container.GetInputCapture()
+	p.container.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if p.grid.search.View().HasFocus() {
+			return event
+		}
+		return capture(event)
+	})
+}
+
+// updateTitle shows whether the trace is pinned in the title of the timeline and the flame graph
+func (p *TimelinePage) updateTitle() {
+	if p.store.IsPinned(p.traceID) {
+		p.grid.gridView.SetTitle("Pinned Trace Timeline (t)")
+		p.flame.view.SetTitle("Pinned Flame Graph (t)")
+	} else {
+		p.grid.gridView.SetTitle("Trace Timeline (t)")
+		p.flame.view.SetTitle("Flame Graph (t)")
+	}
 }
 
 func (p *TimelinePage) updateContainer() {
-	p.mainContainer.AddItem(p.grid.gridView, 0, defaultGridProportion, true).
+	p.mainContainer.AddItem(p.timelineView, 0, defaultGridProportion, true).
 		AddItem(p.detail.view, 0, defaultDetailProportion, false)
 	p.container.AddItem(p.mainContainer, 0, 1, true).
 		AddItem(p.logPane.tableView, 2, 1, false)
@@ -1,6 +1,7 @@
 package timeline
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -144,6 +145,31 @@ func TestTimelinePage(t *testing.T) {
 				assert.Equal(t, want, got.String())
 			})
 
+			t.Run("critical path only", func(t *testing.T) {
+				mockHandler, page, _, store := setupTimelinePage(t)
+
+				payload, spans := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{3}})
+				store.AddSpan(&payload)
+
+				mockHandler.On("switchToPageHandler").Return().Once()
+
+				page.DrawTimeline(spans.Spans[0].TraceID().String())
+				page.grid.gridView.Focus(nil)
+				assert.Equal(t, 3, page.grid.totalRow)
+
+				handler := page.base.InputHandler()
+				handler(tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone), nil)
+
+				// the spans have the same start and end time, so only the first one is on the critical path
+				assert.Equal(t, 1, page.grid.totalRow)
+				assert.Equal(t, " span-0-0-0 200ms (cp 200ms)", page.grid.texts[0])
+				assert.Equal(t, page.grid.nodes[0].span, page.grid.getCurrentSpan())
+
+				handler(tcell.NewEventKey(tcell.KeyRune, 'c', tcell.ModNone), nil)
+
+				assert.Equal(t, 3, page.grid.totalRow)
+			})
+
 			tests := []struct {
 				name            string
 				key             *tcell.EventKey
@@ -295,3 +321,244 @@ func TestTimelinePage(t *testing.T) {
 		})
 	})
 }
+
+func TestTimelinePageSpanLink(t *testing.T) {
+	mockHandler, page, _, store := setupTimelinePage(t)
+
+	producer, pspans := test.GenerateOTLPTracesPayload(t, 2, 1, []int{1}, [][]int{{3}})
+	consumer, cspans := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{1}})
+	linked := pspans.Spans[2]
+	link := cspans.Spans[0].Links().At(0)
+	link.SetTraceID(linked.TraceID())
+	link.SetSpanID(linked.SpanID())
+	store.AddSpan(&producer)
+	store.AddSpan(&consumer)
+
+	mockHandler.On("switchToPageHandler").Return().Times(3)
+
+	consumerTraceID := cspans.Spans[0].TraceID().String()
+	page.DrawTimeline(consumerTraceID)
+
+	var linkNode *tview.TreeNode
+	for _, n := range page.detail.tree.GetRoot().GetChildren() {
+		if n.GetText() == "Links" {
+			linkNode = n.GetChildren()[0]
+		}
+	}
+	assert.Equal(t, "link 0 → "+linked.Name()+" (test-service-1)", linkNode.GetText())
+
+	t.Run("open the linked trace", func(t *testing.T) {
+		page.detail.tree.SetCurrentNode(linkNode)
+		page.detail.tree.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, ' ', tcell.ModNone), nil)
+
+		assert.Equal(t, linked.TraceID().String(), page.traceID)
+		assert.Equal(t, linked.SpanID().String(), page.grid.getCurrentSpan().Span.SpanID().String())
+		assert.Equal(t, 1, len(page.history))
+	})
+
+	t.Run("back to the originating trace", func(t *testing.T) {
+		handler := page.base.InputHandler()
+		handler(tcell.NewEventKey(tcell.KeyBackspace2, ' ', tcell.ModNone), nil)
+
+		assert.Equal(t, consumerTraceID, page.traceID)
+		assert.Equal(t, cspans.Spans[0].SpanID().String(), page.grid.getCurrentSpan().Span.SpanID().String())
+		assert.Equal(t, 0, len(page.history))
+
+		// nothing happens without the history
+		handler(tcell.NewEventKey(tcell.KeyBackspace2, ' ', tcell.ModNone), nil)
+		assert.Equal(t, consumerTraceID, page.traceID)
+	})
+
+	mockHandler.AssertExpectations(t)
+}
+
+func TestTimelinePageSpanLinkNotInCache(t *testing.T) {
+	mockHandler, page, _, store := setupTimelinePage(t)
+
+	payload, spans := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{1}})
+	store.AddSpan(&payload)
+
+	mockHandler.On("switchToPageHandler").Return().Once()
+
+	traceID := spans.Spans[0].TraceID().String()
+	page.DrawTimeline(traceID)
+	page.openLink(spans.Spans[0].Links().At(0).TraceID().String(), "")
+
+	assert.Equal(t, traceID, page.traceID)
+	assert.Equal(t, 0, len(page.history))
+	mockHandler.AssertExpectations(t)
+}
+
+func TestTimelinePageSpanLinkBackToEvictedTrace(t *testing.T) {
+	mockHandler, page, _, store := setupTimelinePage(t)
+
+	producer, pspans := test.GenerateOTLPTracesPayload(t, 2, 1, []int{1}, [][]int{{1}})
+	consumer, cspans := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{1}})
+	store.AddSpan(&producer)
+	store.AddSpan(&consumer)
+
+	mockHandler.On("switchToPageHandler").Return().Twice()
+
+	producerTraceID := pspans.Spans[0].TraceID().String()
+	page.DrawTimeline(cspans.Spans[0].TraceID().String())
+	page.openLink(producerTraceID, pspans.Spans[0].SpanID().String())
+	assert.Equal(t, 1, len(page.history))
+
+	// the originating trace in the history is flushed while the linked one is pinned
+	store.TogglePinTrace(producerTraceID)
+	store.Flush()
+
+	page.back()
+
+	assert.Equal(t, producerTraceID, page.traceID)
+	assert.Equal(t, 0, len(page.history))
+	mockHandler.AssertExpectations(t)
+}
+
+func TestTimelinePageTopSelfTime(t *testing.T) {
+	mockHandler, page, _, store := setupTimelinePage(t)
+
+	// span-0-0-0
+	//  ├- span-0-0-1
+	//  └- span-0-0-2
+	payload, spans := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{3}})
+	spans.Spans[1].SetParentSpanID(spans.Spans[0].SpanID())
+	spans.Spans[2].SetParentSpanID(spans.Spans[0].SpanID())
+	store.AddSpan(&payload)
+
+	mockHandler.On("switchToPageHandler").Return().Once()
+
+	page.DrawTimeline(spans.Spans[0].TraceID().String())
+	page.grid.gridView.Focus(nil)
+
+	// the children cover the whole duration of the parent
+	assert.Equal(t, "▼span-0-0-0 200ms self 0s (cp 0s)", page.grid.texts[0])
+
+	var (
+		selfTimeNode *tview.TreeNode
+		topNode      *tview.TreeNode
+	)
+	for _, n := range page.detail.tree.GetRoot().GetChildren() {
+		if strings.HasPrefix(n.GetText(), "self time:") {
+			selfTimeNode = n
+		}
+		if n.GetText() == "Top Self Time" {
+			topNode = n
+		}
+	}
+	assert.Equal(t, "self time: 0s", selfTimeNode.GetText())
+	assert.Equal(t, 3, len(topNode.GetChildren()))
+	assert.Equal(t, "span-0-0-1 200ms (test-service-1)", topNode.GetChildren()[0].GetText())
+	assert.Equal(t, "span-0-0-2 200ms (test-service-1)", topNode.GetChildren()[1].GetText())
+	assert.Equal(t, "span-0-0-0 0s (test-service-1)", topNode.GetChildren()[2].GetText())
+
+	t.Run("jump to the folded span", func(t *testing.T) {
+		handler := page.base.InputHandler()
+		handler(tcell.NewEventKey(tcell.KeyEnter, ' ', tcell.ModNone), nil)
+		assert.Equal(t, 1, page.grid.totalRow)
+
+		page.detail.tree.SetCurrentNode(topNode.GetChildren()[1])
+		page.detail.tree.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, ' ', tcell.ModNone), nil)
+
+		assert.Equal(t, 3, page.grid.totalRow)
+		assert.Equal(t, 2, page.grid.currentRow)
+		assert.Equal(t, spans.Spans[2].SpanID().String(), page.grid.getCurrentSpan().Span.SpanID().String())
+	})
+
+	mockHandler.AssertExpectations(t)
+}
+
+func TestTimelinePageFlameGraph(t *testing.T) {
+	mockHandler, page, _, store := setupTimelinePage(t)
+
+	payload, spans := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{3}})
+	store.AddSpan(&payload)
+
+	mockHandler.On("switchToPageHandler").Return().Once()
+
+	page.DrawTimeline(spans.Spans[0].TraceID().String())
+	page.grid.gridView.Focus(nil)
+
+	handler := page.base.InputHandler()
+	handler(tcell.NewEventKey(tcell.KeyDown, ' ', tcell.ModNone), nil)
+
+	t.Run("show the flame graph with the selected span", func(t *testing.T) {
+		handler(tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone), nil)
+		page.grid.gridView.Blur()
+		page.flame.view.Focus(nil)
+
+		assert.Assert(t, page.isFlame)
+		assert.Equal(t, page.flame.view, page.timelineView.GetItem(0))
+		assert.Equal(t, spans.Spans[1].SpanID(), page.getCurrentSpan().Span.SpanID())
+	})
+
+	t.Run("the selection is shown in the detail", func(t *testing.T) {
+		page.flame.stepBy(1)(nil)
+
+		assert.Equal(t, spans.Spans[2].SpanID(), page.getCurrentSpan().Span.SpanID())
+		var nameNode *tview.TreeNode
+		for _, n := range page.detail.tree.GetRoot().GetChildren() {
+			if strings.HasPrefix(n.GetText(), "name:") {
+				nameNode = n
+			}
+		}
+		assert.Equal(t, "name: "+spans.Spans[2].Name(), nameNode.GetText())
+	})
+
+	t.Run("back to the grid with the selected span", func(t *testing.T) {
+		handler(tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone), nil)
+
+		assert.Assert(t, !page.isFlame)
+		assert.Equal(t, page.grid.view, page.timelineView.GetItem(0))
+		assert.Equal(t, 2, page.grid.currentRow)
+	})
+
+	mockHandler.AssertExpectations(t)
+}
+
+func TestTimelinePageFind(t *testing.T) {
+	mockHandler, page, _, store := setupTimelinePage(t)
+
+	payload, spans := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{3}})
+	store.AddSpan(&payload)
+
+	mockHandler.On("switchToPageHandler").Return().Once()
+
+	page.DrawTimeline(spans.Spans[0].TraceID().String())
+	page.grid.gridView.Focus(nil)
+
+	handler := page.base.InputHandler()
+	key := func(r rune) {
+		handler(tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone), nil)
+	}
+
+	t.Run("incremental search", func(t *testing.T) {
+		key('/')
+		page.grid.gridView.Blur()
+		page.grid.search.View().Focus(nil)
+
+		key('2')
+		assert.Equal(t, 2, page.grid.currentRow)
+
+		// the keys of the page are typed in the field
+		key('d')
+		assert.Equal(t, "2d", page.grid.search.View().GetText())
+		assert.Equal(t, 2, page.grid.currentRow)
+
+		handler(tcell.NewEventKey(tcell.KeyBackspace2, ' ', tcell.ModNone), nil)
+		handler(tcell.NewEventKey(tcell.KeyEnter, ' ', tcell.ModNone), nil)
+		assert.Equal(t, "2", page.grid.search.InputConfirmed())
+	})
+
+	t.Run("cycle the matches", func(t *testing.T) {
+		page.grid.search.View().Blur()
+		page.grid.gridView.Focus(nil)
+
+		key('g')
+		assert.Equal(t, 0, page.grid.currentRow)
+		key('n')
+		assert.Equal(t, 2, page.grid.currentRow)
+	})
+
+	mockHandler.AssertExpectations(t)
+}
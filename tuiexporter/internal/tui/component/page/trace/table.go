@@ -6,6 +6,7 @@ import (
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/export"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/filter"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/layout"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/tui/component/navigation"
@@ -19,11 +20,14 @@ type table struct {
 	spanData *ctable.SpanDataForTable
 	filter   *filter.Filter
 	detail   *detail
+	// onCompareTraces is called with the traces marked to compare
+	onCompareTraces func(baseTraceID, targetTraceID string)
 }
 
 func newTable(
 	commands *tview.TextView,
-	onSelectTableRow func(row, column int),
+	onSelectTrace func(traceID string),
+	onCompareTraces func(baseTraceID, targetTraceID string),
 	store *telemetry.Store,
 	detail *detail,
 	resizeManager *layout.ResizeManager,
@@ -34,49 +38,57 @@ func newTable(
 	t := tview.NewTable().
 		SetBorders(false).
 		SetSelectable(true, false).
-		SetSelectedFunc(onSelectTableRow).
 		SetFixed(1, 0)
 
 	filter := filter.NewFilter(
 		commands,
-		"Filter by service or span name (/): ",
-		func(inputConfirmed string, sortType telemetry.SortType) {
-			store.ApplyFilterTraces(inputConfirmed, sortType)
+		"Filter by text or query (/): ",
+		func(inputConfirmed string, sortType telemetry.SortType) error {
+			return store.ApplyFilterTraces(inputConfirmed, sortType)
 		},
 		func() {
 			navigation.Focus(t)
 		},
 		nil,
-		func(inputConfirmed string, sortType telemetry.SortType) {
-			store.ApplyFilterTraces(inputConfirmed, sortType)
+		func(inputConfirmed string, sortType telemetry.SortType) error {
+			return store.ApplyFilterTraces(inputConfirmed, sortType)
 		},
 	)
+	filter.SetPlaceholder("e.g. service.name=api status=error duration>250ms")
 
-	spanData := ctable.NewSpanDataForTable(store.GetTraceCache(), store.GetFilteredSvcSpans(), filter.SortType())
+	spanData := ctable.NewSpanDataForTable(store.GetTraceCache(), store.SnapshotSvcSpans, filter.SortType())
 	t.SetContent(&spanData)
+	ctable.RefreshOnDraw(t, &spanData)
+	t.SetSelectedFunc(func(row, _ int) {
+		if sd := spanData.At(row - 1); sd != nil {
+			onSelectTrace(sd.Span.TraceID().String())
+		}
+	})
 	store.SetOnSpanAdded(func() {
 		if detail.tree.GetRoot() == nil {
+			spanData.Refresh()
 			// Select the first data row (row 1), not the header (row 0)
 			t.Select(1, 0)
 		}
 	})
 
 	stable := &table{
-		store:    store,
-		view:     container,
-		table:    t,
-		spanData: &spanData,
-		filter:   filter,
-		detail:   detail,
+		store:           store,
+		view:            container,
+		table:           t,
+		spanData:        &spanData,
+		filter:          filter,
+		detail:          detail,
+		onCompareTraces: onCompareTraces,
 	}
 
 	t.SetSelectionChangedFunc(stable.onSelectionChangedFunc())
 
-	container.
-		AddItem(filter.View(), 1, 0, false).
+	filter.AddTo(container).
 		AddItem(t, 0, 1, true)
 
 	stable.registerCommands(commands, resizeManager)
+	ctable.RefreshOnInput(t, &spanData)
 
 	return stable
 }
@@ -110,17 +122,55 @@ func (t *table) registerCommands(commands *tview.TextView, resizeManager *layout
 			},
 		},
 		{
-			Key:         tcell.NewEventKey(tcell.KeyRune, 'R', tcell.ModNone),
-			Description: "Recalculate service root span",
+			Key:         tcell.NewEventKey(tcell.KeyRune, 'S', tcell.ModNone),
+			Description: "Save",
+			Handler: func(_ *tcell.EventKey) *tcell.EventKey {
+				export.SaveAndNotify(commands, "traces", "spans", t.store.ExportFilteredTraces)
+				return nil
+			},
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone),
+			Description: "Toggle pin",
 			Handler: func(_ *tcell.EventKey) *tcell.EventKey {
-				log.Println("Recalculate service root span")
 				row, _ := t.table.GetSelection()
-				if row == 0 {
-					return nil
+				if sd := t.spanData.At(row - 1); sd != nil {
+					t.store.TogglePinTrace(sd.Span.TraceID().String())
+				}
+				return nil
+			},
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, 'P', tcell.ModNone),
+			Description: "Toggle pinned only",
+			Handler: func(_ *tcell.EventKey) *tcell.EventKey {
+				if t.store.TogglePinnedOnly() {
+					t.view.SetTitle("Pinned Traces (t)")
+				} else {
+					t.view.SetTitle("Traces (t)")
+				}
+				t.table.Select(1, 0)
+				return nil
+			},
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, 'm', tcell.ModNone),
+			Description: "Mark to compare",
+			Handler: func(_ *tcell.EventKey) *tcell.EventKey {
+				row, _ := t.table.GetSelection()
+				if sd := t.spanData.At(row - 1); sd != nil {
+					t.spanData.ToggleMark(sd.Span.TraceID().String())
+				}
+				return nil
+			},
+		},
+		{
+			Key:         tcell.NewEventKey(tcell.KeyRune, 'C', tcell.ModNone),
+			Description: "Compare marked traces",
+			Handler: func(_ *tcell.EventKey) *tcell.EventKey {
+				if marked := t.spanData.MarkedTraces(); len(marked) == 2 && t.onCompareTraces != nil {
+					t.onCompareTraces(marked[0], marked[1])
 				}
-				log.Printf("Recalculating service root spans")
-				t.store.RecalculateServiceRootSpanByIdx(row - 1)
-
 				return nil
 			},
 		},
@@ -143,8 +193,12 @@ func (t *table) onSelectionChangedFunc() func(row, col int) {
 		if row == 0 {
 			return
 		}
-		spans := t.store.GetFilteredServiceSpansByIdx(row - 1)
-		if spans == nil {
+		sd := t.spanData.At(row - 1)
+		if sd == nil {
+			return
+		}
+		spans, ok := t.store.GetTraceCache().GetSpansByTraceIDAndSvc(sd.Span.TraceID().String(), sd.GetServiceName())
+		if !ok {
 			return
 		}
 		t.detail.update(spans)
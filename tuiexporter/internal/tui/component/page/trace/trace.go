@@ -20,7 +20,8 @@ type TracePage struct {
 }
 
 func NewTracePage(
-	onSelectTableRow func(row, column int),
+	onSelectTrace func(traceID string),
+	onCompareTraces func(baseTraceID, targetTraceID string),
 	store *telemetry.Store,
 ) *TracePage {
 	commands := layout.NewCommandList()
@@ -28,7 +29,7 @@ func NewTracePage(
 
 	resizeManager := layout.NewResizeManager(layout.ResizeDirectionHorizontal)
 	detail := newDetail(commands, resizeManager)
-	table := newTable(commands, onSelectTableRow, store, detail, resizeManager)
+	table := newTable(commands, onSelectTrace, onCompareTraces, store, detail, resizeManager)
 
 	resizeManager.Register(
 		container,
@@ -13,18 +13,22 @@ import (
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/test"
 )
 
-type mockSelectTableRowHandler struct {
+type mockSelectTraceHandler struct {
 	mock.Mock
 }
 
-func (m *mockSelectTableRowHandler) Handle(row, column int) {
-	m.Called(row, column)
+func (m *mockSelectTraceHandler) Handle(traceID string) {
+	m.Called(traceID)
 }
 
-func setupTracePage(t *testing.T) (*mockSelectTableRowHandler, *TracePage, tcell.SimulationScreen, *telemetry.Store) {
+func (m *mockSelectTraceHandler) HandleCompare(baseTraceID, targetTraceID string) {
+	m.Called(baseTraceID, targetTraceID)
+}
+
+func setupTracePage(t *testing.T) (*mockSelectTraceHandler, *TracePage, tcell.SimulationScreen, *telemetry.Store) {
 	t.Helper()
 
-	mockHandler := new(mockSelectTableRowHandler)
+	mockHandler := new(mockSelectTraceHandler)
 	mockClock := clockwork.NewFakeClockAt(time.Date(2025, 11, 9, 12, 15, 0, 0, time.UTC))
 	store := telemetry.NewStore(mockClock)
 
@@ -35,7 +39,7 @@ func setupTracePage(t *testing.T) (*mockSelectTableRowHandler, *TracePage, tcell
 	}
 	screen.SetSize(sw, sh)
 
-	page := NewTracePage(mockHandler.Handle, store)
+	page := NewTracePage(mockHandler.Handle, mockHandler.HandleCompare, store)
 	page.table.table.Focus(nil)
 
 	page.view.SetRect(0, 0, sw, sh)
@@ -157,10 +161,10 @@ func TestTracePage(t *testing.T) {
 			t.Run("select table row", func(t *testing.T) {
 				mockHandler, page, screen, store := setupTracePage(t)
 
-				payload, _ := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{1}})
+				payload, testdata := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{1}})
 				store.AddSpan(&payload)
 
-				mockHandler.On("Handle", 1, 0).Once()
+				mockHandler.On("Handle", testdata.Spans[0].TraceID().String()).Once()
 
 				handler := page.table.view.InputHandler()
 				handler(tcell.NewEventKey(tcell.KeyEnter, ' ', tcell.ModNone), nil)
@@ -171,6 +175,71 @@ func TestTracePage(t *testing.T) {
 				mockHandler.AssertExpectations(t)
 			})
 
+			t.Run("pin trace", func(t *testing.T) {
+				_, page, screen, store := setupTracePage(t)
+
+				payload1, testdata1 := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{1}})
+				payload2, _ := test.GenerateOTLPTracesPayload(t, 2, 1, []int{1}, [][]int{{1}})
+				store.AddSpan(&payload1)
+				store.AddSpan(&payload2)
+
+				handler := page.table.view.InputHandler()
+				handler(tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone), nil)
+
+				page.view.Draw(screen)
+				screen.Sync()
+
+				assert.True(t, store.IsPinned(testdata1.Spans[0].TraceID().String()))
+				assert.Equal(t, "*", page.table.table.GetCell(1, 1).Text)
+				assert.Equal(t, "", page.table.table.GetCell(2, 1).Text)
+
+				handler(tcell.NewEventKey(tcell.KeyRune, 'P', tcell.ModNone), nil)
+
+				page.view.Draw(screen)
+				screen.Sync()
+
+				assert.Equal(t, 1, page.table.spanData.Len())
+				assert.Equal(t, "Pinned Traces (t)", page.table.view.GetTitle())
+
+				handler(tcell.NewEventKey(tcell.KeyRune, 'P', tcell.ModNone), nil)
+
+				page.view.Draw(screen)
+				screen.Sync()
+
+				assert.Equal(t, 2, page.table.spanData.Len())
+				assert.Equal(t, "Traces (t)", page.table.view.GetTitle())
+			})
+
+			t.Run("compare marked traces", func(t *testing.T) {
+				mockHandler, page, _, store := setupTracePage(t)
+
+				payload1, testdata1 := test.GenerateOTLPTracesPayload(t, 1, 1, []int{1}, [][]int{{1}})
+				payload2, testdata2 := test.GenerateOTLPTracesPayload(t, 2, 1, []int{1}, [][]int{{1}})
+				store.AddSpan(&payload1)
+				store.AddSpan(&payload2)
+
+				handler := page.table.view.InputHandler()
+				// nothing happens until two traces are marked
+				handler(tcell.NewEventKey(tcell.KeyRune, 'm', tcell.ModNone), nil)
+				handler(tcell.NewEventKey(tcell.KeyRune, 'C', tcell.ModNone), nil)
+
+				page.table.table.Select(2, 0)
+				handler(tcell.NewEventKey(tcell.KeyRune, 'm', tcell.ModNone), nil)
+
+				assert.Equal(t, "A", page.table.table.GetCell(1, 1).Text)
+				assert.Equal(t, "B", page.table.table.GetCell(2, 1).Text)
+
+				mockHandler.On(
+					"HandleCompare",
+					testdata1.Spans[0].TraceID().String(),
+					testdata2.Spans[0].TraceID().String(),
+				).Once()
+
+				handler(tcell.NewEventKey(tcell.KeyRune, 'C', tcell.ModNone), nil)
+
+				mockHandler.AssertExpectations(t)
+			})
+
 			t.Run("flush", func(t *testing.T) {
 				_, page, screen, store := setupTracePage(t)
 
@@ -81,16 +81,16 @@ var logCellMappersForTimeline = cellMappers[telemetry.LogData]{
 // LogDataForTable is a wrapper for logs to be displayed in a table
 type LogDataForTable struct {
 	tview.TableContentReadOnly
-	logs           *[]*telemetry.LogData
+	*snapshotSource[telemetry.LogData]
 	mapper         cellMappers[telemetry.LogData]
 	isFullDatetime bool
 }
 
-// NewLogDataForTable creates a new LogDataForTable.
-func NewLogDataForTable(logs *[]*telemetry.LogData) LogDataForTable {
+// NewLogDataForTable creates a new LogDataForTable rendering the snapshots returned by source.
+func NewLogDataForTable(source func() *telemetry.Snapshot[telemetry.LogData]) LogDataForTable {
 	l := LogDataForTable{
-		logs:   logs,
-		mapper: defaultLogCellMappers,
+		snapshotSource: newSnapshotSource(source),
+		mapper:         defaultLogCellMappers,
 	}
 	l.updateTimestampMapper()
 
@@ -98,9 +98,12 @@ func NewLogDataForTable(logs *[]*telemetry.LogData) LogDataForTable {
 }
 
 // NewLogDataForTableForTimeline creates a new LogDataForTable for timeline page.
-func NewLogDataForTableForTimeline(logs *[]*telemetry.LogData) LogDataForTable {
+func NewLogDataForTableForTimeline(logs []*telemetry.LogData) LogDataForTable {
+	snapshot := telemetry.NewSnapshot(logs, 0)
 	l := LogDataForTable{
-		logs:   logs,
+		snapshotSource: newSnapshotSource(func() *telemetry.Snapshot[telemetry.LogData] {
+			return snapshot
+		}),
 		mapper: logCellMappersForTimeline,
 	}
 	l.updateTimestampMapper()
@@ -142,14 +145,14 @@ func (l LogDataForTable) GetCell(row, column int) *tview.TableCell {
 	if row == 0 {
 		return l.getHeaderCell(column)
 	}
-	if row > 0 && row <= len(*l.logs) {
-		return getCellFromData(l.mapper, (*l.logs)[row-1], column)
+	if ld := l.At(row - 1); row > 0 && ld != nil {
+		return getCellFromData(l.mapper, ld, column)
 	}
 	return tview.NewTableCell("N/A")
 }
 
 func (l LogDataForTable) GetRowCount() int {
-	return len(*l.logs) + 1
+	return l.Len() + 1
 }
 
 func (l LogDataForTable) GetColumnCount() int {
@@ -36,7 +36,7 @@ func TestLogDataForTable(t *testing.T) {
 	_, testdata1 := test.GenerateOTLPLogsPayload(t, 1, 2, []int{2, 1}, [][]int{{2, 1}, {1}})
 	_, testdata2 := test.GenerateOTLPLogsPayload(t, 2, 1, []int{1}, [][]int{{1}})
 	testdata1.Logs[0].Attributes().PutStr("event.name", "device.app.lifecycle")
-	logs := &[]*telemetry.LogData{
+	logs := []*telemetry.LogData{
 		{
 			Log:         testdata1.Logs[0],
 			ResourceLog: testdata1.RLogs[0],
@@ -78,7 +78,9 @@ func TestLogDataForTable(t *testing.T) {
 			ResourceLog: testdata2.RLogs[0],
 		},
 	}
-	ldftable := NewLogDataForTable(logs)
+	ldftable := NewLogDataForTable(func() *telemetry.Snapshot[telemetry.LogData] {
+		return telemetry.NewSnapshot(logs, 0)
+	})
 	ldftableForTL := NewLogDataForTableForTimeline(logs)
 
 	t.Run("GetRowCount", func(t *testing.T) {
@@ -1,48 +1,62 @@
 package table
 
 import (
+	"strconv"
+
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
 )
 
-var defaultMetricCellMappers = cellMappers[telemetry.MetricData]{
+var defaultMetricCellMappers = cellMappers[telemetry.MetricSeries]{
 	0: {
 		header: "Service Name",
-		getTextRowFn: func(data *telemetry.MetricData) string {
+		getTextRowFn: func(data *telemetry.MetricSeries) string {
 			return data.GetServiceName()
 		},
 	},
 	1: {
 		header: "Metric Name",
-		getTextRowFn: func(data *telemetry.MetricData) string {
+		getTextRowFn: func(data *telemetry.MetricSeries) string {
 			return data.GetMetricName()
 		},
 	},
 	2: {
 		header: "Metric Type",
-		getTextRowFn: func(data *telemetry.MetricData) string {
+		getTextRowFn: func(data *telemetry.MetricSeries) string {
 			return data.GetMetricTypeText()
 		},
 	},
 	3: {
-		header: "Data Point Count",
-		getTextRowFn: func(data *telemetry.MetricData) string {
-			return data.GetDataPointNum()
+		header: "Attributes",
+		getTextRowFn: func(data *telemetry.MetricSeries) string {
+			return data.GetAttributesText()
+		},
+	},
+	4: {
+		header: "Last Value",
+		getTextRowFn: func(data *telemetry.MetricSeries) string {
+			return data.GetLastValueText()
+		},
+	},
+	5: {
+		header: "Points",
+		getTextRowFn: func(data *telemetry.MetricSeries) string {
+			return strconv.Itoa(data.Len())
 		},
 	},
 }
 
 type MetricDataForTable struct {
 	tview.TableContentReadOnly
-	metrics *[]*telemetry.MetricData
-	mapper  cellMappers[telemetry.MetricData]
+	*snapshotSource[telemetry.MetricSeries]
+	mapper cellMappers[telemetry.MetricSeries]
 }
 
-func NewMetricDataForTable(metrics *[]*telemetry.MetricData) MetricDataForTable {
+func NewMetricDataForTable(source func() *telemetry.Snapshot[telemetry.MetricSeries]) MetricDataForTable {
 	return MetricDataForTable{
-		metrics: metrics,
-		mapper:  defaultMetricCellMappers,
+		snapshotSource: newSnapshotSource(source),
+		mapper:         defaultMetricCellMappers,
 	}
 }
 
@@ -52,14 +66,14 @@ func (m MetricDataForTable) GetCell(row, column int) *tview.TableCell {
 	if row == 0 {
 		return m.getHeaderCell(column)
 	}
-	if row > 0 && row <= len(*m.metrics) {
-		return getCellFromData(m.mapper, (*m.metrics)[row-1], column)
+	if md := m.At(row - 1); row > 0 && md != nil {
+		return getCellFromData(m.mapper, md, column)
 	}
 	return tview.NewTableCell("N/A")
 }
 
 func (m MetricDataForTable) GetRowCount() int {
-	return len(*m.metrics) + 1
+	return m.Len() + 1
 }
 
 func (m MetricDataForTable) GetColumnCount() int {
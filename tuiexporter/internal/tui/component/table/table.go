@@ -1,6 +1,12 @@
 package table
 
-import "github.com/rivo/tview"
+import (
+	"sync/atomic"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/ymtdzzz/otel-tui/tuiexporter/internal/telemetry"
+)
 
 type getTextByDataFunc[T any] func(data *T) string
 
@@ -26,3 +32,62 @@ func getCellFromData[T any](mappers cellMappers[T], data *T, column int) *tview.
 
 	return tview.NewTableCell(text)
 }
+
+// snapshotSource holds the snapshot of the store rendered in a table.
+// The table keeps rendering the same snapshot until Refresh is called.
+type snapshotSource[T any] struct {
+	source   func() *telemetry.Snapshot[T]
+	snapshot atomic.Pointer[telemetry.Snapshot[T]]
+}
+
+func newSnapshotSource[T any](source func() *telemetry.Snapshot[T]) *snapshotSource[T] {
+	s := &snapshotSource[T]{
+		source: source,
+	}
+	s.Refresh()
+
+	return s
+}
+
+// Refresh takes the latest snapshot from the source
+func (s *snapshotSource[T]) Refresh() {
+	s.snapshot.Store(s.source())
+}
+
+// At returns the item at the given index in the current snapshot, or nil if the index is out of range
+func (s *snapshotSource[T]) At(idx int) *T {
+	return s.snapshot.Load().At(idx)
+}
+
+// Len returns the number of items in the current snapshot
+func (s *snapshotSource[T]) Len() int {
+	return s.snapshot.Load().Len()
+}
+
+// Refresher is a table content rendered from a snapshot
+type Refresher interface {
+	Refresh()
+}
+
+// RefreshOnDraw refreshes the content with the latest snapshot every time before
+// the table is drawn so that all cells in a frame are rendered from the same snapshot.
+func RefreshOnDraw(table *tview.Table, content Refresher) {
+	table.SetDrawFunc(func(_ tcell.Screen, _, _, _, _ int) (int, int, int, int) {
+		content.Refresh()
+		return table.GetInnerRect()
+	})
+}
+
+// RefreshOnInput refreshes the content with the latest snapshot before the table handles
+// a key event so that the selection moves over the rows the selected data is resolved from.
+// It must be called after the input capture of the table is set.
+func RefreshOnInput(table *tview.Table, content Refresher) {
+	capture := table.GetInputCapture()
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		content.Refresh()
+		if capture == nil {
+			return event
+		}
+		return capture(event)
+	})
+}
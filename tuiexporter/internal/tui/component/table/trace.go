@@ -7,25 +7,25 @@ import (
 )
 
 var defaultSpanCellMappers = cellMappers[telemetry.SpanData]{
-	1: {
+	2: {
 		header: "Service Name",
 		getTextRowFn: func(data *telemetry.SpanData) string {
 			return data.GetServiceName()
 		},
 	},
-	2: {
+	3: {
 		header: "Latency",
 		getTextRowFn: func(data *telemetry.SpanData) string {
 			return data.GetDurationText()
 		},
 	},
-	3: {
+	4: {
 		header: "Received At",
 		getTextRowFn: func(data *telemetry.SpanData) string {
 			panic("Received At column should be overridden")
 		},
 	},
-	4: {
+	5: {
 		header: "Span Name",
 		getTextRowFn: func(data *telemetry.SpanData) string {
 			return data.GetSpanName()
@@ -36,20 +36,22 @@ var defaultSpanCellMappers = cellMappers[telemetry.SpanData]{
 // SpanDataForTable is a wrapper for spans to be displayed in a table.
 type SpanDataForTable struct {
 	tview.TableContentReadOnly
+	*snapshotSource[telemetry.SpanData]
 	tcache         *telemetry.TraceCache
-	spans          *telemetry.SvcSpans
 	sortType       *telemetry.SortType
 	mapper         cellMappers[telemetry.SpanData]
 	isFullDatetime bool
+	// marked is the IDs of the traces marked to compare, the older first
+	marked []string
 }
 
-// NewSpanDataForTable creates a new SpanDataForTable.
-func NewSpanDataForTable(tcache *telemetry.TraceCache, spans *telemetry.SvcSpans, sortType *telemetry.SortType) SpanDataForTable {
+// NewSpanDataForTable creates a new SpanDataForTable rendering the snapshots returned by source.
+func NewSpanDataForTable(tcache *telemetry.TraceCache, source func() *telemetry.Snapshot[telemetry.SpanData], sortType *telemetry.SortType) SpanDataForTable {
 	t := SpanDataForTable{
-		tcache:   tcache,
-		spans:    spans,
-		sortType: sortType,
-		mapper:   defaultSpanCellMappers,
+		snapshotSource: newSnapshotSource(source),
+		tcache:         tcache,
+		sortType:       sortType,
+		mapper:         defaultSpanCellMappers,
 	}
 	t.updateReceivedAtMapper()
 
@@ -67,6 +69,26 @@ func (s SpanDataForTable) IsFullDatetime() bool {
 	return s.isFullDatetime
 }
 
+// ToggleMark marks the trace to compare, or unmarks it if it is already marked. Up to two traces
+// are marked and the older mark is removed by marking another trace.
+func (s *SpanDataForTable) ToggleMark(traceID string) {
+	for i, id := range s.marked {
+		if id == traceID {
+			s.marked = append(s.marked[:i], s.marked[i+1:]...)
+			return
+		}
+	}
+	if len(s.marked) == 2 {
+		s.marked = s.marked[1:]
+	}
+	s.marked = append(s.marked, traceID)
+}
+
+// MarkedTraces returns the IDs of the traces marked to compare, the older first
+func (s SpanDataForTable) MarkedTraces() []string {
+	return s.marked
+}
+
 func (s *SpanDataForTable) updateReceivedAtMapper() {
 	for k, m := range s.mapper {
 		if m.header == "Received At" {
@@ -85,10 +107,12 @@ func (s SpanDataForTable) GetCell(row, column int) *tview.TableCell {
 	if row == 0 {
 		return s.getHeaderCell(column, *s.sortType)
 	}
-	if row > 0 && row <= len(*s.spans) {
-		sd := (*s.spans)[row-1]
-		if column == 0 {
+	if sd := s.At(row - 1); row > 0 && sd != nil {
+		switch column {
+		case 0:
 			return s.getErrorIndicator(sd)
+		case 1:
+			return s.getPinMarker(sd)
 		}
 		return getCellFromData(s.mapper, sd, column)
 	}
@@ -96,11 +120,11 @@ func (s SpanDataForTable) GetCell(row, column int) *tview.TableCell {
 }
 
 func (s SpanDataForTable) GetRowCount() int {
-	return len(*s.spans) + 1
+	return s.Len() + 1
 }
 
 func (s SpanDataForTable) GetColumnCount() int {
-	return len(s.mapper) + 1 // including error indicator
+	return len(s.mapper) + 2 // including error indicator and pin marker
 }
 
 func (s SpanDataForTable) getErrorIndicator(span *telemetry.SpanData) *tview.TableCell {
@@ -116,14 +140,31 @@ func (s SpanDataForTable) getErrorIndicator(span *telemetry.SpanData) *tview.Tab
 	return tview.NewTableCell(text)
 }
 
+func (s SpanDataForTable) getPinMarker(span *telemetry.SpanData) *tview.TableCell {
+	if s.tcache == nil {
+		return tview.NewTableCell("")
+	}
+	text := ""
+	if s.tcache.IsPinned(span.Span.TraceID().String()) {
+		text = "*"
+	}
+	// the traces to compare are marked with A (base) and B (target)
+	for i, id := range s.marked {
+		if id == span.Span.TraceID().String() {
+			text += string(rune('A' + i))
+		}
+	}
+	return tview.NewTableCell(text).SetTextColor(tcell.ColorAqua)
+}
+
 func (s SpanDataForTable) getHeaderCell(column int, sortType telemetry.SortType) *tview.TableCell {
 	cell := tview.NewTableCell("N/A").
 		SetSelectable(false).
 		SetTextColor(tcell.ColorYellow)
 	h, ok := s.mapper[column]
 	if !ok {
-		if column == 0 {
-			cell.SetText(" ") // Error indicator
+		if column == 0 || column == 1 {
+			cell.SetText(" ") // Error indicator and pin marker
 		}
 		return cell
 	}
@@ -51,7 +51,7 @@ func TestSpanDataForTable(t *testing.T) {
 			ReceivedAt:   receivedAt,
 		}, // trace 2, span-1-1-1
 	}
-	svcspans := &telemetry.SvcSpans{
+	svcspans := telemetry.SvcSpans{
 		svc1sds[0],
 		svc1sds[1],
 		svc2sds[0],
@@ -63,15 +63,18 @@ func TestSpanDataForTable(t *testing.T) {
 	for _, sd := range svc2sds {
 		tcache.UpdateCache("test-service-2", sd)
 	}
+	tcache.Pin(testdata2.Spans[0].TraceID().String())
 	sortType := telemetry.SORT_TYPE_NONE
-	sdftable := NewSpanDataForTable(tcache, svcspans, &sortType)
+	sdftable := NewSpanDataForTable(tcache, func() *telemetry.Snapshot[telemetry.SpanData] {
+		return telemetry.NewSnapshot(svcspans, 0)
+	}, &sortType)
 
 	t.Run("GetRowCount", func(t *testing.T) {
 		assert.Equal(t, 4, sdftable.GetRowCount()) // including header row
 	})
 
 	t.Run("GetColumnCount", func(t *testing.T) {
-		assert.Equal(t, 5, sdftable.GetColumnCount())
+		assert.Equal(t, 6, sdftable.GetColumnCount())
 	})
 
 	t.Run("GetCell_Header", func(t *testing.T) {
@@ -84,31 +87,31 @@ func TestSpanDataForTable(t *testing.T) {
 			{
 				name:     "N/A",
 				sortType: telemetry.SORT_TYPE_NONE,
-				column:   5,
+				column:   6,
 				want:     "N/A",
 			},
 			{
 				name:     "Latency None",
 				sortType: telemetry.SORT_TYPE_NONE,
-				column:   2,
+				column:   3,
 				want:     "Latency",
 			},
 			{
 				name:     "Latency Desc",
 				sortType: telemetry.SORT_TYPE_LATENCY_DESC,
-				column:   2,
+				column:   3,
 				want:     "Latency ▼",
 			},
 			{
 				name:     "Latency Asc",
 				sortType: telemetry.SORT_TYPE_LATENCY_ASC,
-				column:   2,
+				column:   3,
 				want:     "Latency ▲",
 			},
 			{
 				name:     "Service Name no effect",
 				sortType: telemetry.SORT_TYPE_LATENCY_DESC,
-				column:   1,
+				column:   2,
 				want:     "Service Name",
 			},
 		}
@@ -137,7 +140,7 @@ func TestSpanDataForTable(t *testing.T) {
 			{
 				name:   "invalid column",
 				row:    0,
-				column: 5,
+				column: 6,
 				want:   "N/A",
 			},
 			{
@@ -158,28 +161,40 @@ func TestSpanDataForTable(t *testing.T) {
 				column: 0,
 				want:   "",
 			},
+			{
+				name:   "not pinned trace 1 span-1-1-1",
+				row:    0,
+				column: 1,
+				want:   "",
+			},
+			{
+				name:   "pinned trace 2 span-1-1-1",
+				row:    2,
+				column: 1,
+				want:   "*",
+			},
 			{
 				name:   "service name trace 1 span-2-1-1",
 				row:    1,
-				column: 1,
+				column: 2,
 				want:   "test-service-2",
 			},
 			{
 				name:   "latency span-1-1-1",
 				row:    0,
-				column: 2,
+				column: 3,
 				want:   "200ms",
 			},
 			{
 				name:   "received at trace 2 span-1-1-1",
 				row:    2,
-				column: 3,
+				column: 4,
 				want:   datetime.GetSimpleTime(receivedAt.Local()),
 			},
 			{
 				name:   "span name trace 2 span-1-1-1",
 				row:    2,
-				column: 4,
+				column: 5,
 				want:   "span-0-0-0",
 			},
 		}
@@ -193,7 +208,25 @@ func TestSpanDataForTable(t *testing.T) {
 		t.Run("full datetime", func(t *testing.T) {
 			sdftable.SetFullDatetime(true)
 			defer sdftable.SetFullDatetime(false)
-			assert.Equal(t, datetime.GetFullTime(receivedAt.Local()), sdftable.GetCell(3, 3).Text)
+			assert.Equal(t, datetime.GetFullTime(receivedAt.Local()), sdftable.GetCell(3, 4).Text)
 		})
 	})
+
+	t.Run("compare marks", func(t *testing.T) {
+		trace1, trace2 := testdata1.Spans[0].TraceID().String(), testdata2.Spans[0].TraceID().String()
+		sdftable.ToggleMark(trace1)
+		sdftable.ToggleMark(trace2)
+
+		assert.DeepEqual(t, []string{trace1, trace2}, sdftable.MarkedTraces())
+		assert.Equal(t, "A", sdftable.GetCell(1, 1).Text)
+		assert.Equal(t, "A", sdftable.GetCell(2, 1).Text)
+		assert.Equal(t, "*B", sdftable.GetCell(3, 1).Text)
+
+		// the older mark is removed by marking another trace
+		sdftable.ToggleMark("other")
+		assert.DeepEqual(t, []string{trace2, "other"}, sdftable.MarkedTraces())
+
+		sdftable.ToggleMark(trace2)
+		assert.DeepEqual(t, []string{"other"}, sdftable.MarkedTraces())
+	})
 }